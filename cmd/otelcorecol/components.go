@@ -8,6 +8,11 @@ import (
 	otlpexporter "go.opentelemetry.io/collector/exporter/otlpexporter"
 	otlphttpexporter "go.opentelemetry.io/collector/exporter/otlphttpexporter"
 	ballastextension "go.opentelemetry.io/collector/extension/ballastextension"
+	basicauthextension "go.opentelemetry.io/collector/extension/basicauthextension"
+	bearertokenauthextension "go.opentelemetry.io/collector/extension/bearertokenauthextension"
+	hmacauthextension "go.opentelemetry.io/collector/extension/hmacauthextension"
+	mtlsauthextension "go.opentelemetry.io/collector/extension/mtlsauthextension"
+	oauth2clientauthextension "go.opentelemetry.io/collector/extension/oauth2clientauthextension"
 	zpagesextension "go.opentelemetry.io/collector/extension/zpagesextension"
 	batchprocessor "go.opentelemetry.io/collector/processor/batchprocessor"
 	memorylimiterprocessor "go.opentelemetry.io/collector/processor/memorylimiterprocessor"
@@ -20,6 +25,11 @@ func components() (component.Factories, error) {
 
 	factories.Extensions, err = component.MakeExtensionFactoryMap(
 		ballastextension.NewFactory(),
+		basicauthextension.NewFactory(),
+		bearertokenauthextension.NewFactory(),
+		hmacauthextension.NewFactory(),
+		mtlsauthextension.NewFactory(),
+		oauth2clientauthextension.NewFactory(),
 		zpagesextension.NewFactory(),
 	)
 	if err != nil {
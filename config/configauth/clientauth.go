@@ -17,6 +17,7 @@ package configauth // import "go.opentelemetry.io/collector/config/configauth"
 import (
 	"net/http"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
 	"go.opentelemetry.io/collector/component"
@@ -34,3 +35,13 @@ type ClientAuthenticator interface {
 	// PerRPCCredentials returns a PerRPCCredentials that can be used to authenticate gRPC requests.
 	PerRPCCredentials() (credentials.PerRPCCredentials, error)
 }
+
+// GRPCClientInterceptor is an optional interface of the ClientAuthenticators which need to intercept the gRPC calls
+// they authenticate, e.g. to drop the credentials rejected by the server. The interceptors may be nil.
+type GRPCClientInterceptor interface {
+	// UnaryClientInterceptor returns the interceptor of the unary gRPC calls.
+	UnaryClientInterceptor() grpc.UnaryClientInterceptor
+
+	// StreamClientInterceptor returns the interceptor of the streaming gRPC calls.
+	StreamClientInterceptor() grpc.StreamClientInterceptor
+}
@@ -18,12 +18,16 @@ import (
 	"context"
 	"net/http"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
 	"go.opentelemetry.io/collector/component"
 )
 
-var _ ClientAuthenticator = (*defaultClientAuthenticator)(nil)
+var (
+	_ ClientAuthenticator   = (*defaultClientAuthenticator)(nil)
+	_ GRPCClientInterceptor = (*defaultClientAuthenticator)(nil)
+)
 
 // Option represents the possible options for NewServerAuthenticator.
 type ClientOption func(*defaultClientAuthenticator)
@@ -33,6 +37,8 @@ type defaultClientAuthenticator struct {
 	component.ShutdownFunc
 	roundTripperFunc      func(base http.RoundTripper) (http.RoundTripper, error)
 	perRPCCredentialsFunc func() (credentials.PerRPCCredentials, error)
+	unaryInterceptor      grpc.UnaryClientInterceptor
+	streamInterceptor     grpc.StreamClientInterceptor
 }
 
 // WithClientStart overrides the default `Start` function for a component.Component.
@@ -67,6 +73,22 @@ func WithPerRPCCredentials(perRPCCredentialsFunc func() (credentials.PerRPCCrede
 	}
 }
 
+// WithClientUnaryInterceptor provides a `grpc.UnaryClientInterceptor` for this client authenticator.
+// There's no default.
+func WithClientUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) ClientOption {
+	return func(o *defaultClientAuthenticator) {
+		o.unaryInterceptor = interceptor
+	}
+}
+
+// WithClientStreamInterceptor provides a `grpc.StreamClientInterceptor` for this client authenticator.
+// There's no default.
+func WithClientStreamInterceptor(interceptor grpc.StreamClientInterceptor) ClientOption {
+	return func(o *defaultClientAuthenticator) {
+		o.streamInterceptor = interceptor
+	}
+}
+
 // NewClientAuthenticator returns a ClientAuthenticator configured with the provided options.
 func NewClientAuthenticator(options ...ClientOption) ClientAuthenticator {
 	bc := &defaultClientAuthenticator{
@@ -102,3 +124,13 @@ func (a *defaultClientAuthenticator) RoundTripper(base http.RoundTripper) (http.
 func (a *defaultClientAuthenticator) PerRPCCredentials() (credentials.PerRPCCredentials, error) {
 	return a.perRPCCredentialsFunc()
 }
+
+// UnaryClientInterceptor returns this authenticator's grpc.UnaryClientInterceptor, if any.
+func (a *defaultClientAuthenticator) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return a.unaryInterceptor
+}
+
+// StreamClientInterceptor returns this authenticator's grpc.StreamClientInterceptor, if any.
+func (a *defaultClientAuthenticator) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return a.streamInterceptor
+}
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
 	"go.opentelemetry.io/collector/component"
@@ -48,6 +49,12 @@ func TestClientDefaultValues(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("interceptors", func(t *testing.T) {
+		ic := e.(GRPCClientInterceptor)
+		assert.Nil(t, ic.UnaryClientInterceptor())
+		assert.Nil(t, ic.StreamClientInterceptor())
+	})
+
 	t.Run("shutdown", func(t *testing.T) {
 		err := e.Shutdown(context.Background())
 		assert.NoError(t, err)
@@ -116,3 +123,27 @@ func TestWithPerRPCCredentials(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestWithClientInterceptors(t *testing.T) {
+	unaryCalled, streamCalled := false, false
+	e := NewClientAuthenticator(
+		WithClientUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			unaryCalled = true
+			return nil
+		}),
+		WithClientStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			streamCalled = true
+			return nil, nil
+		}),
+	)
+
+	// test
+	ic := e.(GRPCClientInterceptor)
+	assert.NoError(t, ic.UnaryClientInterceptor()(context.Background(), "", nil, nil, nil, nil))
+	_, err := ic.StreamClientInterceptor()(context.Background(), nil, nil, "", nil)
+
+	// verify
+	assert.NoError(t, err)
+	assert.True(t, unaryCalled)
+	assert.True(t, streamCalled)
+}
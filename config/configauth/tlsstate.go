@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauth // import "go.opentelemetry.io/collector/config/configauth"
+
+import (
+	"context"
+	"crypto/tls"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type tlsStateKey struct{}
+
+// ContextWithTLSConnectionState returns a context carrying the state of the TLS connection the request was received on,
+// so ServerAuthenticators can authenticate the client with its certificate. HTTP servers call it before the authentication,
+// while the state of the gRPC connections is obtained from the peer of the call.
+func ContextWithTLSConnectionState(ctx context.Context, state *tls.ConnectionState) context.Context {
+	return context.WithValue(ctx, tlsStateKey{}, state)
+}
+
+// TLSConnectionStateFromContext returns the state of the TLS connection the HTTP request or gRPC call was received on.
+// It returns false when the request was not received over TLS.
+func TLSConnectionStateFromContext(ctx context.Context) (*tls.ConnectionState, bool) {
+	if state, ok := ctx.Value(tlsStateKey{}).(*tls.ConnectionState); ok && state != nil {
+		return state, true
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			return &info.State, true
+		}
+	}
+	return nil, false
+}
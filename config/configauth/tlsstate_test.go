@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauth
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestTLSConnectionStateFromContext(t *testing.T) {
+	_, ok := TLSConnectionStateFromContext(context.Background())
+	assert.False(t, ok)
+
+	// HTTP requests.
+	state := &tls.ConnectionState{ServerName: "http"}
+	got, ok := TLSConnectionStateFromContext(ContextWithTLSConnectionState(context.Background(), state))
+	assert.True(t, ok)
+	assert.Same(t, state, got)
+
+	// gRPC calls.
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{ServerName: "grpc"}}})
+	got, ok = TLSConnectionStateFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "grpc", got.ServerName)
+
+	// gRPC calls received without TLS.
+	_, ok = TLSConnectionStateFromContext(peer.NewContext(context.Background(), &peer.Peer{}))
+	assert.False(t, ok)
+}
@@ -0,0 +1,255 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauthz // import "go.opentelemetry.io/collector/config/configauthz"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/internal/authdata"
+)
+
+// Action is the decision of a rule matching a request.
+type Action string
+
+const (
+	// ActionAllow lets the request through.
+	ActionAllow Action = "allow"
+	// ActionDeny rejects the request.
+	ActionDeny Action = "deny"
+)
+
+// Signal is the type of telemetry sent by a request.
+type Signal string
+
+const (
+	// SignalTraces is sent to the OTLP trace service.
+	SignalTraces Signal = "traces"
+	// SignalMetrics is sent to the OTLP metrics service.
+	SignalMetrics Signal = "metrics"
+	// SignalLogs is sent to the OTLP logs service.
+	SignalLogs Signal = "logs"
+)
+
+// ErrPermissionDenied is returned by Policy.Authorize for the requests the rules do not allow.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Authorization defines the authorization policy of a receiver, evaluated after the authentication of the requests.
+type Authorization struct {
+	// Rules are evaluated in order, the action of the first rule matching the request is applied.
+	Rules []Rule `mapstructure:"rules"`
+
+	// DefaultAction is applied to the requests matching no rule. Defaults to "deny".
+	DefaultAction Action `mapstructure:"default_action"`
+
+	// PrincipalAttribute is the client.AuthData attribute holding the principal of the clients.
+	// Defaults to "principal", set by the authenticators of this repository.
+	PrincipalAttribute string `mapstructure:"principal_attribute"`
+
+	// GroupsAttribute is the client.AuthData attribute holding the groups of the clients.
+	// Defaults to "groups", set by the authenticators of this repository.
+	GroupsAttribute string `mapstructure:"groups_attribute"`
+}
+
+// Rule matches the requests of some clients, sending some signals. A rule matches a request when all its
+// non-empty conditions match. The values of the conditions can contain "*" wildcards matching any characters.
+type Rule struct {
+	// Action is applied to the matching requests.
+	Action Action `mapstructure:"action"`
+
+	// Principals matches the requests of the clients with one of the principals.
+	Principals []string `mapstructure:"principals"`
+
+	// Groups matches the requests of the clients member of one of the groups.
+	Groups []string `mapstructure:"groups"`
+
+	// Signals matches the requests sending one of the signals, for the OTLP gRPC services and HTTP paths.
+	Signals []Signal `mapstructure:"signals"`
+
+	// Paths matches the requests to one of the HTTP paths or gRPC full method names, like
+	// "/opentelemetry.proto.collector.trace.v1.TraceService/Export".
+	Paths []string `mapstructure:"paths"`
+}
+
+// Validate checks if the authorization policy is valid.
+func (a *Authorization) Validate() error {
+	if err := validateAction(a.DefaultAction, true); err != nil {
+		return fmt.Errorf("invalid default_action: %w", err)
+	}
+	for i, rule := range a.Rules {
+		if err := validateAction(rule.Action, false); err != nil {
+			return fmt.Errorf("invalid action of rule %d: %w", i, err)
+		}
+		for _, signal := range rule.Signals {
+			switch signal {
+			case SignalTraces, SignalMetrics, SignalLogs:
+			default:
+				return fmt.Errorf("unknown signal %q in rule %d", signal, i)
+			}
+		}
+	}
+	return nil
+}
+
+func validateAction(action Action, optional bool) error {
+	switch {
+	case action == ActionAllow, action == ActionDeny, action == "" && optional:
+		return nil
+	case action == "":
+		return errors.New("action must be set")
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// ToPolicy creates the Policy evaluating the rules.
+func (a *Authorization) ToPolicy() (*Policy, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		defaultAction:      a.DefaultAction,
+		principalAttribute: a.PrincipalAttribute,
+		groupsAttribute:    a.GroupsAttribute,
+	}
+	if p.defaultAction == "" {
+		p.defaultAction = ActionDeny
+	}
+	if p.principalAttribute == "" {
+		p.principalAttribute = authdata.PrincipalAttribute
+	}
+	if p.groupsAttribute == "" {
+		p.groupsAttribute = authdata.GroupsAttribute
+	}
+	for _, rule := range a.Rules {
+		signals := make([]string, len(rule.Signals))
+		for i, signal := range rule.Signals {
+			signals[i] = string(signal)
+		}
+		p.rules = append(p.rules, compiledRule{
+			action:     rule.Action,
+			principals: compilePatterns(rule.Principals),
+			groups:     compilePatterns(rule.Groups),
+			signals:    compilePatterns(signals),
+			paths:      compilePatterns(rule.Paths),
+		})
+	}
+	return p, nil
+}
+
+// Policy authorizes the requests with the rules of an Authorization.
+type Policy struct {
+	rules              []compiledRule
+	defaultAction      Action
+	principalAttribute string
+	groupsAttribute    string
+}
+
+type compiledRule struct {
+	action     Action
+	principals []*regexp.Regexp
+	groups     []*regexp.Regexp
+	signals    []*regexp.Regexp
+	paths      []*regexp.Regexp
+}
+
+// Authorize checks that the client authenticated in the context can send the request to the HTTP path, or gRPC
+// full method name. It returns an error wrapping ErrPermissionDenied when the request is denied.
+func (p *Policy) Authorize(ctx context.Context, path string) error {
+	var principal string
+	var groups []string
+	if auth := client.FromContext(ctx).Auth; auth != nil {
+		principal, _ = auth.GetAttribute(p.principalAttribute).(string)
+		switch v := auth.GetAttribute(p.groupsAttribute).(type) {
+		case []string:
+			groups = v
+		case string:
+			groups = []string{v}
+		}
+	}
+	signal := string(SignalFromPath(path))
+
+	action := p.defaultAction
+	for _, rule := range p.rules {
+		if matchAny(rule.principals, principal) && matchAnyOf(rule.groups, groups) &&
+			matchAny(rule.signals, signal) && matchAny(rule.paths, path) {
+			action = rule.action
+			break
+		}
+	}
+	if action != ActionAllow {
+		return fmt.Errorf("%w: principal %q cannot call %q", ErrPermissionDenied, principal, path)
+	}
+	return nil
+}
+
+// SignalFromPath returns the signal sent to the OTLP HTTP path or gRPC full method name, or an empty signal for
+// the other paths.
+func SignalFromPath(path string) Signal {
+	switch {
+	case path == "/v1/traces", strings.HasPrefix(path, "/opentelemetry.proto.collector.trace."):
+		return SignalTraces
+	case path == "/v1/metrics", strings.HasPrefix(path, "/opentelemetry.proto.collector.metrics."):
+		return SignalMetrics
+	case path == "/v1/logs", strings.HasPrefix(path, "/opentelemetry.proto.collector.logs."):
+		return SignalLogs
+	default:
+		return ""
+	}
+}
+
+// compilePatterns compiles the values with "*" wildcards matching any characters.
+func compilePatterns(values []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(values))
+	for _, value := range values {
+		expr := strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+		patterns = append(patterns, regexp.MustCompile("^"+expr+"$"))
+	}
+	return patterns
+}
+
+// matchAny returns true if there are no patterns, or if one of them matches the value. An empty value, like the
+// principal of the clients not authenticated, is not matched by any pattern.
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	if value == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyOf returns true if there are no patterns, or if one of them matches one of the values.
+func matchAnyOf(patterns []*regexp.Regexp, values []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if matchAny(patterns, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauthz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/internal/authdata"
+)
+
+const (
+	tracesMethod  = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	metricsMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		authz Authorization
+		err   string
+	}{
+		{authz: Authorization{}},
+		{authz: Authorization{DefaultAction: ActionAllow, Rules: []Rule{{Action: ActionDeny, Signals: []Signal{SignalLogs}}}}},
+		{authz: Authorization{DefaultAction: "maybe"}, err: `invalid default_action: unknown action "maybe"`},
+		{authz: Authorization{Rules: []Rule{{}}}, err: "invalid action of rule 0: action must be set"},
+		{authz: Authorization{Rules: []Rule{{Action: ActionAllow, Signals: []Signal{"profiles"}}}}, err: `unknown signal "profiles" in rule 0`},
+	} {
+		err := tt.authz.Validate()
+		if tt.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tt.err)
+		}
+	}
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	authz := &Authorization{
+		Rules: []Rule{
+			// The first matching rule applies.
+			{Action: ActionDeny, Principals: []string{"tenant-a-blocked"}},
+			{Action: ActionAllow, Principals: []string{"tenant-a*"}, Signals: []Signal{SignalTraces, SignalMetrics}},
+			{Action: ActionAllow, Groups: []string{"admins"}},
+			{Action: ActionAllow, Paths: []string{"/health"}},
+		},
+	}
+	policy, err := authz.ToPolicy()
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		principal string
+		groups    []string
+		path      string
+		allowed   bool
+	}{
+		{name: "allowed_signal_grpc", principal: "tenant-a", path: tracesMethod, allowed: true},
+		{name: "allowed_signal_http", principal: "tenant-a-2", path: "/v1/metrics", allowed: true},
+		{name: "denied_signal", principal: "tenant-a", path: "/v1/logs"},
+		{name: "denied_principal", principal: "tenant-a-blocked", path: tracesMethod},
+		{name: "unknown_principal", principal: "tenant-b", path: tracesMethod},
+		{name: "allowed_group", principal: "tenant-b", groups: []string{"devs", "admins"}, path: "/v1/logs", allowed: true},
+		{name: "allowed_path", path: "/health", allowed: true},
+		{name: "not_authenticated", path: metricsMethod},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != "" {
+				ctx = authdata.NewContext(ctx, tt.principal, tt.groups)
+			}
+			err := policy.Authorize(ctx, tt.path)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrPermissionDenied))
+			}
+		})
+	}
+}
+
+type testAuthData map[string]interface{}
+
+func (a testAuthData) GetAttribute(name string) interface{} {
+	return a[name]
+}
+
+func (a testAuthData) GetAttributeNames() []string {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestPolicy_AuthorizeAttributes(t *testing.T) {
+	authz := &Authorization{
+		DefaultAction:      ActionAllow,
+		PrincipalAttribute: "subject",
+		GroupsAttribute:    "membership",
+		Rules: []Rule{
+			{Action: ActionDeny, Principals: []string{"blocked"}},
+			{Action: ActionDeny, Groups: []string{"guests"}},
+		},
+	}
+	policy, err := authz.ToPolicy()
+	require.NoError(t, err)
+
+	authorize := func(auth client.AuthData) error {
+		return policy.Authorize(client.NewContext(context.Background(), client.Info{Auth: auth}), tracesMethod)
+	}
+	assert.NoError(t, authorize(testAuthData{"subject": "alice", "membership": []string{"devs"}}))
+	assert.Error(t, authorize(testAuthData{"subject": "blocked"}))
+	// The groups can be a single string.
+	assert.Error(t, authorize(testAuthData{"subject": "bob", "membership": "guests"}))
+	assert.EqualError(t, authorize(testAuthData{"principal": "blocked", "subject": "blocked"}), `permission denied: principal "blocked" cannot call "`+tracesMethod+`"`)
+	// The default action applies to the clients not authenticated.
+	assert.NoError(t, policy.Authorize(context.Background(), tracesMethod))
+}
+
+func TestSignalFromPath(t *testing.T) {
+	assert.Equal(t, SignalTraces, SignalFromPath(tracesMethod))
+	assert.Equal(t, SignalTraces, SignalFromPath("/v1/traces"))
+	assert.Equal(t, SignalMetrics, SignalFromPath(metricsMethod))
+	assert.Equal(t, SignalMetrics, SignalFromPath("/v1/metrics"))
+	assert.Equal(t, SignalLogs, SignalFromPath("/opentelemetry.proto.collector.logs.v1.LogsService/Export"))
+	assert.Equal(t, SignalLogs, SignalFromPath("/v1/logs"))
+	assert.Equal(t, Signal(""), SignalFromPath("/api/traces"))
+}
@@ -27,20 +27,26 @@ import (
 	"github.com/mostynb/go-grpc-compression/zstd"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configauthz"
 	"go.opentelemetry.io/collector/config/configcompression"
 	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/config/configratelimit"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/internal/middleware"
 )
@@ -154,6 +160,13 @@ type GRPCServerSettings struct {
 	// Auth for this receiver
 	Auth *configauth.Authentication `mapstructure:"auth,omitempty"`
 
+	// Authorization policy for this receiver, evaluated after the authentication.
+	Authorization *configauthz.Authorization `mapstructure:"authorization,omitempty"`
+
+	// RateLimit applied to each client of this receiver, evaluated after the authorization.
+	// The calls are limited, not the messages of the streams.
+	RateLimit *configratelimit.RateLimit `mapstructure:"rate_limit,omitempty"`
+
 	// Include propagates the incoming connection's metadata to downstream consumers.
 	// Experimental: *NOTE* this option is subject to change or removal in the future.
 	IncludeMetadata bool `mapstructure:"include_metadata,omitempty"`
@@ -190,7 +203,7 @@ func (gcs *GRPCClientSettings) ToDialOptions(host component.Host, settings compo
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(cp)))
 	}
 
-	tlsCfg, err := gcs.TLSSetting.LoadTLSConfig()
+	tlsCfg, err := gcs.TLSSetting.LoadTLSConfig(configtls.WithLogger(settings.Logger), configtls.WithEndpoint(gcs.Endpoint))
 	if err != nil {
 		return nil, err
 	}
@@ -234,6 +247,15 @@ func (gcs *GRPCClientSettings) ToDialOptions(host component.Host, settings compo
 			return nil, err
 		}
 		opts = append(opts, grpc.WithPerRPCCredentials(perRPCCredentials))
+
+		if interceptor, ok := grpcAuthenticator.(configauth.GRPCClientInterceptor); ok {
+			if unary := interceptor.UnaryClientInterceptor(); unary != nil {
+				opts = append(opts, grpc.WithChainUnaryInterceptor(unary))
+			}
+			if stream := interceptor.StreamClientInterceptor(); stream != nil {
+				opts = append(opts, grpc.WithChainStreamInterceptor(stream))
+			}
+		}
 	}
 
 	if gcs.BalancerName != "" {
@@ -270,7 +292,7 @@ func (gss *GRPCServerSettings) ToServerOption(host component.Host, settings comp
 	var opts []grpc.ServerOption
 
 	if gss.TLSSetting != nil {
-		tlsCfg, err := gss.TLSSetting.LoadTLSConfig()
+		tlsCfg, err := gss.TLSSetting.LoadTLSConfig(configtls.WithLogger(settings.Logger))
 		if err != nil {
 			return nil, err
 		}
@@ -338,6 +360,49 @@ func (gss *GRPCServerSettings) ToServerOption(host component.Host, settings comp
 		})
 	}
 
+	if gss.Authorization != nil {
+		policy, err := gss.Authorization.ToPolicy()
+		if err != nil {
+			return nil, err
+		}
+
+		uInterceptors = append(uInterceptors, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := authorize(ctx, info.FullMethod, policy); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		})
+		sInterceptors = append(sInterceptors, func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authorize(ss.Context(), info.FullMethod, policy); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		})
+	}
+
+	if gss.RateLimit != nil {
+		if err := gss.RateLimit.ValidateAuth(gss.Auth != nil); err != nil {
+			return nil, err
+		}
+		limiter, err := gss.RateLimit.ToLimiter()
+		if err != nil {
+			return nil, err
+		}
+
+		uInterceptors = append(uInterceptors, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := rateLimit(ctx, limiter); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		})
+		sInterceptors = append(sInterceptors, func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := rateLimit(ss.Context(), limiter); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		})
+	}
+
 	// Enable OpenTelemetry observability plugin.
 	// TODO: Pass construct settings to have access to Tracer.
 	uInterceptors = append(uInterceptors, otelgrpc.UnaryServerInterceptor(
@@ -424,6 +489,33 @@ func authUnaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.Un
 	return handler(ctx, req)
 }
 
+// authorize rejects the calls not allowed by the policy with the PermissionDenied code.
+func authorize(ctx context.Context, fullMethod string, policy *configauthz.Policy) error {
+	if err := policy.Authorize(ctx, fullMethod); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// rateLimit rejects the calls exceeding the rate limit of their client with the ResourceExhausted code, and the
+// delay before retrying in a RetryInfo.
+func rateLimit(ctx context.Context, limiter *configratelimit.Limiter) error {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	delay := limiter.Allow(ctx, addr, md)
+	if delay <= 0 {
+		return nil
+	}
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
 func authStreamServerInterceptor(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler, authenticate configauth.AuthenticateFunc) error {
 	ctx := stream.Context()
 	headers, ok := metadata.FromIncomingContext(ctx)
@@ -27,18 +27,25 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configauthz"
 	"go.opentelemetry.io/collector/config/configcompression"
 	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/config/configratelimit"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/internal/authdata"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/obsreport/obsreporttest"
 )
@@ -162,6 +169,36 @@ func TestAllGrpcClientSettings(t *testing.T) {
 	}
 }
 
+func TestGrpcClientAuthInterceptors(t *testing.T) {
+	gcs := &GRPCClientSettings{
+		Endpoint: "localhost:1234",
+		Auth:     &configauth.Authentication{AuthenticatorID: config.NewComponentID("testauth")},
+	}
+	withoutInterceptors := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("testauth"): configauth.NewClientAuthenticator(),
+		},
+	}
+	withInterceptors := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("testauth"): configauth.NewClientAuthenticator(
+				configauth.WithClientUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+					return invoker(ctx, method, req, reply, cc, opts...)
+				}),
+				configauth.WithClientStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					return streamer(ctx, desc, cc, method, opts...)
+				}),
+			),
+		},
+	}
+
+	opts, err := gcs.ToDialOptions(withoutInterceptors, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	optsWithInterceptors, err := gcs.ToDialOptions(withInterceptors, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	assert.Len(t, optsWithInterceptors, len(opts)+2)
+}
+
 func TestDefaultGrpcServerSettings(t *testing.T) {
 	gss := &GRPCServerSettings{}
 	opts, err := gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
@@ -641,6 +678,134 @@ func TestReceiveOnUnixDomainSocket(t *testing.T) {
 	s.Stop()
 }
 
+func TestServerAuthorization(t *testing.T) {
+	gss := &GRPCServerSettings{
+		NetAddr: confignet.NetAddr{
+			Endpoint:  "localhost:0",
+			Transport: "tcp",
+		},
+		Auth: &configauth.Authentication{
+			AuthenticatorID: config.NewComponentID("mock"),
+		},
+		Authorization: &configauthz.Authorization{
+			Rules: []configauthz.Rule{
+				{Action: configauthz.ActionAllow, Principals: []string{"tenant-a"}, Signals: []configauthz.Signal{configauthz.SignalTraces}},
+			},
+		},
+	}
+	// The principal is the authorization header of the calls.
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("mock"): configauth.NewServerAuthenticator(
+				configauth.WithAuthenticate(func(ctx context.Context, headers map[string][]string) (context.Context, error) {
+					return authdata.NewContext(ctx, headers["authorization"][0], nil), nil
+				}),
+			),
+		},
+	}
+	ln, err := gss.ToListener()
+	require.NoError(t, err)
+	opts, err := gss.ToServerOption(host, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	s := grpc.NewServer(opts...)
+	defer s.Stop()
+	otlpgrpc.RegisterTracesServer(s, &grpcTraceServer{})
+	go func() {
+		_ = s.Serve(ln)
+	}()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := otlpgrpc.NewTracesClient(conn)
+
+	for principal, code := range map[string]codes.Code{
+		"tenant-a": codes.OK,
+		"tenant-b": codes.PermissionDenied,
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", principal)
+		_, err = client.Export(ctx, otlpgrpc.NewTracesRequest(), grpc.WaitForReady(true))
+		cancel()
+		assert.Equal(t, code, status.Code(err), principal)
+	}
+}
+
+func TestServerAuthorizationError(t *testing.T) {
+	gss := &GRPCServerSettings{
+		Authorization: &configauthz.Authorization{
+			Rules: []configauthz.Rule{{Action: "maybe"}},
+		},
+	}
+	_, err := gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	assert.EqualError(t, err, `invalid action of rule 0: unknown action "maybe"`)
+}
+
+func TestServerRateLimit(t *testing.T) {
+	gss := &GRPCServerSettings{
+		NetAddr: confignet.NetAddr{
+			Endpoint:  "localhost:0",
+			Transport: "tcp",
+		},
+		RateLimit: &configratelimit.RateLimit{
+			RequestsPerSecond: 0.1,
+			RequestsBurst:     2,
+			Key:               configratelimit.KeySourceMetadata,
+			MetadataKey:       "X-Tenant",
+		},
+	}
+	ln, err := gss.ToListener()
+	require.NoError(t, err)
+	opts, err := gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	s := grpc.NewServer(opts...)
+	defer s.Stop()
+	otlpgrpc.RegisterTracesServer(s, &grpcTraceServer{})
+	go func() {
+		_ = s.Serve(ln)
+	}()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := otlpgrpc.NewTracesClient(conn)
+
+	export := func(tenant string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant", tenant)
+		_, err := client.Export(ctx, otlpgrpc.NewTracesRequest(), grpc.WaitForReady(true))
+		return err
+	}
+	assert.NoError(t, export("tenant-a"))
+	assert.NoError(t, export("tenant-a"))
+
+	// The calls above the burst are rejected with a hint of when to retry.
+	err = export("tenant-a")
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Greater(t, retryInfo.RetryDelay.AsDuration(), 9*time.Second)
+	assert.LessOrEqual(t, retryInfo.RetryDelay.AsDuration(), 10*time.Second)
+
+	// Each tenant has its own limit.
+	assert.NoError(t, export("tenant-b"))
+}
+
+func TestServerRateLimitError(t *testing.T) {
+	gss := &GRPCServerSettings{
+		RateLimit: &configratelimit.RateLimit{},
+	}
+	_, err := gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	assert.EqualError(t, err, "requests_per_second must be positive")
+
+	gss.RateLimit = &configratelimit.RateLimit{RequestsPerSecond: 1, Key: configratelimit.KeySourcePrincipal}
+	_, err = gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	assert.EqualError(t, err, "key principal requires the auth of the receiver to be configured")
+}
+
 func TestContextWithClient(t *testing.T) {
 	testCases := []struct {
 		desc       string
@@ -17,8 +17,10 @@ package confighttp // import "go.opentelemetry.io/collector/config/confighttp"
 import (
 	"crypto/tls"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/rs/cors"
@@ -29,7 +31,9 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configauthz"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configratelimit"
 	"go.opentelemetry.io/collector/config/configtls"
 )
 
@@ -38,6 +42,11 @@ const headerContentEncoding = "Content-Encoding"
 // HTTPClientSettings defines settings for creating an HTTP client.
 type HTTPClientSettings struct {
 	// The target URL to send data to (e.g.: http://some.url:9411/v1/traces).
+	// The requests can also be sent to the HTTP server listening on a unix socket, whose path is prefixed
+	// by "unix://", like "unix:///var/run/otel.sock", or on a VM socket, whose context ID and port are prefixed
+	// by "vsock://", like "vsock://2:4318": the URLs of the requests are then the endpoint followed by the HTTP
+	// path, like "unix:///var/run/otel.sock/v1/traces". The requests are sent to a socket with plain HTTP
+	// unless the TLSSetting sets a CA, a client certificate, InsecureSkipVerify or ServerName.
 	Endpoint string `mapstructure:"endpoint"`
 
 	// TLSSetting struct exposes TLS client configuration.
@@ -100,7 +109,16 @@ func NewDefaultHTTPClientSettings() HTTPClientSettings {
 
 // ToClient creates an HTTP client.
 func (hcs *HTTPClientSettings) ToClient(ext map[config.ComponentID]component.Extension, settings component.TelemetrySettings) (*http.Client, error) {
-	tlsCfg, err := hcs.TLSSetting.LoadTLSConfig()
+	socket, err := newSocketEndpoint(hcs.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	tlsSetting := hcs.TLSSetting
+	if socket != nil && tlsSetting.ServerName == "" {
+		// The certificate of the server listening on the socket is verified for the host of the requests.
+		tlsSetting.ServerName = socketServerName
+	}
+	tlsCfg, err := tlsSetting.LoadTLSConfig(configtls.WithLogger(settings.Logger), configtls.WithEndpoint(hcs.Endpoint))
 	if err != nil {
 		return nil, err
 	}
@@ -132,9 +150,12 @@ func (hcs *HTTPClientSettings) ToClient(ext map[config.ComponentID]component.Ext
 	}
 
 	clientTransport := (http.RoundTripper)(transport)
+	if socket != nil {
+		clientTransport = newSocketTransport(transport, socket, socketTLS(hcs.TLSSetting))
+	}
 	if len(hcs.Headers) > 0 {
 		clientTransport = &headerRoundTripper{
-			transport: transport,
+			transport: clientTransport,
 			headers:   hcs.Headers,
 		}
 	}
@@ -200,9 +221,14 @@ func (interceptor *headerRoundTripper) RoundTrip(req *http.Request) (*http.Respo
 
 // HTTPServerSettings defines settings for creating an HTTP server.
 type HTTPServerSettings struct {
-	// Endpoint configures the listening address for the server.
+	// Endpoint configures the listening address for the server, the path of the unix socket prefixed
+	// by "unix://", like "unix:///var/run/otel.sock", or the context ID and the port of the VM socket prefixed
+	// by "vsock://", like "vsock://:4318" to listen on any context ID.
 	Endpoint string `mapstructure:"endpoint"`
 
+	// UnixSocket configures the permissions of the unix socket of the Endpoint.
+	UnixSocket *UnixSocketSettings `mapstructure:"unix_socket,omitempty"`
+
 	// TLSSetting struct exposes TLS client configuration.
 	TLSSetting *configtls.TLSServerSetting `mapstructure:"tls, omitempty"`
 
@@ -212,6 +238,12 @@ type HTTPServerSettings struct {
 	// Auth for this receiver
 	Auth *configauth.Authentication `mapstructure:"auth,omitempty"`
 
+	// Authorization policy for this receiver, evaluated after the authentication.
+	Authorization *configauthz.Authorization `mapstructure:"authorization,omitempty"`
+
+	// RateLimit applied to each client of this receiver, evaluated after the authorization.
+	RateLimit *configratelimit.RateLimit `mapstructure:"rate_limit,omitempty"`
+
 	// MaxRequestBodySize sets the maximum request body size in bytes
 	MaxRequestBodySize int64 `mapstructure:"max_request_body_size,omitempty"`
 
@@ -220,16 +252,29 @@ type HTTPServerSettings struct {
 	IncludeMetadata bool `mapstructure:"include_metadata,omitempty"`
 }
 
-// ToListener creates a net.Listener.
-func (hss *HTTPServerSettings) ToListener() (net.Listener, error) {
-	listener, err := net.Listen("tcp", hss.Endpoint)
+// ToListener creates a net.Listener. The options configure how the TLS configuration is loaded.
+func (hss *HTTPServerSettings) ToListener(opts ...configtls.LoadOption) (net.Listener, error) {
+	vsock, isVsock, err := vsockAddress(hss.Endpoint, true)
+	if err != nil {
+		return nil, err
+	}
+	var listener net.Listener
+	if path, ok := unixSocketPath(hss.Endpoint); ok {
+		listener, err = listenUnix(path, hss.UnixSocket)
+	} else if hss.UnixSocket != nil {
+		return nil, fmt.Errorf("unix_socket requires an endpoint prefixed by %q", unixSchemePrefix)
+	} else if isVsock {
+		listener, err = listenVsock(vsock)
+	} else {
+		listener, err = net.Listen("tcp", hss.Endpoint)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	if hss.TLSSetting != nil {
 		var tlsCfg *tls.Config
-		tlsCfg, err = hss.TLSSetting.LoadTLSConfig()
+		tlsCfg, err = hss.TLSSetting.LoadTLSConfig(opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -273,6 +318,27 @@ func (hss *HTTPServerSettings) ToServer(host component.Host, settings component.
 		handler = maxRequestBodySizeInterceptor(handler, hss.MaxRequestBodySize)
 	}
 
+	if hss.RateLimit != nil {
+		if err := hss.RateLimit.ValidateAuth(hss.Auth != nil); err != nil {
+			return nil, err
+		}
+		limiter, err := hss.RateLimit.ToLimiter()
+		if err != nil {
+			return nil, err
+		}
+
+		handler = rateLimitInterceptor(handler, limiter)
+	}
+
+	if hss.Authorization != nil {
+		policy, err := hss.Authorization.ToPolicy()
+		if err != nil {
+			return nil, err
+		}
+
+		handler = authzInterceptor(handler, policy)
+	}
+
 	if hss.Auth != nil {
 		authenticator, err := hss.Auth.GetServerAuthenticator(host.GetExtensions())
 		if err != nil {
@@ -341,7 +407,12 @@ type CORSSettings struct {
 
 func authInterceptor(next http.Handler, authenticate configauth.AuthenticateFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx, err := authenticate(r.Context(), r.Header)
+		ctx := r.Context()
+		if r.TLS != nil {
+			// Makes the client certificate available to the authenticators.
+			ctx = configauth.ContextWithTLSConnectionState(ctx, r.TLS)
+		}
+		ctx, err := authenticate(ctx, r.Header)
 		if err != nil {
 			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
@@ -351,6 +422,32 @@ func authInterceptor(next http.Handler, authenticate configauth.AuthenticateFunc
 	})
 }
 
+// rateLimitInterceptor rejects the requests exceeding the rate limit of their client with the 429 status code,
+// and the number of seconds to wait before retrying in the Retry-After header.
+func rateLimitInterceptor(next http.Handler, limiter *configratelimit.Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if delay := limiter.Allow(r.Context(), r.RemoteAddr, r.Header); delay > 0 {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(delay.Seconds())), 10))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authzInterceptor rejects the requests not allowed by the policy with the 403 status code.
+func authzInterceptor(next http.Handler, policy *configauthz.Policy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := policy.Authorize(r.Context(), r.URL.Path); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func maxRequestBodySizeInterceptor(next http.Handler, maxRecvSize int64) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		r.Body = http.MaxBytesReader(w, r.Body, maxRecvSize)
@@ -16,6 +16,7 @@ package confighttp
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -35,7 +36,10 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configauthz"
+	"go.opentelemetry.io/collector/config/configratelimit"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/internal/authdata"
 )
 
 type customRoundTripper struct {
@@ -898,6 +902,150 @@ func TestServerAuth(t *testing.T) {
 	assert.True(t, authCalled)
 }
 
+func TestServerAuthTLSConnectionState(t *testing.T) {
+	hss := HTTPServerSettings{
+		Auth: &configauth.Authentication{
+			AuthenticatorID: config.NewComponentID("mock"),
+		},
+	}
+
+	var serverName string
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("mock"): configauth.NewServerAuthenticator(
+				configauth.WithAuthenticate(func(ctx context.Context, headers map[string][]string) (context.Context, error) {
+					state, ok := configauth.TLSConnectionStateFromContext(ctx)
+					if !ok {
+						return ctx, errors.New("no TLS connection state")
+					}
+					serverName = state.ServerName
+					return ctx, nil
+				}),
+			),
+		},
+	}
+
+	srv, err := hss.ToServer(host, componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.NoError(t, err)
+
+	response := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{ServerName: "example.com"}
+	response = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(response, req)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "example.com", serverName)
+}
+
+func TestServerAuthorization(t *testing.T) {
+	hss := HTTPServerSettings{
+		Auth: &configauth.Authentication{
+			AuthenticatorID: config.NewComponentID("mock"),
+		},
+		Authorization: &configauthz.Authorization{
+			Rules: []configauthz.Rule{
+				{Action: configauthz.ActionAllow, Principals: []string{"tenant-a"}, Signals: []configauthz.Signal{configauthz.SignalTraces}},
+			},
+		},
+	}
+	// The principal is the authorization header of the requests.
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("mock"): configauth.NewServerAuthenticator(
+				configauth.WithAuthenticate(func(ctx context.Context, headers map[string][]string) (context.Context, error) {
+					return authdata.NewContext(ctx, http.Header(headers).Get("Authorization"), nil), nil
+				}),
+			),
+		},
+	}
+
+	srv, err := hss.ToServer(host, componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		principal string
+		path      string
+		status    int
+	}{
+		{principal: "tenant-a", path: "/v1/traces", status: http.StatusOK},
+		{principal: "tenant-a", path: "/v1/metrics", status: http.StatusForbidden},
+		{principal: "tenant-b", path: "/v1/traces", status: http.StatusForbidden},
+	} {
+		req := httptest.NewRequest("POST", tt.path, nil)
+		req.Header.Set("Authorization", tt.principal)
+		response := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(response, req)
+		assert.Equal(t, tt.status, response.Code, "%s %s", tt.principal, tt.path)
+	}
+}
+
+func TestServerAuthorizationError(t *testing.T) {
+	hss := HTTPServerSettings{
+		Authorization: &configauthz.Authorization{
+			DefaultAction: "maybe",
+		},
+	}
+	_, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	assert.EqualError(t, err, `invalid default_action: unknown action "maybe"`)
+}
+
+func TestServerRateLimit(t *testing.T) {
+	hss := HTTPServerSettings{
+		Auth: &configauth.Authentication{
+			AuthenticatorID: config.NewComponentID("mock"),
+		},
+		RateLimit: &configratelimit.RateLimit{
+			RequestsPerSecond: 0.5,
+			Key:               configratelimit.KeySourcePrincipal,
+		},
+	}
+	// The principal is the authorization header of the requests.
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("mock"): configauth.NewServerAuthenticator(
+				configauth.WithAuthenticate(func(ctx context.Context, headers map[string][]string) (context.Context, error) {
+					return authdata.NewContext(ctx, http.Header(headers).Get("Authorization"), nil), nil
+				}),
+			),
+		},
+	}
+
+	srv, err := hss.ToServer(host, componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		principal  string
+		status     int
+		retryAfter string
+	}{
+		{principal: "tenant-a", status: http.StatusOK},
+		{principal: "tenant-a", status: http.StatusTooManyRequests, retryAfter: "2"},
+		{principal: "tenant-b", status: http.StatusOK},
+	} {
+		req := httptest.NewRequest("POST", "/v1/traces", nil)
+		req.Header.Set("Authorization", tt.principal)
+		response := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(response, req)
+		assert.Equal(t, tt.status, response.Code, tt.principal)
+		assert.Equal(t, tt.retryAfter, response.Header().Get("Retry-After"), tt.principal)
+	}
+}
+
+func TestServerRateLimitError(t *testing.T) {
+	hss := HTTPServerSettings{
+		RateLimit: &configratelimit.RateLimit{RequestsPerSecond: 1, Key: "tenant"},
+	}
+	_, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	assert.EqualError(t, err, `unknown key "tenant"`)
+
+	hss.RateLimit = &configratelimit.RateLimit{RequestsPerSecond: 1, Key: configratelimit.KeySourcePrincipal}
+	_, err = hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	assert.EqualError(t, err, "key principal requires the auth of the receiver to be configured")
+}
+
 func TestInvalidServerAuth(t *testing.T) {
 	hss := HTTPServerSettings{
 		Auth: &configauth.Authentication{
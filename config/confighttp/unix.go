@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confighttp // import "go.opentelemetry.io/collector/config/confighttp"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+// unixSchemePrefix prefixes the endpoints of the unix sockets, like "unix:///var/run/otel.sock".
+const unixSchemePrefix = "unix://"
+
+// UnixSocketSettings defines the permissions of the unix socket a server listens on.
+type UnixSocketSettings struct {
+	// Mode is the octal file mode of the socket, like "0660". If not set, the mode depends on the umask.
+	Mode string `mapstructure:"mode"`
+
+	// User owning the socket, a user name or ID. If not set, the socket is owned by the user of the collector.
+	User string `mapstructure:"user"`
+
+	// Group owning the socket, a group name or ID. If not set, the socket is owned by the group of the collector.
+	Group string `mapstructure:"group"`
+}
+
+// unixSocketPath returns the path of the unix socket of an endpoint like "unix:///var/run/otel.sock",
+// and whether the endpoint is a unix socket.
+func unixSocketPath(endpoint string) (string, bool) {
+	if !strings.HasPrefix(endpoint, unixSchemePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(endpoint, unixSchemePrefix), true
+}
+
+// listenUnix listens on the unix socket, replacing the socket left by a previous process. When the permissions of
+// the socket are set, the socket is created in a private directory and only moved to its path once they are set,
+// so it is never reachable with the default permissions.
+func listenUnix(path string, settings *UnixSocketSettings) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, dialErr := net.Dial("unix", path); dialErr == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unix socket %s is in use", path)
+		}
+		if err = os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale unix socket: %w", err)
+		}
+	}
+
+	if settings == nil {
+		return net.Listen("unix", path)
+	}
+
+	// The directory is created next to the socket, so the socket is moved within the same file system.
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the unix socket: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	tmpPath := filepath.Join(dir, "s")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is removed from its final path on close.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err = settings.apply(tmpPath); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to move the unix socket: %w", err)
+	}
+	return &unixListener{Listener: listener, path: path}, nil
+}
+
+// unixListener removes the socket moved to path when closed.
+type unixListener struct {
+	net.Listener
+	path string
+}
+
+func (l *unixListener) Close() error {
+	err := l.Listener.Close()
+	if removeErr := os.Remove(l.path); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// apply sets the mode and the ownership of the socket.
+func (s *UnixSocketSettings) apply(path string) error {
+	if s.Mode != "" {
+		mode, err := strconv.ParseUint(s.Mode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid unix socket mode %q: %w", s.Mode, err)
+		}
+		if err = os.Chmod(path, os.FileMode(mode)); err != nil {
+			return fmt.Errorf("failed to set the mode of the unix socket: %w", err)
+		}
+	}
+	if s.User == "" && s.Group == "" {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	var err error
+	if s.User != "" {
+		uid, err = lookupID(s.User, func(name string) (string, error) {
+			u, lookupErr := user.Lookup(name)
+			if lookupErr != nil {
+				return "", lookupErr
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid unix socket user: %w", err)
+		}
+	}
+	if s.Group != "" {
+		gid, err = lookupID(s.Group, func(name string) (string, error) {
+			g, lookupErr := user.LookupGroup(name)
+			if lookupErr != nil {
+				return "", lookupErr
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid unix socket group: %w", err)
+		}
+	}
+	if err = os.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to set the owner of the unix socket: %w", err)
+	}
+	return nil
+}
+
+// lookupID returns the numeric ID, or looks up the ID of the name.
+func lookupID(nameOrID string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, nil
+	}
+	id, err := lookup(nameOrID)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+// unixSocketEndpoint returns the socketEndpoint of the unix socket at path.
+func unixSocketEndpoint(path string) *socketEndpoint {
+	return &socketEndpoint{
+		dial: func(ctx context.Context) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+		// The URLs are the path of the socket followed by the HTTP path, like "unix:///var/run/otel.sock/v1/traces".
+		httpPath: func(u *url.URL) (string, bool) {
+			if u.Scheme != "unix" || u.Host != "" || !strings.HasPrefix(u.Path, path) {
+				return "", false
+			}
+			httpPath := strings.TrimPrefix(u.Path, path)
+			if httpPath != "" && httpPath[0] != '/' {
+				// The URL is on another socket whose path starts with the path of the socket.
+				return "", false
+			}
+			return httpPath, true
+		},
+		name: path,
+	}
+}
+
+// socketEndpoint is a unix socket or a VM socket the HTTP client sends the requests to.
+type socketEndpoint struct {
+	dial func(ctx context.Context) (net.Conn, error)
+	// httpPath returns the HTTP path of the URL of a request, and false if the URL is not on the socket.
+	httpPath func(u *url.URL) (string, bool)
+	// name identifies the socket in the errors.
+	name string
+}
+
+// newSocketEndpoint returns the socketEndpoint of an endpoint prefixed by "unix://" or "vsock://",
+// or nil for the other endpoints.
+func newSocketEndpoint(endpoint string) (*socketEndpoint, error) {
+	if path, ok := unixSocketPath(endpoint); ok {
+		return unixSocketEndpoint(path), nil
+	}
+	addr, ok, err := vsockAddress(endpoint, false)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return vsockSocketEndpoint(addr), nil
+}
+
+// socketTLS returns whether the requests sent to a unix socket or a VM socket use TLS. Plain HTTP is used unless the
+// TLS settings set a CA, a client certificate, insecure_skip_verify or a server name.
+func socketTLS(s configtls.TLSClientSetting) bool {
+	if s.Insecure {
+		return false
+	}
+	return s.CAFile != "" || s.CAPem != "" || s.CertFile != "" || s.CertPem != "" || s.InsecureSkipVerify || s.ServerName != ""
+}
+
+// socketHost is the host the requests sent to the socket are dialed for. It is reserved, so the requests to the other
+// hosts, e.g. redirected, are still dialed and proxied as usual.
+const socketHost = "socket.invalid"
+
+// socketServerName is the host of the requests received by the server on the socket, and the name its TLS
+// certificate is verified for by default.
+const socketServerName = "localhost"
+
+// socketRoundTripper sends the requests to the URLs of the socket over the connections dialed to the socket.
+type socketRoundTripper struct {
+	socket *socketEndpoint
+	scheme string
+	next   http.RoundTripper
+}
+
+func newSocketTransport(transport *http.Transport, socket *socketEndpoint, useTLS bool) http.RoundTripper {
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(addr); err == nil && host == socketHost {
+			return socket.dial(ctx)
+		}
+		return dial(ctx, network, addr)
+	}
+	if proxy := transport.Proxy; proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if req.URL.Hostname() == socketHost {
+				return nil, nil
+			}
+			return proxy(req)
+		}
+	}
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	return &socketRoundTripper{socket: socket, scheme: scheme, next: transport}
+}
+
+func (rt *socketRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "unix" && req.URL.Scheme != "vsock" {
+		return rt.next.RoundTrip(req)
+	}
+	httpPath, ok := rt.socket.httpPath(req.URL)
+	if !ok {
+		return nil, fmt.Errorf("URL %s is not on the socket %s", req.URL, rt.socket.name)
+	}
+	// The request of the caller must not be modified.
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.scheme
+	req.URL.Host = socketHost
+	req.URL.Path = httpPath
+	req.URL.RawPath = ""
+	req.Host = socketServerName
+	return rt.next.RoundTrip(req)
+}
@@ -0,0 +1,291 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confighttp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+func skipUnlessUnixSockets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket permissions are not supported on windows")
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	skipUnlessUnixSockets(t)
+	path := filepath.Join(t.TempDir(), "otel.sock")
+	hss := &HTTPServerSettings{
+		Endpoint: "unix://" + path,
+		UnixSocket: &UnixSocketSettings{
+			Mode:  "0600",
+			User:  strconv.Itoa(os.Getuid()),
+			Group: strconv.Itoa(os.Getgid()),
+		},
+	}
+	ln, err := hss.ToListener()
+	require.NoError(t, err)
+	var gotPath, gotHost, gotHeader string
+	srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotHost, gotHeader = r.URL.Path, r.Host, r.Header.Get("X-Test")
+	}))
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer srv.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSocket)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	hcs := &HTTPClientSettings{
+		Endpoint: "unix://" + path,
+		Headers:  map[string]string{"X-Test": "value"},
+	}
+	client, err := hcs.ToClient(nil, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	resp, err := client.Post(hcs.Endpoint+"/v1/traces", "application/x-protobuf", strings.NewReader("data"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/v1/traces", gotPath)
+	assert.Equal(t, "localhost", gotHost)
+	assert.Equal(t, "value", gotHeader)
+
+	// The requests to the URLs of other sockets are rejected, including the sockets whose path starts with the path
+	// of the socket.
+	_, err = client.Get("unix:///var/run/other.sock/v1/traces")
+	assert.Error(t, err)
+	_, err = client.Get(hcs.Endpoint + "2/v1/traces")
+	assert.Error(t, err)
+}
+
+func TestUnixSocketCreatedWithPermissions(t *testing.T) {
+	skipUnlessUnixSockets(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "otel.sock")
+	hss := &HTTPServerSettings{Endpoint: "unix://" + path, UnixSocket: &UnixSocketSettings{Mode: "0600"}}
+	ln, err := hss.ToListener()
+	require.NoError(t, err)
+
+	// The socket was moved from its private directory, which is removed.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "otel.sock", entries[0].Name())
+	go func() {
+		if conn, acceptErr := ln.Accept(); acceptErr == nil {
+			_ = conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// The socket is removed when the listener is closed.
+	require.NoError(t, ln.Close())
+	_, err = os.Lstat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUnixSocketStale(t *testing.T) {
+	skipUnlessUnixSockets(t)
+	path := filepath.Join(t.TempDir(), "otel.sock")
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	// The socket left by a previous process is replaced.
+	hss := &HTTPServerSettings{Endpoint: "unix://" + path}
+	ln, err := hss.ToListener()
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// The socket in use is not.
+	go func() {
+		if conn, acceptErr := ln.Accept(); acceptErr == nil {
+			_ = conn.Close()
+		}
+	}()
+	_, err = hss.ToListener()
+	assert.EqualError(t, err, "unix socket "+path+" is in use")
+}
+
+func TestUnixSocketError(t *testing.T) {
+	skipUnlessUnixSockets(t)
+	dir := t.TempDir()
+
+	for _, tt := range []struct {
+		name string
+		hss  HTTPServerSettings
+		err  string
+	}{
+		{
+			name: "tcp_endpoint",
+			hss:  HTTPServerSettings{Endpoint: "localhost:0", UnixSocket: &UnixSocketSettings{Mode: "0600"}},
+			err:  `unix_socket requires an endpoint prefixed by "unix://"`,
+		},
+		{
+			name: "invalid_mode",
+			hss:  HTTPServerSettings{Endpoint: "unix://" + filepath.Join(dir, "mode.sock"), UnixSocket: &UnixSocketSettings{Mode: "rw"}},
+			err:  `invalid unix socket mode "rw": strconv.ParseUint: parsing "rw": invalid syntax`,
+		},
+		{
+			name: "unknown_user",
+			hss:  HTTPServerSettings{Endpoint: "unix://" + filepath.Join(dir, "user.sock"), UnixSocket: &UnixSocketSettings{User: "no-such-otel-user"}},
+			err:  "invalid unix socket user: user: unknown user no-such-otel-user",
+		},
+		{
+			name: "unknown_group",
+			hss:  HTTPServerSettings{Endpoint: "unix://" + filepath.Join(dir, "group.sock"), UnixSocket: &UnixSocketSettings{Group: "no-such-otel-group"}},
+			err:  "invalid unix socket group: group: unknown group no-such-otel-group",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := tt.hss.ToListener()
+			assert.EqualError(t, err, tt.err)
+			assert.Nil(t, ln)
+		})
+	}
+}
+
+func TestUnixSocketTLS(t *testing.T) {
+	skipUnlessUnixSockets(t)
+	certPem, keyPem := selfSignedCert(t, "localhost")
+	path := filepath.Join(t.TempDir(), "otel.sock")
+	hss := &HTTPServerSettings{
+		Endpoint: "unix://" + path,
+		TLSSetting: &configtls.TLSServerSetting{
+			TLSSetting: configtls.TLSSetting{CertPem: certPem, KeyPem: keyPem},
+		},
+	}
+	ln, err := hss.ToListener()
+	require.NoError(t, err)
+	var gotTLS bool
+	srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTLS = r.TLS != nil
+	}))
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer srv.Close()
+
+	// The certificate of the server is verified for localhost.
+	hcs := &HTTPClientSettings{
+		Endpoint: "unix://" + path,
+		TLSSetting: configtls.TLSClientSetting{
+			TLSSetting: configtls.TLSSetting{CAPem: certPem},
+		},
+	}
+	client, err := hcs.ToClient(nil, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	resp, err := client.Get(hcs.Endpoint + "/v1/traces")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, gotTLS)
+
+	// Without TLS settings, the client sends plain HTTP, which the server rejects.
+	hcs = &HTTPClientSettings{Endpoint: "unix://" + path}
+	client, err = hcs.ToClient(nil, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	resp, err = client.Get(hcs.Endpoint + "/v1/traces")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// selfSignedCert returns a self-signed certificate for the DNS name, and its private key, PEM encoded.
+func selfSignedCert(t *testing.T, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestUnixTransportDialsOtherHosts(t *testing.T) {
+	skipUnlessUnixSockets(t)
+	path := filepath.Join(t.TempDir(), "otel.sock")
+	unixLn, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	unixSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The requests redirected to another host are not sent to the socket.
+		http.Redirect(w, r, "http://"+r.URL.Query().Get("to")+"/redirected", http.StatusFound)
+	})}
+	go func() {
+		_ = unixSrv.Serve(unixLn)
+	}()
+	defer unixSrv.Close()
+
+	tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var gotPath string
+	tcpSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	})}
+	go func() {
+		_ = tcpSrv.Serve(tcpLn)
+	}()
+	defer tcpSrv.Close()
+
+	hcs := &HTTPClientSettings{Endpoint: "unix://" + path}
+	client, err := hcs.ToClient(nil, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	resp, err := client.Get(hcs.Endpoint + "/v1/traces?to=" + tcpLn.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/redirected", gotPath)
+}
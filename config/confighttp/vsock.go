@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confighttp // import "go.opentelemetry.io/collector/config/confighttp"
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// vsockSchemePrefix prefixes the endpoints of the VM sockets, like "vsock://3:4318".
+	vsockSchemePrefix = "vsock://"
+	// vsockAny is the context ID or the port of a VM socket bound to any context ID or port.
+	vsockAny = math.MaxUint32
+)
+
+// vsockAddr is the address of a VM socket, the context ID (CID) of the VM and the port.
+type vsockAddr struct {
+	cid  uint32
+	port uint32
+}
+
+func (a vsockAddr) Network() string {
+	return "vsock"
+}
+
+func (a vsockAddr) String() string {
+	return strconv.FormatUint(uint64(a.cid), 10) + ":" + strconv.FormatUint(uint64(a.port), 10)
+}
+
+// vsockAddress returns the address of an endpoint like "vsock://3:4318", and whether the endpoint is a VM socket.
+// The servers can omit the context ID to listen on any context ID, like "vsock://:4318", and use the port 0
+// to listen on any port.
+func vsockAddress(endpoint string, server bool) (vsockAddr, bool, error) {
+	if !strings.HasPrefix(endpoint, vsockSchemePrefix) {
+		return vsockAddr{}, false, nil
+	}
+	cid, port, err := net.SplitHostPort(strings.TrimPrefix(endpoint, vsockSchemePrefix))
+	if err != nil {
+		return vsockAddr{}, true, fmt.Errorf("invalid vsock endpoint %q: %w", endpoint, err)
+	}
+
+	var addr vsockAddr
+	if cid == "" && server {
+		addr.cid = vsockAny
+	} else {
+		id, parseErr := strconv.ParseUint(cid, 10, 32)
+		if parseErr != nil {
+			return vsockAddr{}, true, fmt.Errorf("invalid context ID of the vsock endpoint %q: %w", endpoint, parseErr)
+		}
+		addr.cid = uint32(id)
+	}
+	p, err := strconv.ParseUint(port, 10, 32)
+	if err != nil {
+		return vsockAddr{}, true, fmt.Errorf("invalid port of the vsock endpoint %q: %w", endpoint, err)
+	}
+	addr.port = uint32(p)
+	if addr.port == 0 && server {
+		addr.port = vsockAny
+	}
+	return addr, true, nil
+}
+
+// vsockSocketEndpoint returns the socketEndpoint of the VM socket at addr.
+func vsockSocketEndpoint(addr vsockAddr) *socketEndpoint {
+	return &socketEndpoint{
+		dial: func(ctx context.Context) (net.Conn, error) {
+			return dialVsock(ctx, addr)
+		},
+		// The URLs are the endpoint followed by the HTTP path, like "vsock://3:4318/v1/traces".
+		httpPath: func(u *url.URL) (string, bool) {
+			return u.Path, u.Scheme == "vsock" && u.Host == addr.String()
+		},
+		name: vsockSchemePrefix + addr.String(),
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package confighttp // import "go.opentelemetry.io/collector/config/confighttp"
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// The VM sockets are not supported by the net package, their non-blocking file descriptors are wrapped in os.File
+// instead, which waits for them to be ready with the runtime network poller.
+
+// newVsockFile creates a non-blocking VM socket.
+func newVsockFile(addr vsockAddr) (*os.File, error) {
+	fd, err := unix.Socket(unix.AF_VSOCK, unix.SOCK_STREAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	return os.NewFile(uintptr(fd), vsockSchemePrefix+addr.String()), nil
+}
+
+// vsockSockname returns the local address of the VM socket.
+func vsockSockname(fd uintptr) vsockAddr {
+	sa, err := unix.Getsockname(int(fd))
+	if err != nil {
+		return vsockAddr{}
+	}
+	return sockaddrToVsock(sa)
+}
+
+func sockaddrToVsock(sa unix.Sockaddr) vsockAddr {
+	if vm, ok := sa.(*unix.SockaddrVM); ok {
+		return vsockAddr{cid: vm.CID, port: vm.Port}
+	}
+	return vsockAddr{}
+}
+
+// control calls fn with the file descriptor of the file, and returns the first error.
+func control(file *os.File, fn func(fd uintptr) error) error {
+	rc, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err = rc.Control(func(fd uintptr) {
+		fnErr = fn(fd)
+	}); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+func listenVsock(addr vsockAddr) (net.Listener, error) {
+	file, err := newVsockFile(addr)
+	if err != nil {
+		return nil, err
+	}
+	var local vsockAddr
+	err = control(file, func(fd uintptr) error {
+		if bindErr := unix.Bind(int(fd), &unix.SockaddrVM{CID: addr.cid, Port: addr.port}); bindErr != nil {
+			return os.NewSyscallError("bind", bindErr)
+		}
+		if listenErr := unix.Listen(int(fd), unix.SOMAXCONN); listenErr != nil {
+			return os.NewSyscallError("listen", listenErr)
+		}
+		local = vsockSockname(fd)
+		return nil
+	})
+	if err != nil {
+		_ = file.Close()
+		return nil, &net.OpError{Op: "listen", Net: "vsock", Addr: addr, Err: err}
+	}
+	return &vsockListener{file: file, addr: local}, nil
+}
+
+// vsockListener accepts the connections of a VM socket.
+type vsockListener struct {
+	file *os.File
+	addr vsockAddr
+}
+
+func (l *vsockListener) Accept() (net.Conn, error) {
+	rc, err := l.file.SyscallConn()
+	if err != nil {
+		return nil, &net.OpError{Op: "accept", Net: "vsock", Addr: l.addr, Err: err}
+	}
+	var nfd int
+	var sa unix.Sockaddr
+	var acceptErr error
+	err = rc.Read(func(fd uintptr) bool {
+		for {
+			nfd, sa, acceptErr = unix.Accept4(int(fd), unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC)
+			// The connections reset before being accepted are skipped.
+			if acceptErr != unix.ECONNABORTED {
+				break
+			}
+		}
+		// Wait for the next connection.
+		return acceptErr != unix.EAGAIN
+	})
+	if err == nil && acceptErr != nil {
+		err = os.NewSyscallError("accept4", acceptErr)
+	}
+	if err != nil {
+		return nil, &net.OpError{Op: "accept", Net: "vsock", Addr: l.addr, Err: err}
+	}
+	file := os.NewFile(uintptr(nfd), vsockSchemePrefix+l.addr.String())
+	return &vsockConn{File: file, local: vsockSockname(uintptr(nfd)), remote: sockaddrToVsock(sa)}, nil
+}
+
+func (l *vsockListener) Close() error {
+	return l.file.Close()
+}
+
+func (l *vsockListener) Addr() net.Addr {
+	return l.addr
+}
+
+// vsockConn is a connection of a VM socket, the os.File implements reading, writing and the deadlines.
+type vsockConn struct {
+	*os.File
+	local  vsockAddr
+	remote vsockAddr
+}
+
+func (c *vsockConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *vsockConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func dialVsock(ctx context.Context, addr vsockAddr) (net.Conn, error) {
+	file, err := newVsockFile(addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := connectVsock(ctx, file, addr)
+	if err != nil {
+		_ = file.Close()
+		return nil, &net.OpError{Op: "dial", Net: "vsock", Addr: addr, Err: err}
+	}
+	return conn, nil
+}
+
+// connectVsock connects the VM socket to addr, waiting for the connection to be established until ctx is done.
+func connectVsock(ctx context.Context, file *os.File, addr vsockAddr) (net.Conn, error) {
+	rc, err := file.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var connectErr error
+	if err = rc.Control(func(fd uintptr) {
+		connectErr = unix.Connect(int(fd), &unix.SockaddrVM{CID: addr.cid, Port: addr.port})
+	}); err != nil {
+		return nil, err
+	}
+
+	if connectErr == unix.EINPROGRESS {
+		// Interrupt waiting for the connection when the context is done.
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				_ = file.SetWriteDeadline(time.Unix(1, 0))
+			case <-done:
+			}
+		}()
+		waited := false
+		err = rc.Write(func(fd uintptr) bool {
+			// The socket is writable once the connection is established or failed.
+			if !waited {
+				waited = true
+				return false
+			}
+			soErr, getErr := unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ERROR)
+			if getErr != nil {
+				connectErr = getErr
+			} else if soErr != 0 {
+				connectErr = unix.Errno(soErr)
+			} else {
+				connectErr = nil
+			}
+			return true
+		})
+		close(done)
+		<-stopped
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if connectErr != nil {
+		return nil, os.NewSyscallError("connect", connectErr)
+	}
+
+	var local vsockAddr
+	_ = control(file, func(fd uintptr) error {
+		local = vsockSockname(fd)
+		return nil
+	})
+	return &vsockConn{File: file, local: local, remote: addr}, nil
+}
@@ -0,0 +1,34 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package confighttp // import "go.opentelemetry.io/collector/config/confighttp"
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+var errVsockUnsupported = errors.New("vsock endpoints are only supported on linux")
+
+func listenVsock(vsockAddr) (net.Listener, error) {
+	return nil, errVsockUnsupported
+}
+
+func dialVsock(context.Context, vsockAddr) (net.Conn, error) {
+	return nil, errVsockUnsupported
+}
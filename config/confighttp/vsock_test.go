@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confighttp
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestVsockAddress(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		server   bool
+		want     vsockAddr
+		isVsock  bool
+		err      string
+	}{
+		{endpoint: "localhost:4318"},
+		{endpoint: "vsock://3:4318", want: vsockAddr{cid: 3, port: 4318}, isVsock: true},
+		{endpoint: "vsock://:4318", server: true, want: vsockAddr{cid: vsockAny, port: 4318}, isVsock: true},
+		{endpoint: "vsock://2:0", server: true, want: vsockAddr{cid: 2, port: vsockAny}, isVsock: true},
+		{endpoint: "vsock://:4318", isVsock: true, err: `invalid context ID of the vsock endpoint "vsock://:4318": strconv.ParseUint: parsing "": invalid syntax`},
+		{endpoint: "vsock://host:4318", isVsock: true, err: `invalid context ID of the vsock endpoint "vsock://host:4318": strconv.ParseUint: parsing "host": invalid syntax`},
+		{endpoint: "vsock://3:port", isVsock: true, err: `invalid port of the vsock endpoint "vsock://3:port": strconv.ParseUint: parsing "port": invalid syntax`},
+		{endpoint: "vsock://3", isVsock: true, err: `invalid vsock endpoint "vsock://3": address 3: missing port in address`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			addr, isVsock, err := vsockAddress(tt.endpoint, tt.server)
+			assert.Equal(t, tt.isVsock, isVsock)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, addr)
+		})
+	}
+}
+
+func TestVsock(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("vsock endpoints are only supported on linux")
+	}
+	hss := &HTTPServerSettings{Endpoint: "vsock://:0"}
+	ln, err := hss.ToListener()
+	if err != nil {
+		t.Skipf("vsock is not available: %v", err)
+	}
+	var gotPath, gotHost string
+	srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotHost = r.URL.Path, r.Host
+	}))
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer srv.Close()
+
+	// The local context ID reaches the sockets of the host.
+	port := ln.Addr().(vsockAddr).port
+	hcs := &HTTPClientSettings{Endpoint: "vsock://1:" + strconv.FormatUint(uint64(port), 10)}
+	client, err := hcs.ToClient(nil, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	resp, err := client.Post(hcs.Endpoint+"/v1/traces", "application/x-protobuf", strings.NewReader("data"))
+	if err != nil && strings.Contains(err.Error(), "connect") {
+		t.Skipf("vsock loopback is not available: %v", err)
+	}
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/v1/traces", gotPath)
+	assert.Equal(t, "localhost", gotHost)
+
+	// The requests to the URLs of other sockets are rejected.
+	_, err = client.Get("vsock://2:4318/v1/traces")
+	assert.Error(t, err)
+}
+
+func TestVsockListenerClose(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("vsock endpoints are only supported on linux")
+	}
+	hss := &HTTPServerSettings{Endpoint: "vsock://:0"}
+	ln, err := hss.ToListener()
+	if err != nil {
+		t.Skipf("vsock is not available: %v", err)
+	}
+	assert.NotEqual(t, uint32(vsockAny), ln.Addr().(vsockAddr).port)
+
+	accepted := make(chan error, 1)
+	go func() {
+		_, acceptErr := ln.Accept()
+		accepted <- acceptErr
+	}()
+	require.NoError(t, ln.Close())
+	select {
+	case err = <-accepted:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "accept was not unblocked by close")
+	}
+}
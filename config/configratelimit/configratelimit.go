@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configratelimit // import "go.opentelemetry.io/collector/config/configratelimit"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/internal/authdata"
+	"go.opentelemetry.io/collector/internal/ratelimit"
+)
+
+const (
+	// minSweepInterval bounds how often the buckets of the idle clients are looked for.
+	minSweepInterval = time.Second
+
+	// defaultMaxClients is the default number of clients with their own bucket.
+	defaultMaxClients = 10000
+)
+
+// KeySource identifies the clients sharing a rate limit.
+type KeySource string
+
+const (
+	// KeySourcePeerAddress limits each client IP address, or unix socket address.
+	KeySourcePeerAddress KeySource = "peer_address"
+	// KeySourcePrincipal limits each principal authenticated by the authenticator of the receiver.
+	KeySourcePrincipal KeySource = "principal"
+	// KeySourceMetadata limits each value of a gRPC metadata or HTTP header.
+	KeySourceMetadata KeySource = "metadata"
+)
+
+// RateLimit defines the rate limit applied by a receiver to each of its clients, evaluated after the authentication
+// and the authorization of the requests. Limits are enforced with a token bucket per client.
+type RateLimit struct {
+	// RequestsPerSecond is the maximum rate of requests of each client, required.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+
+	// RequestsBurst is the maximum number of requests of each client at once above RequestsPerSecond.
+	// Zero means as many requests as allowed in one second.
+	RequestsBurst int `mapstructure:"requests_burst"`
+
+	// Key identifies the clients sharing a rate limit. Defaults to "peer_address".
+	Key KeySource `mapstructure:"key"`
+
+	// PrincipalAttribute is the client.AuthData attribute holding the principal of the clients, for the
+	// "principal" key. Defaults to "principal", set by the authenticators of this repository.
+	PrincipalAttribute string `mapstructure:"principal_attribute"`
+
+	// MetadataKey is the name of the gRPC metadata or HTTP header identifying the clients, for the "metadata" key.
+	MetadataKey string `mapstructure:"metadata_key"`
+
+	// MaxClients is the maximum number of clients with their own rate limit, the other clients share one rate limit
+	// until some buckets become idle. It bounds the memory used when the clients choose their key, like with the
+	// "metadata" key. Defaults to 10000.
+	MaxClients int `mapstructure:"max_clients"`
+}
+
+// Validate checks if the rate limit is valid.
+func (rl *RateLimit) Validate() error {
+	if rl.RequestsPerSecond <= 0 {
+		return errors.New("requests_per_second must be positive")
+	}
+	if rl.RequestsBurst < 0 {
+		return errors.New("requests_burst must not be negative")
+	}
+	if rl.MaxClients < 0 {
+		return errors.New("max_clients must not be negative")
+	}
+	switch rl.Key {
+	case "", KeySourcePeerAddress, KeySourcePrincipal:
+	case KeySourceMetadata:
+		if rl.MetadataKey == "" {
+			return errors.New("metadata_key must be set with the metadata key")
+		}
+	default:
+		return fmt.Errorf("unknown key %q", rl.Key)
+	}
+	return nil
+}
+
+// ValidateAuth checks that the clients are authenticated when they are identified by their principal, as
+// the requests of all the clients would otherwise share one rate limit.
+func (rl *RateLimit) ValidateAuth(authenticated bool) error {
+	if rl.Key == KeySourcePrincipal && !authenticated {
+		return errors.New("key principal requires the auth of the receiver to be configured")
+	}
+	return nil
+}
+
+// ToLimiter creates the Limiter applying the rate limit.
+func (rl *RateLimit) ToLimiter() (*Limiter, error) {
+	if err := rl.Validate(); err != nil {
+		return nil, err
+	}
+	l := &Limiter{
+		rate:               rl.RequestsPerSecond,
+		burst:              rl.RequestsBurst,
+		key:                rl.Key,
+		principalAttribute: rl.PrincipalAttribute,
+		metadataKeys:       []string{strings.ToLower(rl.MetadataKey), http.CanonicalHeaderKey(rl.MetadataKey)},
+		maxClients:         rl.MaxClients,
+		clients:            make(map[string]*clientBucket),
+		overflow:           ratelimit.NewTokenBucket(rl.RequestsPerSecond, rl.RequestsBurst),
+		now:                time.Now,
+	}
+	if l.maxClients == 0 {
+		l.maxClients = defaultMaxClients
+	}
+	if l.key == "" {
+		l.key = KeySourcePeerAddress
+	}
+	if l.principalAttribute == "" {
+		l.principalAttribute = authdata.PrincipalAttribute
+	}
+	// A bucket not used for the time it takes to refill is full, like a new one, so it can be forgotten.
+	burst := float64(rl.RequestsBurst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(rl.RequestsPerSecond))
+	}
+	l.idleTimeout = time.Duration(math.Ceil(burst / rl.RequestsPerSecond * float64(time.Second)))
+	l.sweepInterval = l.idleTimeout
+	if l.sweepInterval < minSweepInterval {
+		l.sweepInterval = minSweepInterval
+	}
+	l.nextSweep = l.now().Add(l.sweepInterval)
+	return l, nil
+}
+
+// Limiter limits the rate of the requests of each client with the settings of a RateLimit.
+type Limiter struct {
+	rate               float64
+	burst              int
+	key                KeySource
+	principalAttribute string
+	metadataKeys       []string
+	maxClients         int
+	idleTimeout        time.Duration
+	sweepInterval      time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*clientBucket
+	// overflow is the bucket shared by the clients above maxClients, so they cannot evict the buckets of the
+	// other clients to reset their own rate limit.
+	overflow  *ratelimit.TokenBucket
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type clientBucket struct {
+	bucket   *ratelimit.TokenBucket
+	lastUsed time.Time
+}
+
+// Allow takes a token of the bucket of the client sending the request, and returns zero if the request is
+// allowed. Otherwise it returns how long the client should wait before retrying.
+// The client is identified by the peer address, like "10.0.0.1:4317", the client.Info.Auth in the context, or
+// the gRPC metadata or HTTP headers, depending on the key of the RateLimit. The requests without principal or
+// metadata share the same bucket, as do the new clients while MaxClients clients have their own bucket.
+func (l *Limiter) Allow(ctx context.Context, peerAddr string, metadata map[string][]string) time.Duration {
+	return l.bucket(l.clientKey(ctx, peerAddr, metadata)).Take(1)
+}
+
+func (l *Limiter) clientKey(ctx context.Context, peerAddr string, metadata map[string][]string) string {
+	switch l.key {
+	case KeySourcePrincipal:
+		if auth := client.FromContext(ctx).Auth; auth != nil {
+			principal, _ := auth.GetAttribute(l.principalAttribute).(string)
+			return principal
+		}
+		return ""
+	case KeySourceMetadata:
+		for _, key := range l.metadataKeys {
+			if values := metadata[key]; len(values) > 0 {
+				return strings.Join(values, ",")
+			}
+		}
+		return ""
+	default:
+		// Each connection of a client has its own port.
+		if host, _, err := net.SplitHostPort(peerAddr); err == nil {
+			return host
+		}
+		return peerAddr
+	}
+}
+
+func (l *Limiter) bucket(key string) *ratelimit.TokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.After(l.nextSweep) {
+		for k, cb := range l.clients {
+			if now.Sub(cb.lastUsed) > l.idleTimeout {
+				delete(l.clients, k)
+			}
+		}
+		l.nextSweep = now.Add(l.sweepInterval)
+	}
+	cb, ok := l.clients[key]
+	if !ok {
+		if len(l.clients) >= l.maxClients {
+			return l.overflow
+		}
+		cb = &clientBucket{bucket: ratelimit.NewTokenBucket(l.rate, l.burst)}
+		l.clients[key] = cb
+	}
+	cb.lastUsed = now
+	return cb.bucket
+}
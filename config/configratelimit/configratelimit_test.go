@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/internal/authdata"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		rl  RateLimit
+		err string
+	}{
+		{rl: RateLimit{RequestsPerSecond: 10}},
+		{rl: RateLimit{RequestsPerSecond: 10, RequestsBurst: 20, Key: KeySourcePrincipal}},
+		{rl: RateLimit{RequestsPerSecond: 10, Key: KeySourceMetadata, MetadataKey: "X-Tenant"}},
+		{rl: RateLimit{}, err: "requests_per_second must be positive"},
+		{rl: RateLimit{RequestsPerSecond: -1}, err: "requests_per_second must be positive"},
+		{rl: RateLimit{RequestsPerSecond: 10, RequestsBurst: -1}, err: "requests_burst must not be negative"},
+		{rl: RateLimit{RequestsPerSecond: 10, MaxClients: -1}, err: "max_clients must not be negative"},
+		{rl: RateLimit{RequestsPerSecond: 10, Key: KeySourceMetadata}, err: "metadata_key must be set with the metadata key"},
+		{rl: RateLimit{RequestsPerSecond: 10, Key: "tenant"}, err: `unknown key "tenant"`},
+	} {
+		err := tt.rl.Validate()
+		if tt.err == "" {
+			assert.NoError(t, err, tt.rl)
+			continue
+		}
+		assert.EqualError(t, err, tt.err)
+		_, err = tt.rl.ToLimiter()
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	rl := RateLimit{RequestsPerSecond: 10, Key: KeySourcePrincipal}
+	assert.NoError(t, rl.ValidateAuth(true))
+	assert.EqualError(t, rl.ValidateAuth(false), "key principal requires the auth of the receiver to be configured")
+
+	rl.Key = KeySourcePeerAddress
+	assert.NoError(t, rl.ValidateAuth(false))
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l, err := (&RateLimit{RequestsPerSecond: 2, RequestsBurst: 1}).ToLimiter()
+	require.NoError(t, err)
+
+	assert.Zero(t, l.Allow(context.Background(), "10.0.0.1:1234", nil))
+	delay := l.Allow(context.Background(), "10.0.0.1:1234", nil)
+	assert.Greater(t, delay, time.Duration(0))
+	assert.LessOrEqual(t, delay, 500*time.Millisecond)
+
+	// The connections of a client share its limit, the other clients have their own.
+	assert.Greater(t, l.Allow(context.Background(), "10.0.0.1:5678", nil), time.Duration(0))
+	assert.Zero(t, l.Allow(context.Background(), "10.0.0.2:1234", nil))
+}
+
+func TestLimiterClientKey(t *testing.T) {
+	ctx := authdata.NewContext(context.Background(), "tenant-a", []string{"tenants"})
+	metadata := map[string][]string{"x-tenant": {"tenant-b"}}
+
+	for _, tt := range []struct {
+		name     string
+		rl       RateLimit
+		ctx      context.Context
+		addr     string
+		metadata map[string][]string
+		key      string
+	}{
+		{name: "peer_address", rl: RateLimit{}, ctx: ctx, addr: "10.0.0.1:1234", metadata: metadata, key: "10.0.0.1"},
+		{name: "peer_address_ipv6", rl: RateLimit{Key: KeySourcePeerAddress}, addr: "[::1]:1234", key: "::1"},
+		{name: "peer_address_unix", rl: RateLimit{Key: KeySourcePeerAddress}, addr: "/tmp/otlp.sock", key: "/tmp/otlp.sock"},
+		{name: "principal", rl: RateLimit{Key: KeySourcePrincipal}, ctx: ctx, addr: "10.0.0.1:1234", key: "tenant-a"},
+		{name: "principal_attribute", rl: RateLimit{Key: KeySourcePrincipal, PrincipalAttribute: "tenant"}, ctx: ctx},
+		{name: "principal_not_authenticated", rl: RateLimit{Key: KeySourcePrincipal}, ctx: client.NewContext(context.Background(), client.Info{})},
+		{name: "grpc_metadata", rl: RateLimit{Key: KeySourceMetadata, MetadataKey: "X-Tenant"}, metadata: metadata, key: "tenant-b"},
+		{name: "http_header", rl: RateLimit{Key: KeySourceMetadata, MetadataKey: "x-tenant"}, metadata: map[string][]string{"X-Tenant": {"tenant-c"}}, key: "tenant-c"},
+		{name: "missing_metadata", rl: RateLimit{Key: KeySourceMetadata, MetadataKey: "x-tenant"}, metadata: map[string][]string{"authorization": {"secret"}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rl.RequestsPerSecond = 1
+			l, err := tt.rl.ToLimiter()
+			require.NoError(t, err)
+			if tt.ctx == nil {
+				tt.ctx = context.Background()
+			}
+			assert.Equal(t, tt.key, l.clientKey(tt.ctx, tt.addr, tt.metadata))
+		})
+	}
+}
+
+func TestLimiterMaxClients(t *testing.T) {
+	l, err := (&RateLimit{RequestsPerSecond: 1, RequestsBurst: 1, Key: KeySourceMetadata, MetadataKey: "x-tenant", MaxClients: 2}).ToLimiter()
+	require.NoError(t, err)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	allow := func(tenant string) time.Duration {
+		return l.Allow(context.Background(), "", map[string][]string{"x-tenant": {tenant}})
+	}
+
+	assert.Zero(t, allow("tenant-a"))
+	assert.Zero(t, allow("tenant-b"))
+	// The clients above the maximum share one bucket, and do not reset the buckets of the other clients.
+	assert.Zero(t, allow("tenant-c"))
+	assert.Greater(t, allow("tenant-d"), time.Duration(0))
+	assert.Greater(t, allow("tenant-a"), time.Duration(0))
+	assert.Len(t, l.clients, 2)
+
+	// The new clients get their own bucket once the idle buckets are forgotten.
+	now = now.Add(3 * time.Second)
+	assert.Zero(t, allow("tenant-d"))
+	assert.Len(t, l.clients, 1)
+	assert.Contains(t, l.clients, "tenant-d")
+}
+
+func TestLimiterForgetsIdleClients(t *testing.T) {
+	l, err := (&RateLimit{RequestsPerSecond: 1, RequestsBurst: 5}).ToLimiter()
+	require.NoError(t, err)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	l.Allow(context.Background(), "10.0.0.1:1234", nil)
+	now = now.Add(3 * time.Second)
+	l.Allow(context.Background(), "10.0.0.2:1234", nil)
+	assert.Len(t, l.clients, 2)
+
+	// The buckets not used for the time it takes to refill them are forgotten.
+	now = now.Add(3 * time.Second)
+	l.Allow(context.Background(), "10.0.0.3:1234", nil)
+	assert.Len(t, l.clients, 2)
+	assert.Contains(t, l.clients, "10.0.0.2")
+	assert.Contains(t, l.clients, "10.0.0.3")
+}
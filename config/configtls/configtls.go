@@ -15,13 +15,25 @@
 package configtls // import "go.opentelemetry.io/collector/config/configtls"
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 )
 
 // TLSSetting exposes the common client and server TLS configurations.
@@ -39,6 +51,15 @@ type TLSSetting struct {
 	// Path to the TLS key to use for TLS required connections. (optional)
 	KeyFile string `mapstructure:"key_file"`
 
+	// In memory PEM encoded CA cert, used like the CAFile. Cannot be set with CAFile. (optional)
+	CAPem string `mapstructure:"ca_pem"`
+
+	// In memory PEM encoded TLS cert, used like the CertFile. Cannot be set with CertFile. (optional)
+	CertPem string `mapstructure:"cert_pem"`
+
+	// In memory PEM encoded TLS key, used like the KeyFile. Cannot be set with KeyFile. (optional)
+	KeyPem string `mapstructure:"key_pem"`
+
 	// MinVersion sets the minimum TLS version that is acceptable.
 	// If not set, TLS 1.0 is used. (optional)
 	MinVersion string `mapstructure:"min_version"`
@@ -47,11 +68,52 @@ type TLSSetting struct {
 	// If not set, TLS 1.3 is used. (optional)
 	MaxVersion string `mapstructure:"max_version"`
 
-	// ReloadInterval specifies the duration after which the certificate will be reloaded
-	// If not set, it will never be reloaded (optional)
+	// CipherSuites is the list of the cipher suites allowed for TLS 1.0 to 1.2, named like in
+	// https://pkg.go.dev/crypto/tls#pkg-constants, e.g. TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384.
+	// The TLS 1.3 cipher suites are not configurable. If not set, the Go defaults are used. (optional)
+	CipherSuites []string `mapstructure:"cipher_suites"`
+
+	// CurvePreferences is the list of the elliptic curves used in the key exchanges, in order of
+	// preference: X25519, P256, P384 or P521. If not set, the Go defaults are used. (optional)
+	CurvePreferences []string `mapstructure:"curve_preferences"`
+
+	// ReloadInterval specifies the duration after which the certificate, the CA, the client CA and
+	// the CRL will be reloaded. If not set, they will never be reloaded (optional)
 	ReloadInterval time.Duration `mapstructure:"reload_interval"`
 }
 
+// LoadOption configures how the TLS configurations are loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	logger   *zap.Logger
+	endpoint string
+}
+
+// WithLogger sets the logger reporting the reloads of the certificates and CAs, and their failures.
+func WithLogger(logger *zap.Logger) LoadOption {
+	return func(o *loadOptions) {
+		o.logger = logger
+	}
+}
+
+// WithEndpoint sets the endpoint the client connects to, given as a URL, a gRPC target or a host and port. When the CA
+// is reloaded, its host is verified against the certificates of the servers which are not sent a server name,
+// i.e. IP addresses, unless ServerName is set.
+func WithEndpoint(endpoint string) LoadOption {
+	return func(o *loadOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+func newLoadOptions(opts []LoadOption) *loadOptions {
+	o := &loadOptions{logger: zap.NewNop()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // TLSClientSetting contains TLS configurations that are specific to client
 // connections in addition to the common configurations. This should be used by
 // components configuring TLS client connections.
@@ -89,11 +151,73 @@ type TLSServerSetting struct {
 	// This sets the ClientCAs and ClientAuth to RequireAndVerifyClientCert in the TLSConfig. Please refer to
 	// https://godoc.org/crypto/tls#Config for more information. (optional)
 	ClientCAFile string `mapstructure:"client_ca_file"`
+
+	// In memory PEM encoded cert to use by the server to verify a client certificate, used like the
+	// ClientCAFile. Cannot be set with ClientCAFile. (optional)
+	ClientCAPem string `mapstructure:"client_ca_pem"`
+
+	// Path to the certificate revocation lists, PEM or DER encoded, the client certificates are checked against.
+	// The file is checked for changes every ReloadInterval. The clients are rejected while the CRL of their issuer is
+	// expired. Requires the ClientCAFile or the ClientCAPem. (optional)
+	CRLFile string `mapstructure:"crl_file"`
+
+	// OCSPStapling sets how the OCSP responses stapled by the clients to their certificate are checked:
+	// "none" ignores them, "optional" checks them when the clients provide one, and "required" also rejects
+	// the clients not providing one. The clients only staple OCSP responses with TLS 1.3. Requires the
+	// ClientCAFile or the ClientCAPem. (optional, default "none")
+	OCSPStapling string `mapstructure:"ocsp_stapling"`
+}
+
+// Validate checks the TLS settings are valid, without loading the certificates.
+func (c TLSSetting) Validate() error {
+	if c.CAFile != "" && c.CAPem != "" {
+		return errors.New("ca_file and ca_pem cannot be both provided")
+	}
+	if c.CertFile != "" && c.CertPem != "" {
+		return errors.New("cert_file and cert_pem cannot be both provided")
+	}
+	if c.KeyFile != "" && c.KeyPem != "" {
+		return errors.New("key_file and key_pem cannot be both provided")
+	}
+	if _, err := convertVersion(c.MinVersion); err != nil {
+		return fmt.Errorf("invalid TLS min_version: %w", err)
+	}
+	if _, err := convertVersion(c.MaxVersion); err != nil {
+		return fmt.Errorf("invalid TLS max_version: %w", err)
+	}
+	if _, err := convertCipherSuites(c.CipherSuites); err != nil {
+		return fmt.Errorf("invalid TLS cipher_suites: %w", err)
+	}
+	if _, err := convertCurves(c.CurvePreferences); err != nil {
+		return fmt.Errorf("invalid TLS curve_preferences: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the TLS server settings are valid, without loading the certificates.
+func (c TLSServerSetting) Validate() error {
+	if c.ClientCAFile != "" && c.ClientCAPem != "" {
+		return errors.New("client_ca_file and client_ca_pem cannot be both provided")
+	}
+	switch c.OCSPStapling {
+	case "", ocspStaplingNone, ocspStaplingOptional, ocspStaplingRequired:
+	default:
+		return fmt.Errorf("invalid TLS ocsp_stapling: unsupported value: %q", c.OCSPStapling)
+	}
+	if c.checksRevocation() && c.ClientCAFile == "" && c.ClientCAPem == "" {
+		return errors.New("crl_file and ocsp_stapling require client_ca_file or client_ca_pem")
+	}
+	return c.TLSSetting.Validate()
+}
+
+func (c TLSServerSetting) checksRevocation() bool {
+	return c.CRLFile != "" || (c.OCSPStapling != "" && c.OCSPStapling != ocspStaplingNone)
 }
 
 // certReloader is a wrapper object for certificate reloading
 // Its GetCertificate method will either return the current certificate or reload from disk
-// if the last reload happened more than ReloadInterval ago
+// if the last reload happened more than ReloadInterval ago. When the reload fails, the failure
+// is reported and the last certificate is kept.
 type certReloader struct {
 	// Path to the TLS cert
 	CertFile string
@@ -105,9 +229,10 @@ type certReloader struct {
 	nextReload     time.Time
 	cert           *tls.Certificate
 	lock           sync.RWMutex
+	logger         *zap.Logger
 }
 
-func newCertReloader(certFile, keyFile string, reloadInterval time.Duration) (*certReloader, error) {
+func newCertReloader(certFile, keyFile string, reloadInterval time.Duration, logger *zap.Logger) (*certReloader, error) {
 	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
@@ -118,6 +243,7 @@ func newCertReloader(certFile, keyFile string, reloadInterval time.Duration) (*c
 		ReloadInterval: reloadInterval,
 		nextReload:     time.Now().Add(reloadInterval),
 		cert:           &cert,
+		logger:         logger,
 	}, nil
 }
 
@@ -132,47 +258,213 @@ func (r *certReloader) GetCertificate() (*tls.Certificate, error) {
 		r.lock.RUnlock()
 		r.lock.Lock()
 		defer r.lock.Unlock()
+		r.nextReload = now.Add(r.ReloadInterval)
 		cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load TLS cert and key: %w", err)
+			reportReloadFailure(r.logger, r.CertFile, fmt.Errorf("failed to load TLS cert and key: %w", err))
+			return r.cert, nil
 		}
 		r.cert = &cert
-		r.nextReload = now.Add(r.ReloadInterval)
 		return r.cert, nil
 	}
 	defer r.lock.RUnlock()
 	return r.cert, nil
 }
 
+// fileReloader is a wrapper object for the reloading of a file.
+// Its get method will either return the current value loaded from the file, or load it again if the
+// file changed and the last check happened more than reloadInterval ago. When the reload fails,
+// the failure is reported and the last value is kept. If reloadInterval is not set, the file is
+// never loaded again.
+type fileReloader struct {
+	file           string
+	reloadInterval time.Duration
+	load           func(file string) (interface{}, error)
+	logger         *zap.Logger
+
+	lock       sync.RWMutex
+	nextReload time.Time
+	value      interface{}
+	// modTime and size identify the version of the file the value was loaded from.
+	modTime time.Time
+	size    int64
+}
+
+func newFileReloader(file string, reloadInterval time.Duration, value interface{}, load func(string) (interface{}, error), logger *zap.Logger) *fileReloader {
+	r := &fileReloader{
+		file:           file,
+		reloadInterval: reloadInterval,
+		load:           load,
+		logger:         logger,
+		nextReload:     time.Now().Add(reloadInterval),
+		value:          value,
+	}
+	if info, err := os.Stat(file); err == nil {
+		r.modTime = info.ModTime()
+		r.size = info.Size()
+	}
+	return r
+}
+
+func (r *fileReloader) get() interface{} {
+	now := time.Now()
+	r.lock.RLock()
+	value, reload := r.value, r.reloadInterval != 0 && r.nextReload.Before(now)
+	r.lock.RUnlock()
+	if !reload {
+		return value
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	// Another handshake may have reloaded the file while waiting for the lock.
+	if !r.nextReload.Before(now) {
+		return r.value
+	}
+	r.nextReload = now.Add(r.reloadInterval)
+
+	info, err := os.Stat(r.file)
+	if err == nil && info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return r.value
+	}
+	if err == nil {
+		value, err = r.load(r.file)
+	}
+	if err != nil {
+		reportReloadFailure(r.logger, r.file, err)
+		return r.value
+	}
+	r.value = value
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	r.logger.Info("Reloaded TLS file", zap.String("file", r.file))
+	return r.value
+}
+
+// caReloader reloads a CA file, see fileReloader.
+type caReloader struct {
+	*fileReloader
+}
+
+func newCAReloader(caFile string, reloadInterval time.Duration, pool *x509.CertPool, logger *zap.Logger) *caReloader {
+	load := func(file string) (interface{}, error) { return loadCertPool(file) }
+	return &caReloader{fileReloader: newFileReloader(caFile, reloadInterval, pool, load, logger)}
+}
+
+func (r *caReloader) certPool() *x509.CertPool {
+	return r.get().(*x509.CertPool)
+}
+
+// verifyServerConnection returns the function verifying the certificate chain and host name of the servers with
+// the current CA pool, the way the tls package does when InsecureSkipVerify is not set. The host name is the server
+// name sent to the server, or serverName for IP addresses, which are not sent.
+func (r *caReloader) verifyServerConnection(serverName string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("tls: server did not present a certificate")
+		}
+		name := cs.ServerName
+		if name == "" {
+			name = serverName
+		}
+		if name == "" {
+			return errors.New("tls: cannot verify the host name of the server, server_name_override must be set")
+		}
+		opts := x509.VerifyOptions{
+			Roots:         r.certPool(),
+			DNSName:       name,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
+
+// endpointHost returns the host of an endpoint given as a URL, a gRPC target or a host and port.
+func endpointHost(endpoint string) string {
+	host := endpoint
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return ""
+		}
+		host = u.Host
+		if u.Scheme != "http" && u.Scheme != "https" {
+			// The gRPC targets are scheme://authority/endpoint.
+			host = strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// reportReloadFailure logs the failure to reload the file, and counts it in the tls/reload_failures metric.
+func reportReloadFailure(logger *zap.Logger, file string, err error) {
+	logger.Warn("Failed to reload TLS file, keeping the last loaded version", zap.String("file", file), zap.Error(err))
+	_ = stats.RecordWithTags(context.Background(),
+		[]tag.Mutator{tag.Upsert(obsmetrics.TagKeyFile, file)},
+		obsmetrics.TLSReloadFailures.M(1))
+}
+
 // LoadTLSConfig loads TLS certificates and returns a tls.Config.
 // This will set the RootCAs and Certificates of a tls.Config.
-func (c TLSSetting) loadTLSConfig() (*tls.Config, error) {
+func (c TLSSetting) loadTLSConfig(opts ...LoadOption) (*tls.Config, error) {
+	o := newLoadOptions(opts)
 	// There is no need to load the System Certs for RootCAs because
 	// if the value is nil, it will default to checking against th System Certs.
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
 	var err error
 	var certPool *x509.CertPool
-	if len(c.CAFile) != 0 {
+	switch {
+	case c.CAFile != "":
 		// Set up user specified truststore.
-		certPool, err = c.loadCert(c.CAFile)
+		certPool, err = loadCertPool(c.CAFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load CA CertPool: %w", err)
 		}
+	case c.CAPem != "":
+		certPool, err = newCertPool([]byte(c.CAPem))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CA CertPool: failed to parse ca_pem: %w", err)
+		}
 	}
 
-	if (c.CertFile == "" && c.KeyFile != "") || (c.CertFile != "" && c.KeyFile == "") {
+	hasCert := c.CertFile != "" || c.CertPem != ""
+	hasKey := c.KeyFile != "" || c.KeyPem != ""
+	if hasCert != hasKey {
 		return nil, fmt.Errorf("for auth via TLS, either both certificate and key must be supplied, or neither")
 	}
+	if (c.CertFile != "") != (c.KeyFile != "") {
+		return nil, fmt.Errorf("for auth via TLS, the certificate and the key must be both supplied as files or both as PEM")
+	}
 
 	var getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 	var getClientCertificate func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
-	if c.CertFile != "" && c.KeyFile != "" {
+	switch {
+	case c.CertFile != "":
 		var certReloader *certReloader
-		certReloader, err = newCertReloader(c.CertFile, c.KeyFile, c.ReloadInterval)
+		certReloader, err = newCertReloader(c.CertFile, c.KeyFile, c.ReloadInterval, o.logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load TLS cert and key: %w", err)
 		}
 		getCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) { return certReloader.GetCertificate() }
 		getClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) { return certReloader.GetCertificate() }
+	case c.CertPem != "":
+		var cert tls.Certificate
+		cert, err = tls.X509KeyPair([]byte(c.CertPem), []byte(c.KeyPem))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS cert and key PEM: %w", err)
+		}
+		getCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) { return &cert, nil }
+		getClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) { return &cert, nil }
 	}
 
 	minTLS, err := convertVersion(c.MinVersion)
@@ -183,6 +475,14 @@ func (c TLSSetting) loadTLSConfig() (*tls.Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid TLS max_version: %w", err)
 	}
+	cipherSuites, err := convertCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS cipher_suites: %w", err)
+	}
+	curves, err := convertCurves(c.CurvePreferences)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS curve_preferences: %w", err)
+	}
 
 	return &tls.Config{
 		RootCAs:              certPool,
@@ -190,50 +490,104 @@ func (c TLSSetting) loadTLSConfig() (*tls.Config, error) {
 		GetClientCertificate: getClientCertificate,
 		MinVersion:           minTLS,
 		MaxVersion:           maxTLS,
+		CipherSuites:         cipherSuites,
+		CurvePreferences:     curves,
 	}, nil
 }
 
-func (c TLSSetting) loadCert(caPath string) (*x509.CertPool, error) {
+func loadCertPool(caPath string) (*x509.CertPool, error) {
 	caPEM, err := ioutil.ReadFile(filepath.Clean(caPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load CA %s: %w", caPath, err)
 	}
 
+	certPool, err := newCertPool(caPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA %s: %w", caPath, err)
+	}
+	return certPool, nil
+}
+
+func newCertPool(caPEM []byte) (*x509.CertPool, error) {
 	certPool := x509.NewCertPool()
 	if !certPool.AppendCertsFromPEM(caPEM) {
-		return nil, fmt.Errorf("failed to parse CA %s", caPath)
+		return nil, errors.New("no valid PEM encoded certificate found")
 	}
 	return certPool, nil
 }
 
 // LoadTLSConfig loads the TLS configuration.
-func (c TLSClientSetting) LoadTLSConfig() (*tls.Config, error) {
-	if c.Insecure && c.CAFile == "" {
+func (c TLSClientSetting) LoadTLSConfig(opts ...LoadOption) (*tls.Config, error) {
+	if c.Insecure && c.CAFile == "" && c.CAPem == "" {
 		return nil, nil
 	}
 
-	tlsCfg, err := c.TLSSetting.loadTLSConfig()
+	tlsCfg, err := c.TLSSetting.loadTLSConfig(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load TLS config: %w", err)
 	}
 	tlsCfg.ServerName = c.ServerName
 	tlsCfg.InsecureSkipVerify = c.InsecureSkipVerify
+	if c.CAFile != "" && c.ReloadInterval > 0 && !c.InsecureSkipVerify {
+		// The RootCAs cannot be replaced for the next connections, the server certificates are verified
+		// with the reloaded CA instead.
+		o := newLoadOptions(opts)
+		serverName := c.ServerName
+		if serverName == "" {
+			serverName = endpointHost(o.endpoint)
+		}
+		reloader := newCAReloader(c.CAFile, c.ReloadInterval, tlsCfg.RootCAs, o.logger)
+		tlsCfg.InsecureSkipVerify = true
+		tlsCfg.VerifyConnection = reloader.verifyServerConnection(serverName)
+	}
 	return tlsCfg, nil
 }
 
 // LoadTLSConfig loads the TLS configuration.
-func (c TLSServerSetting) LoadTLSConfig() (*tls.Config, error) {
-	tlsCfg, err := c.loadTLSConfig()
+func (c TLSServerSetting) LoadTLSConfig(opts ...LoadOption) (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to load TLS config: %w", err)
+	}
+	tlsCfg, err := c.loadTLSConfig(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load TLS config: %w", err)
 	}
+	if c.checksRevocation() {
+		checker := &revocationChecker{ocspStapling: c.OCSPStapling, now: time.Now}
+		if c.CRLFile != "" {
+			lists, err := loadRevocationLists(c.CRLFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load TLS config: %w", err)
+			}
+			load := func(file string) (interface{}, error) { return loadRevocationLists(file) }
+			checker.crls = newFileReloader(c.CRLFile, c.ReloadInterval, lists, load, newLoadOptions(opts).logger)
+		}
+		tlsCfg.VerifyConnection = checker.verifyClientConnection
+	}
+	if c.ClientCAPem != "" {
+		tlsCfg.ClientCAs, err = newCertPool([]byte(c.ClientCAPem))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS config: failed to parse client_ca_pem: %w", err)
+		}
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
 	if c.ClientCAFile != "" {
-		certPool, err := c.loadCert(c.ClientCAFile)
+		certPool, err := loadCertPool(c.ClientCAFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load TLS config: failed to load client CA CertPool: %w", err)
 		}
 		tlsCfg.ClientCAs = certPool
 		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		if c.ReloadInterval > 0 {
+			// Each handshake uses a copy of the configuration with the current client CA.
+			reloader := newCAReloader(c.ClientCAFile, c.ReloadInterval, certPool, newLoadOptions(opts).logger)
+			baseCfg := tlsCfg.Clone()
+			tlsCfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				cfg := baseCfg.Clone()
+				cfg.ClientCAs = reloader.certPool()
+				return cfg, nil
+			}
+		}
 	}
 	return tlsCfg, nil
 }
@@ -255,3 +609,49 @@ var tlsVersions = map[string]uint16{
 	"1.2": tls.VersionTLS12,
 	"1.3": tls.VersionTLS13,
 }
+
+func convertCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	suites := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := cipherSuites[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cipher suite: %q", name)
+		}
+		suites = append(suites, id)
+	}
+	return suites, nil
+}
+
+// cipherSuites are the secure cipher suites implemented by the tls package, by name.
+var cipherSuites = func() map[string]uint16 {
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	return suites
+}()
+
+func convertCurves(names []string) ([]tls.CurveID, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	curves := make([]tls.CurveID, 0, len(names))
+	for _, name := range names {
+		id, ok := tlsCurves[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve: %q", name)
+		}
+		curves = append(curves, id)
+	}
+	return curves, nil
+}
+
+var tlsCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
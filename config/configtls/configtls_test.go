@@ -15,9 +15,16 @@
 package configtls
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"io"
+	"math/big"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -25,6 +32,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestOptionsToConfig(t *testing.T) {
@@ -233,7 +242,6 @@ func TestCertificateReload(t *testing.T) {
 		key2           string
 		dns1           string
 		dns2           string
-		errText        string
 	}{
 		{
 			name:           "Should reload the certificate after reload-interval",
@@ -263,13 +271,13 @@ func TestCertificateReload(t *testing.T) {
 			dns2:           "example1",
 		},
 		{
-			name:           "Should return an error if reloading fails",
+			name:           "Should keep the last certificate if reloading fails",
 			reloadInterval: 100 * time.Microsecond,
 			wait:           100 * time.Microsecond,
 			cert2:          "testCA-bad.txt",
 			key2:           "client-2.key",
 			dns1:           "example1",
-			errText:        "failed to load TLS cert and key: tls: failed to find any PEM data in certificate input",
+			dns2:           "example1",
 		},
 	}
 
@@ -338,16 +346,429 @@ func TestCertificateReload(t *testing.T) {
 
 			// Assert that we loaded the new certificate
 			cert, err = cfg.GetCertificate(&tls.ClientHelloInfo{})
+			assert.NoError(t, err)
+			assert.NotNil(t, cert)
+			pCert, err = x509.ParseCertificate(cert.Certificate[0])
+			assert.NoError(t, err)
+			assert.NotNil(t, pCert)
+			assert.Equal(t, test.dns2, pCert.DNSNames[0])
+		})
+	}
+}
+
+// testCA is a CA generated for the tests, that can sign leaf certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) sign(t *testing.T, name string, usage x509.ExtKeyUsage) *x509.Certificate {
+	cert, _ := ca.issue(t, name, usage)
+	return cert
+}
+
+// issuePEM returns a PEM encoded certificate signed by the CA and its PEM encoded key.
+func (ca *testCA) issuePEM(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	cert, key := ca.issue(t, name, usage)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		tmpl.IPAddresses = []net.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{name}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func verifyClientCert(pool *x509.CertPool, cert *x509.Certificate) error {
+	_, err := cert.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	return err
+}
+
+func TestServerClientCAReload(t *testing.T) {
+	ca1 := newTestCA(t, "ca1")
+	ca2 := newTestCA(t, "ca2")
+	clientCert := ca2.sign(t, "client", x509.ExtKeyUsageClientAuth)
+
+	caFile := filepath.Join(t.TempDir(), "client-ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca1.pem, 0600))
+	setting := TLSServerSetting{
+		TLSSetting:   TLSSetting{ReloadInterval: time.Millisecond},
+		ClientCAFile: caFile,
+	}
+	cfg, err := setting.LoadTLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, cfg.GetConfigForClient)
+
+	clientCfg, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, clientCfg.ClientAuth)
+	assert.Error(t, verifyClientCert(clientCfg.ClientCAs, clientCert))
+
+	// The rotated client CA is used by the next handshakes.
+	require.NoError(t, os.WriteFile(caFile, append(ca1.pem, ca2.pem...), 0600))
+	assert.Eventually(t, func() bool {
+		clientCfg, err = cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+		return err == nil && verifyClientCert(clientCfg.ClientCAs, clientCert) == nil
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestServerClientCANotReloaded(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "client-ca.pem")
+	require.NoError(t, os.WriteFile(caFile, newTestCA(t, "ca").pem, 0600))
+	cfg, err := TLSServerSetting{ClientCAFile: caFile}.LoadTLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, cfg.GetConfigForClient)
+	assert.NotNil(t, cfg.ClientCAs)
+}
+
+func TestClientCAReload(t *testing.T) {
+	ca1 := newTestCA(t, "ca1")
+	ca2 := newTestCA(t, "ca2")
+	state := tls.ConnectionState{
+		ServerName:       "server",
+		PeerCertificates: []*x509.Certificate{ca2.sign(t, "server", x509.ExtKeyUsageServerAuth)},
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca1.pem, 0600))
+	setting := TLSClientSetting{
+		TLSSetting: TLSSetting{CAFile: caFile, ReloadInterval: time.Millisecond},
+	}
+	cfg, err := setting.LoadTLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, cfg.VerifyConnection)
+	assert.True(t, cfg.InsecureSkipVerify)
+	assert.Error(t, cfg.VerifyConnection(state))
+
+	// The rotated CA is used to verify the next servers.
+	require.NoError(t, os.WriteFile(caFile, append(ca1.pem, ca2.pem...), 0600))
+	assert.Eventually(t, func() bool {
+		return cfg.VerifyConnection(state) == nil
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// The host name is still verified.
+	state.ServerName = "other"
+	assert.Error(t, cfg.VerifyConnection(state))
+	assert.Error(t, cfg.VerifyConnection(tls.ConnectionState{ServerName: "server"}))
+}
+
+// handshakeWith dials the TLS server presenting the certificate at an IP endpoint with the client configuration.
+func handshakeWith(t *testing.T, serverCert *x509.Certificate, serverKey *ecdsa.PrivateKey, clientCfg *tls.Config) error {
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+	})
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		_ = conn.(*tls.Conn).Handshake()
+		_ = conn.Close()
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), clientCfg)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func TestClientCAReloadVerifiesIPEndpoint(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0600))
+	setting := TLSClientSetting{
+		TLSSetting: TLSSetting{CAFile: caFile, ReloadInterval: time.Millisecond},
+	}
+
+	// No server name is sent to IP addresses, the host of the endpoint is verified instead.
+	cfg, err := setting.LoadTLSConfig(WithEndpoint("https://127.0.0.1:4318/v1/traces"))
+	require.NoError(t, err)
+	cert, key := ca.issue(t, "other", x509.ExtKeyUsageServerAuth)
+	assert.Error(t, handshakeWith(t, cert, key, cfg))
+	cert, key = ca.issue(t, "127.0.0.1", x509.ExtKeyUsageServerAuth)
+	assert.NoError(t, handshakeWith(t, cert, key, cfg))
+
+	// The server name overrides the endpoint.
+	setting.ServerName = "other"
+	cfg, err = setting.LoadTLSConfig(WithEndpoint("127.0.0.1:4317"))
+	require.NoError(t, err)
+	cert, key = ca.issue(t, "127.0.0.1", x509.ExtKeyUsageServerAuth)
+	assert.Error(t, handshakeWith(t, cert, key, cfg))
+	cert, key = ca.issue(t, "other", x509.ExtKeyUsageServerAuth)
+	assert.NoError(t, handshakeWith(t, cert, key, cfg))
+
+	// The server cannot be verified without a host name.
+	setting.ServerName = ""
+	cfg, err = setting.LoadTLSConfig()
+	require.NoError(t, err)
+	cert, key = ca.issue(t, "127.0.0.1", x509.ExtKeyUsageServerAuth)
+	assert.Error(t, handshakeWith(t, cert, key, cfg))
+}
+
+func TestEndpointHost(t *testing.T) {
+	for endpoint, host := range map[string]string{
+		"":                                "",
+		"localhost:4317":                  "localhost",
+		"127.0.0.1:4317":                  "127.0.0.1",
+		"[::1]:4317":                      "::1",
+		"https://10.0.0.1:4318/v1/traces": "10.0.0.1",
+		"http://[::1]/v1/logs":            "::1",
+		"dns:///collector:4317":           "collector",
+		"dns://8.8.8.8/10.0.0.2:4317":     "10.0.0.2",
+	} {
+		assert.Equal(t, host, endpointHost(endpoint), endpoint)
+	}
+}
+
+func TestClientCANotReloadedWithInsecureSkipVerify(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, newTestCA(t, "ca").pem, 0600))
+	setting := TLSClientSetting{
+		TLSSetting:         TLSSetting{CAFile: caFile, ReloadInterval: time.Millisecond},
+		InsecureSkipVerify: true,
+	}
+	cfg, err := setting.LoadTLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, cfg.VerifyConnection)
+}
+
+func TestCAReloadFailure(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0600))
+	pool, err := loadCertPool(caFile)
+	require.NoError(t, err)
+
+	core, logs := observer.New(zap.WarnLevel)
+	reloader := newCAReloader(caFile, time.Millisecond, pool, zap.New(core))
+	assert.Same(t, pool, reloader.certPool())
+
+	// The last CA pool is kept while the file cannot be loaded.
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0600))
+	time.Sleep(5 * time.Millisecond)
+	assert.Same(t, pool, reloader.certPool())
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, caFile, logs.All()[0].ContextMap()["file"])
+
+	// The failure is reported at each check until the file is fixed.
+	time.Sleep(5 * time.Millisecond)
+	assert.Same(t, pool, reloader.certPool())
+	assert.Equal(t, 2, logs.Len())
+
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0600))
+	time.Sleep(5 * time.Millisecond)
+	assert.NotSame(t, pool, reloader.certPool())
+	assert.Equal(t, 2, logs.Len())
+}
+
+func TestTLSSettingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting TLSSetting
+		errText string
+	}{
+		{
+			name: "valid",
+			setting: TLSSetting{
+				MinVersion:       "1.2",
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				CurvePreferences: []string{"X25519", "P256"},
+			},
+		},
+		{
+			name:    "ca_file_and_ca_pem",
+			setting: TLSSetting{CAFile: "ca.pem", CAPem: "pem"},
+			errText: "ca_file and ca_pem cannot be both provided",
+		},
+		{
+			name:    "cert_file_and_cert_pem",
+			setting: TLSSetting{CertFile: "cert.pem", CertPem: "pem"},
+			errText: "cert_file and cert_pem cannot be both provided",
+		},
+		{
+			name:    "key_file_and_key_pem",
+			setting: TLSSetting{KeyFile: "key.pem", KeyPem: "pem"},
+			errText: "key_file and key_pem cannot be both provided",
+		},
+		{
+			name:    "unknown_version",
+			setting: TLSSetting{MaxVersion: "1.4"},
+			errText: `invalid TLS max_version: unsupported TLS version: "1.4"`,
+		},
+		{
+			name:    "unknown_cipher_suite",
+			setting: TLSSetting{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_UNKNOWN"}},
+			errText: `invalid TLS cipher_suites: unsupported cipher suite: "TLS_UNKNOWN"`,
+		},
+		{
+			name:    "insecure_cipher_suite",
+			setting: TLSSetting{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			errText: `invalid TLS cipher_suites: unsupported cipher suite: "TLS_RSA_WITH_RC4_128_SHA"`,
+		},
+		{
+			name:    "unknown_curve",
+			setting: TLSSetting{CurvePreferences: []string{"P224"}},
+			errText: `invalid TLS curve_preferences: unsupported curve: "P224"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.setting.Validate()
 			if test.errText == "" {
 				assert.NoError(t, err)
-				assert.NotNil(t, cert)
-				pCert, err = x509.ParseCertificate(cert.Certificate[0])
-				assert.NoError(t, err)
-				assert.NotNil(t, pCert)
-				assert.Equal(t, test.dns2, pCert.DNSNames[0])
-			} else {
-				assert.EqualError(t, err, test.errText)
+				return
+			}
+			assert.EqualError(t, err, test.errText)
+			_, err = test.setting.loadTLSConfig()
+			assert.EqualError(t, err, test.errText)
+		})
+	}
+
+	err := TLSServerSetting{ClientCAFile: "ca.pem", ClientCAPem: "pem"}.Validate()
+	assert.EqualError(t, err, "client_ca_file and client_ca_pem cannot be both provided")
+}
+
+func TestCipherSuitesAndCurves(t *testing.T) {
+	setting := TLSSetting{
+		CipherSuites:     []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"},
+		CurvePreferences: []string{"P384", "X25519"},
+	}
+	cfg, err := setting.loadTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}, cfg.CipherSuites)
+	assert.Equal(t, []tls.CurveID{tls.CurveP384, tls.X25519}, cfg.CurvePreferences)
+
+	cfg, err = TLSSetting{}.loadTLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, cfg.CipherSuites)
+	assert.Nil(t, cfg.CurvePreferences)
+}
+
+func TestLoadTLSConfigFromPEM(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	serverCert, serverKey := ca.issuePEM(t, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issuePEM(t, "client", x509.ExtKeyUsageClientAuth)
+
+	serverCfg, err := TLSServerSetting{
+		TLSSetting:  TLSSetting{CertPem: string(serverCert), KeyPem: string(serverKey)},
+		ClientCAPem: string(ca.pem),
+	}.LoadTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, serverCfg.ClientAuth)
+
+	clientCfg, err := TLSClientSetting{
+		TLSSetting: TLSSetting{CAPem: string(ca.pem), CertPem: string(clientCert), KeyPem: string(clientKey)},
+		ServerName: "server",
+	}.LoadTLSConfig()
+	require.NoError(t, err)
+
+	// The client and the server verify each other with the in memory CAs.
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tls.Server(serverConn, serverCfg).Handshake()
+	}()
+	require.NoError(t, tls.Client(clientConn, clientCfg).Handshake())
+	require.NoError(t, <-errs)
+}
+
+func TestLoadTLSConfigFromPEMError(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	cert, key := ca.issuePEM(t, "server", x509.ExtKeyUsageServerAuth)
+
+	tests := []struct {
+		name    string
+		setting TLSServerSetting
+		errText string
+	}{
+		{
+			name:    "invalid_ca_pem",
+			setting: TLSServerSetting{TLSSetting: TLSSetting{CAPem: "invalid"}},
+			errText: "failed to load TLS config: failed to load CA CertPool: failed to parse ca_pem: no valid PEM encoded certificate found",
+		},
+		{
+			name:    "invalid_client_ca_pem",
+			setting: TLSServerSetting{ClientCAPem: "invalid"},
+			errText: "failed to load TLS config: failed to parse client_ca_pem: no valid PEM encoded certificate found",
+		},
+		{
+			name:    "cert_pem_without_key",
+			setting: TLSServerSetting{TLSSetting: TLSSetting{CertPem: string(cert)}},
+			errText: "failed to load TLS config: for auth via TLS, either both certificate and key must be supplied, or neither",
+		},
+		{
+			name:    "cert_pem_with_key_file",
+			setting: TLSServerSetting{TLSSetting: TLSSetting{CertPem: string(cert), KeyFile: "key.pem"}},
+			errText: "failed to load TLS config: for auth via TLS, the certificate and the key must be both supplied as files or both as PEM",
+		},
+		{
+			// The error message of the tls package is not checked.
+			name:    "mismatched_key_pem",
+			setting: TLSServerSetting{TLSSetting: TLSSetting{CertPem: string(cert), KeyPem: string(ca.pem)}},
+		},
+		{
+			name:    "both_client_ca",
+			setting: TLSServerSetting{ClientCAFile: "ca.pem", ClientCAPem: string(ca.pem)},
+			errText: "failed to load TLS config: client_ca_file and client_ca_pem cannot be both provided",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.setting.LoadTLSConfig()
+			if test.errText == "" {
+				assert.Error(t, err)
+				return
 			}
+			assert.EqualError(t, err, test.errText)
 		})
 	}
+	_, err := TLSServerSetting{TLSSetting: TLSSetting{CertPem: string(cert), KeyPem: string(key)}}.LoadTLSConfig()
+	assert.NoError(t, err)
 }
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configtls // import "go.opentelemetry.io/collector/config/configtls"
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// The values of TLSServerSetting.OCSPStapling.
+const (
+	ocspStaplingNone     = "none"
+	ocspStaplingOptional = "optional"
+	ocspStaplingRequired = "required"
+)
+
+var errNoOCSPResponse = errors.New("client certificate has no stapled OCSP response")
+
+// revocationList is a certificate revocation list, with the serial numbers of the revoked certificates indexed.
+type revocationList struct {
+	crl     *pkix.CertificateList
+	revoked map[string]struct{}
+}
+
+// loadRevocationLists loads the CRLs of a file, either PEM encoded or a single DER encoded CRL.
+func loadRevocationLists(file string) ([]*revocationList, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CRL %s: %w", file, err)
+	}
+
+	var ders [][]byte
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "X509 CRL" {
+			ders = append(ders, block.Bytes)
+		}
+	}
+	if len(ders) == 0 {
+		ders = [][]byte{data}
+	}
+
+	lists := make([]*revocationList, 0, len(ders))
+	for _, der := range ders {
+		crl, err := x509.ParseDERCRL(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRL %s: %w", file, err)
+		}
+		list := &revocationList{crl: crl, revoked: make(map[string]struct{}, len(crl.TBSCertList.RevokedCertificates))}
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			list.revoked[revoked.SerialNumber.String()] = struct{}{}
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+// revocationChecker checks the client certificates verified by the servers are not revoked.
+type revocationChecker struct {
+	// crls reloads the CRL file, it is nil when no CRL file is configured.
+	crls         *fileReloader
+	ocspStapling string
+	now          func() time.Time
+}
+
+// verifyClientConnection checks the certificates of the first verified chain of the client against the CRLs,
+// and the leaf certificate against the OCSP response stapled by the client.
+func (c *revocationChecker) verifyClientConnection(cs tls.ConnectionState) error {
+	if len(cs.VerifiedChains) == 0 {
+		// Clients without certificate are rejected by the client authentication.
+		return nil
+	}
+	chain := cs.VerifiedChains[0]
+
+	if c.crls != nil {
+		lists := c.crls.get().([]*revocationList)
+		for i := 0; i < len(chain)-1; i++ {
+			cert, issuer := chain[i], chain[i+1]
+			for _, list := range lists {
+				if issuer.CheckCRLSignature(list.crl) != nil {
+					// The list was issued by another CA.
+					continue
+				}
+				// An expired list may not list the latest revocations, the clients of its issuer are rejected until
+				// the list is refreshed.
+				if list.crl.HasExpired(c.now()) {
+					return fmt.Errorf("CRL of the issuer %q of the client certificate %q expired", issuer.Subject, cert.Subject)
+				}
+				if _, revoked := list.revoked[cert.SerialNumber.String()]; revoked {
+					return fmt.Errorf("client certificate %q is revoked", cert.Subject)
+				}
+			}
+		}
+	}
+
+	switch c.ocspStapling {
+	case ocspStaplingOptional:
+		if len(cs.OCSPResponse) == 0 {
+			return nil
+		}
+	case ocspStaplingRequired:
+		if len(cs.OCSPResponse) == 0 {
+			return errNoOCSPResponse
+		}
+	default:
+		return nil
+	}
+	if len(chain) < 2 {
+		return fmt.Errorf("cannot check the stapled OCSP response of the client certificate %q without its issuer", chain[0].Subject)
+	}
+	resp, err := ocsp.ParseResponseForCert(cs.OCSPResponse, chain[0], chain[1])
+	if err != nil {
+		return fmt.Errorf("invalid stapled OCSP response: %w", err)
+	}
+	switch resp.Status {
+	case ocsp.Good:
+	case ocsp.Revoked:
+		return fmt.Errorf("client certificate %q is revoked", chain[0].Subject)
+	default:
+		return fmt.Errorf("client certificate %q has an unknown OCSP status", chain[0].Subject)
+	}
+	if !resp.NextUpdate.IsZero() && c.now().After(resp.NextUpdate) {
+		return fmt.Errorf("stapled OCSP response of the client certificate %q expired", chain[0].Subject)
+	}
+	return nil
+}
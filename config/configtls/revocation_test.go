@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configtls
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+// testClient is a client certificate signed by a testCA.
+type testClient struct {
+	cert    *x509.Certificate
+	keyPair tls.Certificate
+}
+
+func (ca *testCA) issueClient(t *testing.T, name string) *testClient {
+	certPEM, keyPEM := ca.issuePEM(t, name, x509.ExtKeyUsageClientAuth)
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	require.NoError(t, err)
+	return &testClient{cert: cert, keyPair: keyPair}
+}
+
+// crlPEM returns a PEM encoded CRL of the CA revoking the certificates.
+func (ca *testCA) crlPEM(t *testing.T, revoked ...*x509.Certificate) []byte {
+	return ca.crlPEMUntil(t, time.Now().Add(time.Hour), revoked...)
+}
+
+// crlPEMUntil returns a PEM encoded CRL of the CA revoking the certificates, with the next update.
+func (ca *testCA) crlPEMUntil(t *testing.T, nextUpdate time.Time, revoked ...*x509.Certificate) []byte {
+	var entries []pkix.RevokedCertificate
+	for _, cert := range revoked {
+		entries = append(entries, pkix.RevokedCertificate{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(time.Now().UnixNano()),
+		ThisUpdate:          nextUpdate.Add(-2 * time.Hour),
+		NextUpdate:          nextUpdate,
+		RevokedCertificates: entries,
+	}, ca.cert, ca.key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+// ocspResponse returns an OCSP response of the CA about the certificate.
+func (ca *testCA) ocspResponse(t *testing.T, cert *x509.Certificate, status int, nextUpdate time.Time) []byte {
+	resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+		Status:       status,
+		SerialNumber: cert.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Hour),
+		NextUpdate:   nextUpdate,
+		RevokedAt:    time.Now().Add(-time.Minute),
+	}, ca.key)
+	require.NoError(t, err)
+	return resp
+}
+
+func newTestServerSetting(t *testing.T, ca *testCA) TLSServerSetting {
+	certPEM, keyPEM := ca.issuePEM(t, "server", x509.ExtKeyUsageServerAuth)
+	return TLSServerSetting{
+		TLSSetting:  TLSSetting{CertPem: string(certPEM), KeyPem: string(keyPEM), MaxVersion: "1.3"},
+		ClientCAPem: string(ca.pem),
+	}
+}
+
+// handshake connects a TLS 1.3 client with the certificate and the stapled OCSP response to the server,
+// and returns the error of the server.
+func handshake(t *testing.T, serverCfg *tls.Config, ca *testCA, client *testClient, ocspStaple []byte) error {
+	keyPair := client.keyPair
+	keyPair.OCSPStaple = ocspStaple
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCfg := &tls.Config{
+		Certificates: []tls.Certificate{keyPair},
+		RootCAs:      roots,
+		ServerName:   "server",
+		MinVersion:   tls.VersionTLS13,
+	}
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tls.Server(serverConn, serverCfg).Handshake()
+		assert.NoError(t, serverConn.Close())
+	}()
+	conn := tls.Client(clientConn, clientCfg)
+	if conn.Handshake() == nil {
+		// Wait for the server to verify the client certificate, which happens after the client handshake in TLS 1.3.
+		_, _ = conn.Read(make([]byte, 1))
+	}
+	return <-errs
+}
+
+func TestCRL(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other")
+	client1 := ca.issueClient(t, "client1")
+	client2 := ca.issueClient(t, "client2")
+
+	// The CRLs of other CAs are ignored, even when they list a certificate with the same serial number.
+	crlFile := filepath.Join(t.TempDir(), "crl.pem")
+	require.NoError(t, os.WriteFile(crlFile, append(ca.crlPEM(t, client1.cert), otherCA.crlPEM(t, client2.cert)...), 0600))
+	setting := newTestServerSetting(t, ca)
+	setting.CRLFile = crlFile
+	setting.ReloadInterval = time.Millisecond
+	serverCfg, err := setting.LoadTLSConfig()
+	require.NoError(t, err)
+
+	assert.EqualError(t, handshake(t, serverCfg, ca, client1, nil), `client certificate "CN=client1" is revoked`)
+	assert.NoError(t, handshake(t, serverCfg, ca, client2, nil))
+
+	// The rotated CRL is used by the next handshakes.
+	require.NoError(t, os.WriteFile(crlFile, ca.crlPEM(t, client1.cert, client2.cert), 0600))
+	assert.Eventually(t, func() bool {
+		return handshake(t, serverCfg, ca, client2, nil) != nil
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// The last CRL is kept while the file cannot be loaded.
+	require.NoError(t, os.WriteFile(crlFile, []byte("invalid"), 0600))
+	time.Sleep(5 * time.Millisecond)
+	assert.EqualError(t, handshake(t, serverCfg, ca, client2, nil), `client certificate "CN=client2" is revoked`)
+}
+
+func TestCRLDER(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	client := ca.issueClient(t, "client")
+
+	block, _ := pem.Decode(ca.crlPEM(t, client.cert))
+	crlFile := filepath.Join(t.TempDir(), "crl.der")
+	require.NoError(t, os.WriteFile(crlFile, block.Bytes, 0600))
+	setting := newTestServerSetting(t, ca)
+	setting.CRLFile = crlFile
+	serverCfg, err := setting.LoadTLSConfig()
+	require.NoError(t, err)
+
+	assert.EqualError(t, handshake(t, serverCfg, ca, client, nil), `client certificate "CN=client" is revoked`)
+}
+
+func TestCRLExpired(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other")
+	client := ca.issueClient(t, "client")
+
+	// The expired CRLs of other CAs are ignored.
+	crlFile := filepath.Join(t.TempDir(), "crl.pem")
+	require.NoError(t, os.WriteFile(crlFile, append(ca.crlPEM(t), otherCA.crlPEMUntil(t, time.Now().Add(-time.Minute))...), 0600))
+	setting := newTestServerSetting(t, ca)
+	setting.CRLFile = crlFile
+	setting.ReloadInterval = time.Millisecond
+	serverCfg, err := setting.LoadTLSConfig()
+	require.NoError(t, err)
+	assert.NoError(t, handshake(t, serverCfg, ca, client, nil))
+
+	// The clients are rejected while the CRL of their issuer is expired.
+	require.NoError(t, os.WriteFile(crlFile, ca.crlPEMUntil(t, time.Now().Add(-time.Minute)), 0600))
+	assert.Eventually(t, func() bool {
+		return handshake(t, serverCfg, ca, client, nil) != nil
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.EqualError(t, handshake(t, serverCfg, ca, client, nil), `CRL of the issuer "CN=ca" of the client certificate "CN=client" expired`)
+}
+
+func TestOCSPStapling(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other")
+	client := ca.issueClient(t, "client")
+	good := ca.ocspResponse(t, client.cert, ocsp.Good, time.Now().Add(time.Hour))
+	revoked := ca.ocspResponse(t, client.cert, ocsp.Revoked, time.Now().Add(time.Hour))
+	unknown := ca.ocspResponse(t, client.cert, ocsp.Unknown, time.Now().Add(time.Hour))
+	expired := ca.ocspResponse(t, client.cert, ocsp.Good, time.Now().Add(-time.Minute))
+	forged := otherCA.ocspResponse(t, client.cert, ocsp.Good, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name         string
+		ocspStapling string
+		staple       []byte
+		errText      string
+	}{
+		{name: "none_without_response", ocspStapling: "none"},
+		{name: "none_ignores_revoked", ocspStapling: "none", staple: revoked},
+		{name: "optional_without_response", ocspStapling: "optional"},
+		{name: "optional_good", ocspStapling: "optional", staple: good},
+		{name: "optional_revoked", ocspStapling: "optional", staple: revoked, errText: `client certificate "CN=client" is revoked`},
+		{name: "required_without_response", ocspStapling: "required", errText: "client certificate has no stapled OCSP response"},
+		{name: "required_good", ocspStapling: "required", staple: good},
+		{name: "required_revoked", ocspStapling: "required", staple: revoked, errText: `client certificate "CN=client" is revoked`},
+		{name: "required_unknown", ocspStapling: "required", staple: unknown, errText: `client certificate "CN=client" has an unknown OCSP status`},
+		{name: "required_expired", ocspStapling: "required", staple: expired, errText: `stapled OCSP response of the client certificate "CN=client" expired`},
+		{name: "required_forged", ocspStapling: "required", staple: forged, errText: "invalid stapled OCSP response: bad OCSP signature: x509: ECDSA verification failure"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setting := newTestServerSetting(t, ca)
+			setting.OCSPStapling = test.ocspStapling
+			serverCfg, err := setting.LoadTLSConfig()
+			require.NoError(t, err)
+
+			err = handshake(t, serverCfg, ca, client, test.staple)
+			if test.errText == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.errText)
+		})
+	}
+}
+
+func TestRevocationSettingError(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	invalidFile := filepath.Join(t.TempDir(), "crl.pem")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("invalid"), 0600))
+
+	tests := []struct {
+		name    string
+		setting TLSServerSetting
+		errText string
+	}{
+		{
+			name:    "unknown_ocsp_stapling",
+			setting: TLSServerSetting{ClientCAPem: string(ca.pem), OCSPStapling: "always"},
+			errText: `invalid TLS ocsp_stapling: unsupported value: "always"`,
+		},
+		{
+			name:    "crl_file_without_client_ca",
+			setting: TLSServerSetting{CRLFile: invalidFile},
+			errText: "crl_file and ocsp_stapling require client_ca_file or client_ca_pem",
+		},
+		{
+			name:    "ocsp_stapling_without_client_ca",
+			setting: TLSServerSetting{OCSPStapling: "optional"},
+			errText: "crl_file and ocsp_stapling require client_ca_file or client_ca_pem",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, test.setting.Validate(), test.errText)
+			_, err := test.setting.LoadTLSConfig()
+			assert.EqualError(t, err, "failed to load TLS config: "+test.errText)
+		})
+	}
+
+	assert.NoError(t, TLSServerSetting{OCSPStapling: "none"}.Validate())
+
+	setting := newTestServerSetting(t, ca)
+	setting.CRLFile = invalidFile
+	_, err := setting.LoadTLSConfig()
+	assert.ErrorContains(t, err, "failed to load TLS config: failed to parse CRL "+invalidFile)
+}
@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumererror // import "go.opentelemetry.io/collector/consumer/consumererror"
+
+// Partial is an error reporting that only some of the items received were rejected, e.g. by a backend answering
+// with an OTLP partial success. The other items were accepted, so none of them must be sent again.
+type Partial struct {
+	error
+	failed int
+}
+
+// NewPartial creates a Partial error for the given number of items which failed to be processed or sent.
+func NewPartial(err error, failed int) error {
+	return Partial{
+		error:  err,
+		failed: failed,
+	}
+}
+
+// Failed returns the number of items which failed to be processed or sent.
+func (err Partial) Failed() int {
+	return err.failed
+}
+
+// Unwrap returns the wrapped error for functions Is and As in standard package errors.
+func (err Partial) Unwrap() error {
+	return err.error
+}
@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumererror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartial(t *testing.T) {
+	err := fmt.Errorf("some error")
+	partialErr := NewPartial(err, 3)
+	assert.Equal(t, err.Error(), partialErr.Error())
+	var target Partial
+	assert.False(t, errors.As(nil, &target))
+	assert.False(t, errors.As(err, &target))
+	assert.True(t, errors.As(NewPermanent(partialErr), &target))
+	assert.Equal(t, 3, target.Failed())
+	assert.True(t, IsPermanent(NewPermanent(partialErr)))
+}
+
+func TestPartial_Unwrap(t *testing.T) {
+	var err error = testErrorType{"some error"}
+	// Wrapping err with error Partial.
+	partialErr := NewPartial(err, 1)
+	target := testErrorType{}
+	require.NotEqual(t, err, target)
+	// Unwrapping partialErr for err and assigning to target.
+	require.True(t, errors.As(partialErr, &target))
+	require.Equal(t, err, target)
+}
@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+// AdaptiveConcurrencySettings defines configuration for adapting the number of active queue consumers
+// to the observed behavior of the backend, using additive increase and multiplicative decrease (AIMD).
+// The number of active consumers varies between MinConsumers and QueueSettings.NumConsumers.
+type AdaptiveConcurrencySettings struct {
+	// Enabled indicates whether to adapt the number of active consumers instead of always using NumConsumers.
+	Enabled bool `mapstructure:"enabled"`
+	// MinConsumers is the minimum number of active consumers, which is also the number of consumers initially active.
+	MinConsumers int `mapstructure:"min_consumers"`
+	// LatencyThreshold is the export latency above which an attempt is considered a sign of an overloaded backend,
+	// same as an attempt which was throttled or timed out.
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"`
+	// DecreaseRatio is the ratio by which the number of active consumers is multiplied when the backend is overloaded.
+	DecreaseRatio float64 `mapstructure:"decrease_ratio"`
+	// PermanentErrorRateThreshold is the ratio of attempts failing with a permanent error in a window above which
+	// the backend is considered overloaded, e.g. because it rejects data it is not able to process.
+	PermanentErrorRateThreshold float64 `mapstructure:"permanent_error_rate_threshold"`
+}
+
+// NewDefaultAdaptiveConcurrencySettings returns the default settings for AdaptiveConcurrencySettings.
+func NewDefaultAdaptiveConcurrencySettings() AdaptiveConcurrencySettings {
+	return AdaptiveConcurrencySettings{
+		Enabled:                     false,
+		MinConsumers:                1,
+		LatencyThreshold:            2 * time.Second,
+		DecreaseRatio:               0.5,
+		PermanentErrorRateThreshold: 0.5,
+	}
+}
+
+// Validate checks if the AdaptiveConcurrencySettings configuration is valid against the maximum number of consumers.
+func (acCfg *AdaptiveConcurrencySettings) Validate(numConsumers int) error {
+	if !acCfg.Enabled {
+		return nil
+	}
+
+	if acCfg.MinConsumers <= 0 {
+		return errors.New("min consumers must be positive")
+	}
+
+	if acCfg.MinConsumers > numConsumers {
+		return fmt.Errorf("min consumers must not be greater than the number of consumers (%d)", numConsumers)
+	}
+
+	if acCfg.LatencyThreshold <= 0 {
+		return errors.New("latency threshold must be positive")
+	}
+
+	if acCfg.DecreaseRatio <= 0 || acCfg.DecreaseRatio >= 1 {
+		return errors.New("decrease ratio must be between 0 and 1")
+	}
+
+	if acCfg.PermanentErrorRateThreshold <= 0 || acCfg.PermanentErrorRateThreshold > 1 {
+		return errors.New("permanent error rate threshold must be greater than 0 and at most 1")
+	}
+
+	return nil
+}
+
+// concurrencyLimiter limits the number of requests processed concurrently by the queue consumers.
+// It is also a requestSender observing every attempt to send a request to the backend, and adapting the limit:
+// the limit is increased by one after a full window (as many attempts as the current limit, successful or failed
+// with a permanent error) and multiplied by DecreaseRatio when an attempt is throttled, times out or exceeds
+// LatencyThreshold, or when the permanent errors of a full window exceed PermanentErrorRateThreshold.
+type concurrencyLimiter struct {
+	cfg        AdaptiveConcurrencySettings
+	max        int
+	nextSender requestSender
+
+	mu           sync.Mutex
+	cond         *sync.Cond
+	limit        int
+	inFlight     int
+	successes    int
+	permanent    int
+	lastDecrease time.Time
+	stopped      bool
+}
+
+func newConcurrencyLimiter(cfg AdaptiveConcurrencySettings, max int, nextSender requestSender) *concurrencyLimiter {
+	cl := &concurrencyLimiter{
+		cfg:        cfg,
+		max:        max,
+		nextSender: nextSender,
+		limit:      cfg.MinConsumers,
+	}
+	cl.cond = sync.NewCond(&cl.mu)
+	return cl
+}
+
+// acquire blocks until the request can be processed without exceeding the current limit.
+func (cl *concurrencyLimiter) acquire() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	for cl.inFlight >= cl.limit && !cl.stopped {
+		cl.cond.Wait()
+	}
+	cl.inFlight++
+}
+
+// tryAcquire acquires a permit to process a request if it does not exceed the current limit, without waiting.
+func (cl *concurrencyLimiter) tryAcquire() bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.inFlight >= cl.limit && !cl.stopped {
+		return false
+	}
+	cl.inFlight++
+	return true
+}
+
+// release marks the processing of a request acquired previously as finished.
+func (cl *concurrencyLimiter) release() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.inFlight--
+	cl.cond.Signal()
+}
+
+// stop releases all blocked consumers and disables limiting, so the queue consumers can finish.
+func (cl *concurrencyLimiter) stop() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.stopped = true
+	cl.cond.Broadcast()
+}
+
+// currentLimit returns the current number of active consumers.
+func (cl *concurrencyLimiter) currentLimit() int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.limit
+}
+
+// send implements the requestSender interface
+func (cl *concurrencyLimiter) send(req request) error {
+	start := time.Now()
+	err := cl.nextSender.send(req)
+	cl.observe(start, time.Since(start), err)
+	return err
+}
+
+func (cl *concurrencyLimiter) observe(start time.Time, latency time.Duration, err error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if latency > cl.cfg.LatencyThreshold || isOverloadError(err) {
+		// Attempts started before the last decrease were sent with the previous limit,
+		// so they must not decrease the limit again.
+		if start.Before(cl.lastDecrease) {
+			return
+		}
+		cl.decrease()
+		return
+	}
+
+	switch {
+	case err == nil:
+		cl.successes++
+	case consumererror.IsPermanent(err):
+		cl.permanent++
+	default:
+		// Other errors, e.g. network errors retried later, say nothing about the backend load.
+		return
+	}
+
+	attempts := cl.successes + cl.permanent
+	if attempts < cl.limit {
+		return
+	}
+	if float64(cl.permanent) > float64(attempts)*cl.cfg.PermanentErrorRateThreshold {
+		cl.decrease()
+		return
+	}
+	cl.successes = 0
+	cl.permanent = 0
+	if cl.limit < cl.max {
+		cl.limit++
+		cl.cond.Signal()
+	}
+}
+
+// decrease multiplies the limit by DecreaseRatio, never going below MinConsumers, and starts a new window.
+func (cl *concurrencyLimiter) decrease() {
+	cl.limit = int(math.Max(float64(cl.cfg.MinConsumers), math.Floor(float64(cl.limit)*cl.cfg.DecreaseRatio)))
+	cl.successes = 0
+	cl.permanent = 0
+	cl.lastDecrease = time.Now()
+}
+
+// isOverloadError returns true if the error indicates that the backend is not able to handle the current load.
+func isOverloadError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.As(err, &throttleRetry{}) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+func TestAdaptiveConcurrencySettings_Validate(t *testing.T) {
+	acCfg := NewDefaultAdaptiveConcurrencySettings()
+	assert.NoError(t, acCfg.Validate(0))
+
+	acCfg.Enabled = true
+	assert.NoError(t, acCfg.Validate(10))
+	assert.EqualError(t, acCfg.Validate(0), "min consumers must not be greater than the number of consumers (0)")
+
+	acCfg = NewDefaultAdaptiveConcurrencySettings()
+	acCfg.Enabled = true
+	acCfg.MinConsumers = 0
+	assert.EqualError(t, acCfg.Validate(10), "min consumers must be positive")
+
+	acCfg = NewDefaultAdaptiveConcurrencySettings()
+	acCfg.Enabled = true
+	acCfg.LatencyThreshold = 0
+	assert.EqualError(t, acCfg.Validate(10), "latency threshold must be positive")
+
+	acCfg = NewDefaultAdaptiveConcurrencySettings()
+	acCfg.Enabled = true
+	acCfg.DecreaseRatio = 1
+	assert.EqualError(t, acCfg.Validate(10), "decrease ratio must be between 0 and 1")
+
+	acCfg = NewDefaultAdaptiveConcurrencySettings()
+	acCfg.Enabled = true
+	acCfg.PermanentErrorRateThreshold = 0
+	assert.EqualError(t, acCfg.Validate(10), "permanent error rate threshold must be greater than 0 and at most 1")
+
+	qCfg := NewDefaultQueueSettings()
+	qCfg.AdaptiveConcurrency.Enabled = true
+	qCfg.AdaptiveConcurrency.MinConsumers = 20
+	assert.EqualError(t, qCfg.Validate(), "adaptive concurrency has invalid configuration: min consumers must not be greater than the number of consumers (10)")
+}
+
+func TestConcurrencyLimiter_AIMD(t *testing.T) {
+	acCfg := NewDefaultAdaptiveConcurrencySettings()
+	acCfg.MinConsumers = 2
+	acCfg.LatencyThreshold = time.Second
+	cl := newConcurrencyLimiter(acCfg, 4, &timeoutSender{})
+	assert.Equal(t, 2, cl.currentLimit())
+
+	// A full window of successful attempts increases the limit by one.
+	successfulWindow := func() {
+		for i := cl.currentLimit(); i > 0; i-- {
+			cl.observe(time.Now(), time.Millisecond, nil)
+		}
+	}
+	successfulWindow()
+	assert.Equal(t, 3, cl.currentLimit())
+	successfulWindow()
+	assert.Equal(t, 4, cl.currentLimit())
+	// The limit never exceeds the maximum.
+	successfulWindow()
+	assert.Equal(t, 4, cl.currentLimit())
+
+	// Errors unrelated to the backend load do not change the limit.
+	cl.observe(time.Now(), time.Millisecond, errors.New("transient error"))
+	assert.Equal(t, 4, cl.currentLimit())
+
+	// A window of 4 attempts with permanent errors not above the threshold is still complete.
+	permanentErr := consumererror.NewPermanent(errors.New("bad data"))
+	cl.observe(time.Now(), time.Millisecond, permanentErr)
+	cl.observe(time.Now(), time.Millisecond, permanentErr)
+	cl.observe(time.Now(), time.Millisecond, nil)
+	cl.observe(time.Now(), time.Millisecond, nil)
+	assert.Equal(t, 4, cl.currentLimit())
+
+	// A window with permanent errors above the threshold decreases the limit multiplicatively.
+	cl.observe(time.Now(), time.Millisecond, permanentErr)
+	cl.observe(time.Now(), time.Millisecond, permanentErr)
+	cl.observe(time.Now(), time.Millisecond, permanentErr)
+	assert.Equal(t, 4, cl.currentLimit())
+	cl.observe(time.Now(), time.Millisecond, nil)
+	assert.Equal(t, 2, cl.currentLimit())
+	successfulWindow()
+	successfulWindow()
+	assert.Equal(t, 4, cl.currentLimit())
+
+	// A throttled attempt decreases the limit multiplicatively.
+	cl.observe(time.Now(), time.Millisecond, NewThrottleRetry(errors.New("throttle"), time.Second))
+	assert.Equal(t, 2, cl.currentLimit())
+
+	// Attempts started before the decrease do not decrease the limit again.
+	cl.observe(time.Now().Add(-time.Minute), 2*time.Second, nil)
+	assert.Equal(t, 2, cl.currentLimit())
+
+	// A slow attempt decreases the limit, but never below the minimum.
+	cl.observe(time.Now(), 2*time.Second, nil)
+	assert.Equal(t, 2, cl.currentLimit())
+}
+
+func TestConcurrencyLimiter_AcquireRelease(t *testing.T) {
+	acCfg := NewDefaultAdaptiveConcurrencySettings()
+	cl := newConcurrencyLimiter(acCfg, 10, &timeoutSender{})
+
+	cl.acquire()
+	acquired := atomic.NewBool(false)
+	go func() {
+		cl.acquire()
+		acquired.Store(true)
+	}()
+	// The limit is 1, so the second acquire is blocked until the first one is released.
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, acquired.Load())
+	cl.release()
+	assert.Eventually(t, acquired.Load, time.Second, time.Millisecond)
+
+	// Stopping unblocks all waiting consumers.
+	released := atomic.NewBool(false)
+	go func() {
+		cl.acquire()
+		released.Store(true)
+	}()
+	cl.stop()
+	assert.Eventually(t, released.Load, time.Second, time.Millisecond)
+}
+
+func TestConcurrencyLimiter_TryAcquire(t *testing.T) {
+	acCfg := NewDefaultAdaptiveConcurrencySettings()
+	cl := newConcurrencyLimiter(acCfg, 10, &timeoutSender{})
+
+	// The limit is 1, so no permit is available until the first one is released.
+	assert.True(t, cl.tryAcquire())
+	assert.False(t, cl.tryAcquire())
+	cl.release()
+	assert.True(t, cl.tryAcquire())
+
+	// Stopping disables limiting.
+	cl.stop()
+	assert.True(t, cl.tryAcquire())
+}
+
+func TestQueuedRetry_AdaptiveConcurrency(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 5
+	qCfg.AdaptiveConcurrency.Enabled = true
+	qCfg.AdaptiveConcurrency.MinConsumers = 2
+	rCfg := NewDefaultRetrySettings()
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	checkValueForGlobalManager(t, queueGaugeTags(""), int64(2), "exporter/queue_consumers")
+
+	for i := 0; i < 20; i++ {
+		ocs.run(func() {
+			require.NoError(t, be.sender.send(newMockRequest(context.Background(), 2, nil)))
+		})
+	}
+	ocs.awaitAsyncProcessing()
+	ocs.checkSendItemsCount(t, 40)
+	// 20 successful attempts are enough to reach the maximum number of consumers (2+3+4 attempts).
+	checkValueForGlobalManager(t, queueGaugeTags(""), int64(5), "exporter/queue_consumers")
+
+	assert.NoError(t, be.Shutdown(context.Background()))
+	checkValueForGlobalManager(t, queueGaugeTags(""), int64(0), "exporter/queue_consumers")
+}
@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BatcherSettings defines configuration for merging the requests sent to the exporter into batches before they are
+// queued, so batches are formed once per exporter even if the exporter is shared by several pipelines.
+type BatcherSettings struct {
+	// Enabled indicates whether to merge requests into batches.
+	Enabled bool `mapstructure:"enabled"`
+	// MinSizeItems is the number of spans, metric points or log records after which a batch is sent
+	// regardless of FlushTimeout.
+	MinSizeItems int `mapstructure:"min_size_items"`
+	// MaxSizeItems is the maximum number of items in a batch, larger batches are split. Zero means no limit.
+	MaxSizeItems int `mapstructure:"max_size_items"`
+	// FlushTimeout is the time after which a batch is sent regardless of its size.
+	FlushTimeout time.Duration `mapstructure:"flush_timeout"`
+}
+
+// NewDefaultBatcherSettings returns the default settings for BatcherSettings.
+func NewDefaultBatcherSettings() BatcherSettings {
+	return BatcherSettings{
+		Enabled:      true,
+		MinSizeItems: 8192,
+		FlushTimeout: 200 * time.Millisecond,
+	}
+}
+
+// Validate checks if the BatcherSettings configuration is valid
+func (bCfg *BatcherSettings) Validate() error {
+	if !bCfg.Enabled {
+		return nil
+	}
+
+	if bCfg.MinSizeItems < 0 {
+		return errors.New("min size items must not be negative")
+	}
+
+	if bCfg.MaxSizeItems < 0 {
+		return errors.New("max size items must not be negative")
+	}
+
+	if bCfg.MaxSizeItems > 0 && bCfg.MaxSizeItems < bCfg.MinSizeItems {
+		return errors.New("max size items must be greater than or equal to min size items")
+	}
+
+	if bCfg.FlushTimeout <= 0 {
+		return errors.New("flush timeout must be positive")
+	}
+
+	return nil
+}
+
+// batchRequest is a request which can be merged with, and split into, requests of the same type.
+type batchRequest interface {
+	request
+	// merge moves the data of the other request, which must be of the same type, to the end of this request.
+	merge(other request)
+	// split removes the given number of items from the request and returns them as a new request.
+	split(size int) request
+}
+
+// batchSender is placed before the queue, merging the requests into batches of at least MinSizeItems and at most
+// MaxSizeItems items, or sent after FlushTimeout. Like the batch processor, it is fire-and-forget: the requests merged
+// into the pending batch are accepted, and the failures of the batches, e.g. when the sending queue is full, are not
+// returned to the callers but logged and counted by the next senders.
+type batchSender struct {
+	cfg        BatcherSettings
+	logger     *zap.Logger
+	nextSender requestSender
+
+	mu      sync.Mutex
+	pending batchRequest
+	// timer flushes the pending batch after FlushTimeout, generation tells the batch it was started for.
+	timer      *time.Timer
+	generation uint64
+	stopped    bool
+}
+
+func newBatchSender(cfg BatcherSettings, logger *zap.Logger, nextSender requestSender) *batchSender {
+	return &batchSender{
+		cfg:        cfg,
+		logger:     logger,
+		nextSender: nextSender,
+	}
+}
+
+// send implements the requestSender interface. The request is added to the pending batch, and the batches completed
+// by this request are sent before returning, so a blocking sending queue still slows down the callers.
+func (bs *batchSender) send(req request) error {
+	br, ok := req.(batchRequest)
+	if !ok {
+		return bs.nextSender.send(req)
+	}
+
+	bs.mu.Lock()
+	if bs.stopped {
+		bs.mu.Unlock()
+		return bs.nextSender.send(req)
+	}
+	if bs.pending == nil {
+		// The batch is sent after the request returns, so it must not be canceled with the request context, but it
+		// keeps the values of the context, like the client metadata and the span, of the request starting it.
+		br.setContext(noCancellationContext{Context: br.context()})
+		bs.pending = br
+		bs.generation++
+		generation := bs.generation
+		bs.timer = time.AfterFunc(bs.cfg.FlushTimeout, func() { bs.flushOnTimeout(generation) })
+	} else {
+		bs.pending.merge(br)
+	}
+
+	var batches []request
+	for bs.cfg.MaxSizeItems > 0 && bs.pending.count() > bs.cfg.MaxSizeItems {
+		batches = append(batches, bs.pending.split(bs.cfg.MaxSizeItems))
+	}
+	if bs.pending.count() >= bs.cfg.MinSizeItems {
+		batches = append(batches, bs.takePending())
+	}
+	bs.mu.Unlock()
+
+	for _, batch := range batches {
+		bs.sendBatch(batch, "Failed to send the batch.")
+	}
+	return nil
+}
+
+// sendBatch sends the batch to the next sender, which logs and counts its failure.
+func (bs *batchSender) sendBatch(batch request, msg string) {
+	if err := bs.nextSender.send(batch); err != nil {
+		bs.logger.Debug(msg, zap.Int("items", batch.count()), zap.Error(err))
+	}
+}
+
+// takePending must be called while holding the lock.
+func (bs *batchSender) takePending() request {
+	batch := bs.pending
+	bs.pending = nil
+	bs.timer.Stop()
+	return batch
+}
+
+func (bs *batchSender) flushOnTimeout(generation uint64) {
+	bs.mu.Lock()
+	if bs.pending == nil || bs.generation != generation {
+		bs.mu.Unlock()
+		return
+	}
+	batch := bs.takePending()
+	bs.mu.Unlock()
+
+	bs.sendBatch(batch, "Failed to send the batch after flush timeout.")
+}
+
+// shutdown sends the pending batch, the requests sent afterwards are not batched.
+func (bs *batchSender) shutdown() {
+	bs.mu.Lock()
+	bs.stopped = true
+	if bs.pending == nil {
+		bs.mu.Unlock()
+		return
+	}
+	batch := bs.takePending()
+	bs.mu.Unlock()
+
+	bs.sendBatch(batch, "Failed to send the pending batch on shutdown.")
+}
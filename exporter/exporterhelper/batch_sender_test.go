@@ -0,0 +1,219 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestBatcherSettings_Validate(t *testing.T) {
+	bCfg := NewDefaultBatcherSettings()
+	assert.NoError(t, bCfg.Validate())
+
+	bCfg.MinSizeItems = -1
+	assert.EqualError(t, bCfg.Validate(), "min size items must not be negative")
+
+	bCfg = NewDefaultBatcherSettings()
+	bCfg.MaxSizeItems = -1
+	assert.EqualError(t, bCfg.Validate(), "max size items must not be negative")
+
+	bCfg.MaxSizeItems = bCfg.MinSizeItems - 1
+	assert.EqualError(t, bCfg.Validate(), "max size items must be greater than or equal to min size items")
+
+	bCfg = NewDefaultBatcherSettings()
+	bCfg.FlushTimeout = 0
+	assert.EqualError(t, bCfg.Validate(), "flush timeout must be positive")
+
+	// Confirm Validate doesn't return error with invalid config when feature is disabled
+	bCfg.Enabled = false
+	assert.NoError(t, bCfg.Validate())
+}
+
+type recordingSender struct {
+	mu     sync.Mutex
+	counts []int
+}
+
+func (rs *recordingSender) send(req request) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.counts = append(rs.counts, req.count())
+	return nil
+}
+
+func (rs *recordingSender) sentCounts() []int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]int{}, rs.counts...)
+}
+
+func TestBatchSender_MergeAndSplit(t *testing.T) {
+	next := &recordingSender{}
+	bs := newBatchSender(BatcherSettings{Enabled: true, MinSizeItems: 10, MaxSizeItems: 15, FlushTimeout: time.Hour}, zap.NewNop(), next)
+
+	send := func(spans int) {
+		require.NoError(t, bs.send(newTracesRequest(context.Background(), testdata.GenerateTracesManySpansSameResource(spans), nil)))
+	}
+	send(4)
+	send(4)
+	assert.Empty(t, next.sentCounts())
+
+	// The batch reaches the min size.
+	send(4)
+	assert.Equal(t, []int{12}, next.sentCounts())
+
+	// Batches larger than the max size are split.
+	send(40)
+	assert.Equal(t, []int{12, 15, 15, 10}, next.sentCounts())
+
+	// The pending batch is sent on shutdown, then requests are not batched anymore.
+	send(3)
+	bs.shutdown()
+	send(2)
+	assert.Equal(t, []int{12, 15, 15, 10, 3, 2}, next.sentCounts())
+}
+
+func TestBatchSender_FlushTimeout(t *testing.T) {
+	next := &recordingSender{}
+	bs := newBatchSender(BatcherSettings{Enabled: true, MinSizeItems: 100, FlushTimeout: 10 * time.Millisecond}, zap.NewNop(), next)
+
+	require.NoError(t, bs.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(3), nil)))
+	require.NoError(t, bs.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(2), nil)))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]int{5}, next.sentCounts())
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, bs.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(1), nil)))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]int{5, 1}, next.sentCounts())
+	}, time.Second, time.Millisecond)
+	bs.shutdown()
+}
+
+type contextSender struct {
+	ctxs chan context.Context
+}
+
+func (cs *contextSender) send(req request) error {
+	cs.ctxs <- req.context()
+	return nil
+}
+
+func TestBatchSender_KeepsContextValues(t *testing.T) {
+	next := &contextSender{ctxs: make(chan context.Context, 1)}
+	bs := newBatchSender(BatcherSettings{Enabled: true, MinSizeItems: 100, FlushTimeout: 10 * time.Millisecond}, zap.NewNop(), next)
+
+	ctx, cancel := context.WithCancel(client.NewContext(context.Background(), client.Info{
+		Metadata: client.NewMetadata(map[string][]string{"tenant": {"acme"}}),
+	}))
+	require.NoError(t, bs.send(newLogsRequest(ctx, testdata.GenerateLogsManyLogRecordsSameResource(1), nil)))
+	require.NoError(t, bs.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(1), nil)))
+	cancel()
+
+	// The batch has the values of the context of its first request, and is not canceled with it.
+	batchCtx := <-next.ctxs
+	assert.NoError(t, batchCtx.Err())
+	assert.Equal(t, []string{"acme"}, client.FromContext(batchCtx).Metadata.Get("tenant"))
+	bs.shutdown()
+}
+
+func TestBatchSender_NotBatchableRequest(t *testing.T) {
+	next := &recordingSender{}
+	bs := newBatchSender(BatcherSettings{Enabled: true, MinSizeItems: 100, FlushTimeout: time.Hour}, zap.NewNop(), next)
+	require.NoError(t, bs.send(newMockRequest(context.Background(), 2, nil)))
+	assert.Equal(t, []int{2}, next.sentCounts())
+}
+
+func TestMetricsExporter_Batcher(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	bCfg := BatcherSettings{Enabled: true, MinSizeItems: 8, MaxSizeItems: 10, FlushTimeout: time.Hour}
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	me, err := NewMetricsExporter(&fakeMetricsExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeMetrics, WithBatcher(bCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+	assert.True(t, me.Capabilities().MutatesData)
+	require.NoError(t, me.Start(context.Background(), componenttest.NewNopHost()))
+
+	// Every metric has 2 data points.
+	for i := 0; i < 5; i++ {
+		require.NoError(t, me.ConsumeMetrics(context.Background(), testdata.GenerateMetricsManyMetricsSameResource(1)))
+	}
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 8
+	}, time.Second, time.Millisecond)
+	require.Len(t, sink.AllMetrics(), 1)
+
+	// The last request is sent on shutdown.
+	require.NoError(t, me.Shutdown(context.Background()))
+	assert.Equal(t, 10, sink.DataPointCount())
+	require.Len(t, sink.AllMetrics(), 2)
+	assert.Equal(t, 2, sink.AllMetrics()[1].DataPointCount())
+}
+
+func TestLogsExporter_BatcherEnqueueFailure(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	pusher := func(context.Context, pdata.Logs) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	bCfg := BatcherSettings{Enabled: true, MinSizeItems: 5, FlushTimeout: time.Hour}
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.QueueSize = 1
+	le, err := NewLogsExporter(&fakeLogsExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithBatcher(bCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		close(release)
+		assert.NoError(t, le.Shutdown(context.Background()))
+	})
+	enqueueFailed := valueForGlobalManager(tagsForExporterView(fakeLogsExporterName), "exporter/enqueue_failed_log_records")
+
+	// Every batch of 3 requests of 2 log records is sent when the third one is added.
+	sendBatch := func() {
+		for i := 0; i < 3; i++ {
+			// The batches failing to be added to the queue are not returned to the callers.
+			assert.NoError(t, le.ConsumeLogs(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(2)))
+		}
+	}
+	sendBatch()
+	<-started
+	sendBatch()
+	sendBatch()
+
+	// The third batch is rejected by the full queue, and counted once with all its log records.
+	checkExporterEnqueueFailedLogsStats(t, globalInstruments, fakeLogsExporterName, enqueueFailed+6)
+}
+
+func TestTracesExporter_BatcherDisabledCapabilities(t *testing.T) {
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), func(context.Context, pdata.Traces) error { return nil })
+	require.NoError(t, err)
+	assert.False(t, te.Capabilities().MutatesData)
+}
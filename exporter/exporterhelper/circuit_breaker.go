@@ -0,0 +1,300 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+var errCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+// circuitOpenError is returned by the circuitBreakerAttemptSender when the circuit is open. The retry sender returns
+// it without counting a failed attempt, so the circuitBreakerSender waits with the request until it can be sent again.
+type circuitOpenError struct {
+	// req is the request not sent yet, it may only contain the data of the original request which failed.
+	req request
+}
+
+func (e circuitOpenError) Error() string {
+	return errCircuitBreakerOpen.Error()
+}
+
+func (e circuitOpenError) Unwrap() error {
+	return errCircuitBreakerOpen
+}
+
+// CircuitBreakerSettings defines configuration for stopping sending data to a backend which keeps failing.
+// After FailureThreshold consecutive failed attempts the circuit opens: no data is sent for OpenDuration,
+// queued data stays in the queue, and data which cannot be queued is rejected immediately. Then a single
+// probe request is allowed (half-open state), closing the circuit if it succeeds or opening it again otherwise.
+type CircuitBreakerSettings struct {
+	// Enabled indicates whether to stop sending data to a failing backend.
+	Enabled bool `mapstructure:"enabled"`
+	// FailureThreshold is the number of consecutive failed attempts after which the circuit opens.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// OpenDuration is the time to wait after the circuit opens before sending a probe request.
+	OpenDuration time.Duration `mapstructure:"open_duration"`
+}
+
+// NewDefaultCircuitBreakerSettings returns the default settings for CircuitBreakerSettings, the circuit breaker
+// is disabled.
+func NewDefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		Enabled:          false,
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	}
+}
+
+// Validate checks if the CircuitBreakerSettings configuration is valid
+func (cbCfg *CircuitBreakerSettings) Validate() error {
+	if !cbCfg.Enabled {
+		return nil
+	}
+
+	if cbCfg.FailureThreshold <= 0 {
+		return errors.New("failure threshold must be positive")
+	}
+
+	if cbCfg.OpenDuration <= 0 {
+		return errors.New("open duration must be positive")
+	}
+
+	return nil
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (cs circuitState) String() string {
+	switch cs {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
+// circuitBreaker holds the state of the circuit, shared by the circuitBreakerSender placed between the queue
+// and the retry sender, and the circuitBreakerAttemptSender observing every attempt made by the retry sender.
+type circuitBreaker struct {
+	cfg         CircuitBreakerSettings
+	labelValues []metricdata.LabelValue
+	logger      *zap.Logger
+	stopCh      chan struct{}
+	mu          sync.Mutex
+	state       circuitState
+	failures    int
+	openUntil   time.Time
+	// probe is the request allowed to be sent while the circuit is half-open.
+	probe       request
+	stateChange chan struct{}
+}
+
+func newCircuitBreaker(cfg CircuitBreakerSettings, labelValues []metricdata.LabelValue, stopCh chan struct{}, logger *zap.Logger) *circuitBreaker {
+	return &circuitBreaker{
+		cfg:         cfg,
+		labelValues: labelValues,
+		logger:      logger,
+		stopCh:      stopCh,
+		stateChange: make(chan struct{}),
+	}
+}
+
+// transition must be called while holding the lock.
+func (cb *circuitBreaker) transition(state circuitState) {
+	if cb.state == state {
+		return
+	}
+	cb.state = state
+	cb.failures = 0
+	if state == circuitOpen {
+		cb.openUntil = time.Now().Add(cb.cfg.OpenDuration)
+		cb.logger.Warn("Circuit breaker opened, stopped sending data.", zap.Duration("open_duration", cb.cfg.OpenDuration))
+	} else {
+		cb.logger.Info("Circuit breaker state changed.", zap.Stringer("state", state))
+	}
+	// Wake up all the senders waiting for a state change.
+	close(cb.stateChange)
+	cb.stateChange = make(chan struct{})
+
+	entry, err := globalInstruments.circuitBreakerTransitions.GetEntry(
+		append(append([]metricdata.LabelValue{}, cb.labelValues...), metricdata.NewLabelValue(state.String()))...)
+	if err == nil {
+		entry.Inc(1)
+	}
+}
+
+// acquire returns once req is allowed to be sent, i.e. the circuit is closed or req is the probe. It returns whether
+// req is the probe; if wait is false, it returns errCircuitBreakerOpen instead of waiting.
+func (cb *circuitBreaker) acquire(req request, wait bool) (bool, error) {
+	for {
+		cb.mu.Lock()
+		if cb.state == circuitClosed {
+			cb.mu.Unlock()
+			return false, nil
+		}
+		var timer <-chan time.Time
+		if cb.state == circuitOpen {
+			remaining := time.Until(cb.openUntil)
+			if remaining <= 0 {
+				cb.transition(circuitHalfOpen)
+			} else {
+				timer = time.After(remaining)
+			}
+		}
+		if cb.state == circuitHalfOpen && cb.probe == nil {
+			cb.probe = req
+			cb.mu.Unlock()
+			return true, nil
+		}
+		stateChange := cb.stateChange
+		cb.mu.Unlock()
+
+		if !wait {
+			return false, errCircuitBreakerOpen
+		}
+		select {
+		case <-stateChange:
+		case <-timer:
+		case <-cb.stopCh:
+			// Let the request go, the retry sender is stopped as well so it is tried at most once if the circuit
+			// is closed, otherwise it is returned with a circuitOpenError.
+			return false, nil
+		}
+	}
+}
+
+// releaseProbe marks the probe request as finished.
+func (cb *circuitBreaker) releaseProbe() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probe = nil
+	// If the probe did not change the state (e.g. permanent error), another request can be the probe.
+	close(cb.stateChange)
+	cb.stateChange = make(chan struct{})
+}
+
+// onAttempt records the result of an attempt to send data to the backend.
+func (cb *circuitBreaker) onAttempt(err error) {
+	// Permanent errors are caused by the data, so they say nothing about the backend health.
+	if err != nil && consumererror.IsPermanent(err) {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if err == nil {
+		cb.transition(circuitClosed)
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.cfg.FailureThreshold {
+		cb.transition(circuitOpen)
+	}
+}
+
+// allowAttempt returns whether an attempt to send req is allowed, i.e. the circuit is closed or req is the probe.
+func (cb *circuitBreaker) allowAttempt(req request) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		return cb.probe == req
+	}
+	return false
+}
+
+// stopped returns whether the exporter is shutting down.
+func (cb *circuitBreaker) stopped() bool {
+	select {
+	case <-cb.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// circuitBreakerSender is placed between the queue and the retry sender. While the circuit is open it holds
+// the requests taken from the queue, including the ones being retried when the circuit opened, or rejects them
+// immediately when they are not queued.
+type circuitBreakerSender struct {
+	breaker           *circuitBreaker
+	wait              bool
+	nextSender        requestSender
+	onShutdownFailure onRequestHandlingFinishedFunc
+	logger            *zap.Logger
+}
+
+// send implements the requestSender interface
+func (cbs *circuitBreakerSender) send(req request) error {
+	ctx := req.context()
+	for {
+		probe, err := cbs.breaker.acquire(req, cbs.wait)
+		if err != nil {
+			return err
+		}
+		err = cbs.nextSender.send(req)
+		if probe {
+			cbs.breaker.releaseProbe()
+		}
+
+		var openErr circuitOpenError
+		if !errors.As(err, &openErr) || !cbs.wait {
+			return err
+		}
+		if cbs.breaker.stopped() {
+			return cbs.onShutdownFailure(cbs.logger, openErr.req, fmt.Errorf("interrupted due to shutdown %w", err))
+		}
+		// The circuit opened while the request was retried, the retries start again once it can be sent.
+		req = openErr.req
+		req.setContext(ctx)
+	}
+}
+
+// circuitBreakerAttemptSender observes every attempt made by the retry sender. While the circuit is open, or
+// half-open and the request is not the probe, attempts fail immediately with a circuitOpenError.
+type circuitBreakerAttemptSender struct {
+	breaker    *circuitBreaker
+	nextSender requestSender
+}
+
+// send implements the requestSender interface
+func (cbas *circuitBreakerAttemptSender) send(req request) error {
+	if !cbas.breaker.allowAttempt(req) {
+		return circuitOpenError{req: req}
+	}
+	err := cbas.nextSender.send(req)
+	cbas.breaker.onAttempt(err)
+	return err
+}
@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+func TestCircuitBreakerSettings_Validate(t *testing.T) {
+	cbCfg := NewDefaultCircuitBreakerSettings()
+	assert.NoError(t, cbCfg.Validate())
+	cbCfg.Enabled = true
+	assert.NoError(t, cbCfg.Validate())
+
+	cbCfg.FailureThreshold = 0
+	assert.EqualError(t, cbCfg.Validate(), "failure threshold must be positive")
+
+	cbCfg = NewDefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.OpenDuration = 0
+	assert.EqualError(t, cbCfg.Validate(), "open duration must be positive")
+
+	// Confirm Validate doesn't return error with invalid config when feature is disabled
+	cbCfg.Enabled = false
+	assert.NoError(t, cbCfg.Validate())
+}
+
+func TestCircuitBreaker_States(t *testing.T) {
+	cbCfg := NewDefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 2
+	cbCfg.OpenDuration = 20 * time.Millisecond
+	cb := newCircuitBreaker(cbCfg, nil, make(chan struct{}), zap.NewNop())
+	firstReq := newMockRequest(context.Background(), 1, nil)
+	secondReq := newMockRequest(context.Background(), 1, nil)
+
+	// Permanent errors and non consecutive failures do not open the circuit.
+	cb.onAttempt(errors.New("transient error"))
+	cb.onAttempt(nil)
+	cb.onAttempt(errors.New("transient error"))
+	cb.onAttempt(consumererror.NewPermanent(errors.New("bad data")))
+	probe, err := cb.acquire(firstReq, false)
+	assert.NoError(t, err)
+	assert.False(t, probe)
+	assert.True(t, cb.allowAttempt(secondReq))
+
+	cb.onAttempt(errors.New("transient error"))
+	assert.Equal(t, circuitOpen, cb.state)
+	assert.False(t, cb.allowAttempt(firstReq))
+	_, err = cb.acquire(firstReq, false)
+	assert.Equal(t, errCircuitBreakerOpen, err)
+
+	// Once the open duration elapses, a single probe is allowed.
+	probe, err = cb.acquire(firstReq, true)
+	assert.NoError(t, err)
+	assert.True(t, probe)
+	assert.Equal(t, circuitHalfOpen, cb.state)
+	_, err = cb.acquire(secondReq, false)
+	assert.Equal(t, errCircuitBreakerOpen, err)
+	assert.True(t, cb.allowAttempt(firstReq))
+	assert.False(t, cb.allowAttempt(secondReq))
+
+	// A failed probe opens the circuit again.
+	cb.onAttempt(errors.New("transient error"))
+	cb.releaseProbe()
+	assert.Equal(t, circuitOpen, cb.state)
+	assert.False(t, cb.allowAttempt(firstReq))
+
+	probe, err = cb.acquire(secondReq, true)
+	assert.NoError(t, err)
+	assert.True(t, probe)
+	cb.onAttempt(nil)
+	cb.releaseProbe()
+	assert.Equal(t, circuitClosed, cb.state)
+	assert.True(t, cb.allowAttempt(firstReq))
+}
+
+func TestCircuitBreaker_StopUnblocksWaiting(t *testing.T) {
+	cbCfg := NewDefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 1
+	cbCfg.OpenDuration = time.Hour
+	stopCh := make(chan struct{})
+	cb := newCircuitBreaker(cbCfg, nil, stopCh, zap.NewNop())
+	cb.onAttempt(errors.New("transient error"))
+
+	done := make(chan struct{})
+	go func() {
+		_, err := cb.acquire(newMockRequest(context.Background(), 1, nil), true)
+		assert.NoError(t, err)
+		close(done)
+	}()
+	close(stopCh)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "acquire was not unblocked by stop")
+	}
+}
+
+func TestQueuedRetry_CircuitBreaker(t *testing.T) {
+	cbCfg := NewDefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 1
+	cbCfg.OpenDuration = 50 * time.Millisecond
+	rCfg := NewDefaultRetrySettings()
+	rCfg.Enabled = false
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "circuit_breaker"))
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithCircuitBreaker(cbCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	stateTag, _ := tag.NewKey("state")
+	stateTags := func(state string) []tag.Tag {
+		return []tag.Tag{{Key: exporterTag, Value: "test/circuit_breaker"}, {Key: signalTag, Value: ""}, {Key: stateTag, Value: state}}
+	}
+	states := []string{"open", "half_open", "closed"}
+	transitions := make(map[string]int64, len(states))
+	for _, state := range states {
+		transitions[state] = valueForGlobalManager(stateTags(state), "exporter/circuit_breaker_transitions")
+	}
+
+	mockR := newMockRequest(context.Background(), 2, errors.New("transient error"))
+	require.Error(t, be.sender.send(mockR))
+	mockR.checkNumRequests(t, 1)
+
+	// The circuit is open, so the data is rejected without being sent.
+	assert.Equal(t, errCircuitBreakerOpen, be.sender.send(mockR))
+	mockR.checkNumRequests(t, 1)
+
+	// After the open duration, the request is sent as the probe and closes the circuit.
+	time.Sleep(cbCfg.OpenDuration)
+	require.NoError(t, be.sender.send(mockR))
+	mockR.checkNumRequests(t, 2)
+	require.NoError(t, be.sender.send(mockR))
+	mockR.checkNumRequests(t, 3)
+
+	for _, state := range states {
+		checkValueForGlobalManager(t, stateTags(state), transitions[state]+1, "exporter/circuit_breaker_transitions")
+	}
+}
+
+func TestQueuedRetry_CircuitBreakerKeepsDataQueued(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	cbCfg := NewDefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 1
+	cbCfg.OpenDuration = 50 * time.Millisecond
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg), WithCircuitBreaker(cbCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	firstMockR := newMockRequest(context.Background(), 2, errors.New("transient error"))
+	secondMockR := newMockRequest(context.Background(), 3, nil)
+	start := time.Now()
+	ocs.run(func() {
+		require.NoError(t, be.sender.send(firstMockR))
+	})
+	ocs.run(func() {
+		require.NoError(t, be.sender.send(secondMockR))
+	})
+	ocs.awaitAsyncProcessing()
+
+	// The first request opened the circuit, its retry waited for the circuit to be half-open and closed it.
+	assert.Less(t, cbCfg.OpenDuration, time.Since(start))
+	firstMockR.checkNumRequests(t, 2)
+	secondMockR.checkNumRequests(t, 1)
+	ocs.checkSendItemsCount(t, 5)
+	ocs.checkDroppedItemsCount(t, 0)
+}
+
+func TestQueuedRetry_CircuitBreakerOpenDoesNotConsumeRetries(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	cbCfg := NewDefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 1
+	cbCfg.OpenDuration = 100 * time.Millisecond
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	// The circuit stays open longer than the retries are allowed to last.
+	rCfg.MaxElapsedTime = 20 * time.Millisecond
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg), WithCircuitBreaker(cbCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	mockR := newMockRequest(context.Background(), 2, errors.New("transient error"))
+	ocs.run(func() {
+		require.NoError(t, be.sender.send(mockR))
+	})
+	ocs.awaitAsyncProcessing()
+
+	// The request waited for the circuit to be half-open without being dropped, and was sent as the probe.
+	mockR.checkNumRequests(t, 2)
+	ocs.checkSendItemsCount(t, 2)
+	ocs.checkDroppedItemsCount(t, 0)
+}
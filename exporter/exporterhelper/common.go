@@ -16,6 +16,8 @@ package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporte
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -29,6 +31,9 @@ import (
 type TimeoutSettings struct {
 	// Timeout is the timeout for every attempt to send data to the backend.
 	Timeout time.Duration `mapstructure:"timeout"`
+	// RequestTimeout is the timeout for sending a request, spanning all the retries and the backoff between them.
+	// Zero means that the time spent sending a request is only bounded by the retry settings.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
 }
 
 // NewDefaultTimeoutSettings returns the default settings for TimeoutSettings.
@@ -50,6 +55,12 @@ type request interface {
 	onError(error) request
 	// Returns the count of spans/metric points or log records.
 	count() int
+	// Returns the size in bytes of the request serialized as OTLP protobuf, used to bound the sending queue by size.
+	byteSize() int
+	// enqueuedTime returns when the request was put in the sending queue, zero if it is unknown.
+	enqueuedTime() time.Time
+	// setEnqueuedTime records when the request was put in the sending queue, to measure the time spent there.
+	setEnqueuedTime(time.Time)
 
 	// PersistentRequest provides interface with additional capabilities required by persistent queue
 	internal.PersistentRequest
@@ -63,6 +74,7 @@ type requestSender interface {
 // baseRequest is a base implementation for the request.
 type baseRequest struct {
 	ctx                        context.Context
+	enqueued                   time.Time
 	processingFinishedCallback func()
 }
 
@@ -74,6 +86,14 @@ func (req *baseRequest) setContext(ctx context.Context) {
 	req.ctx = ctx
 }
 
+func (req *baseRequest) enqueuedTime() time.Time {
+	return req.enqueued
+}
+
+func (req *baseRequest) setEnqueuedTime(enqueued time.Time) {
+	req.enqueued = enqueued
+}
+
 func (req *baseRequest) SetOnProcessingFinished(callback func()) {
 	req.processingFinishedCallback = callback
 }
@@ -92,6 +112,11 @@ type baseSettings struct {
 	TimeoutSettings
 	QueueSettings
 	RetrySettings
+	CircuitBreakerSettings
+	RateLimitSettings
+	BatcherSettings
+	HedgingSettings
+	PriorityClassifier
 }
 
 // fromOptions returns the internal options starting from the default and applying all configured options.
@@ -102,16 +127,47 @@ func fromOptions(options ...Option) *baseSettings {
 		// TODO: Enable queuing by default (call DefaultQueueSettings)
 		QueueSettings: QueueSettings{Enabled: false},
 		// TODO: Enable retry by default (call DefaultRetrySettings)
-		RetrySettings: RetrySettings{Enabled: false},
+		RetrySettings:          RetrySettings{Enabled: false},
+		CircuitBreakerSettings: CircuitBreakerSettings{Enabled: false},
+		RateLimitSettings:      RateLimitSettings{Enabled: false},
+		BatcherSettings:        BatcherSettings{Enabled: false},
+		HedgingSettings:        HedgingSettings{Enabled: false},
 	}
 
 	for _, op := range options {
 		op(opts)
 	}
 
+	if opts.BatcherSettings.Enabled {
+		// Requests are merged by moving the data, so the exporter must get its own copy of the data.
+		opts.consumerOptions = append(opts.consumerOptions, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+	}
+
 	return opts
 }
 
+// validate checks the settings that are only configured through options, the others are validated with the
+// exporter configuration.
+func (bs *baseSettings) validate() error {
+	if err := bs.CircuitBreakerSettings.Validate(); err != nil {
+		return fmt.Errorf("circuit breaker has invalid configuration: %w", err)
+	}
+
+	if err := bs.RateLimitSettings.Validate(); err != nil {
+		return fmt.Errorf("rate limit has invalid configuration: %w", err)
+	}
+
+	if err := bs.BatcherSettings.Validate(); err != nil {
+		return fmt.Errorf("batcher has invalid configuration: %w", err)
+	}
+
+	if err := bs.HedgingSettings.Validate(); err != nil {
+		return fmt.Errorf("hedging has invalid configuration: %w", err)
+	}
+
+	return nil
+}
+
 // Option apply changes to baseSettings.
 type Option func(*baseSettings)
 
@@ -155,6 +211,48 @@ func WithQueue(queueSettings QueueSettings) Option {
 	}
 }
 
+// WithCircuitBreaker overrides the default CircuitBreakerSettings for an exporter.
+// The default CircuitBreakerSettings is to disable the circuit breaker.
+func WithCircuitBreaker(circuitBreakerSettings CircuitBreakerSettings) Option {
+	return func(o *baseSettings) {
+		o.CircuitBreakerSettings = circuitBreakerSettings
+	}
+}
+
+// WithRateLimit overrides the default RateLimitSettings for an exporter.
+// The default RateLimitSettings is to not limit the rate of data sent.
+func WithRateLimit(rateLimitSettings RateLimitSettings) Option {
+	return func(o *baseSettings) {
+		o.RateLimitSettings = rateLimitSettings
+	}
+}
+
+// WithBatcher overrides the default BatcherSettings for an exporter.
+// The default BatcherSettings is to disable batching.
+// Like the batch processor, batching is fire-and-forget: the data merged into a batch is accepted, and the failures
+// of the batches are logged and counted in the exporter/enqueue_failed_* metrics instead of being returned.
+func WithBatcher(batcherSettings BatcherSettings) Option {
+	return func(o *baseSettings) {
+		o.BatcherSettings = batcherSettings
+	}
+}
+
+// WithHedging overrides the default HedgingSettings for an exporter.
+// The default HedgingSettings is to disable hedged requests.
+func WithHedging(hedgingSettings HedgingSettings) Option {
+	return func(o *baseSettings) {
+		o.HedgingSettings = hedgingSettings
+	}
+}
+
+// WithPriorityClassifier sets the PriorityClassifier putting the batches in the priority lanes of the sending queue.
+// The default is to put all the batches in the last lane.
+func WithPriorityClassifier(classifier PriorityClassifier) Option {
+	return func(o *baseSettings) {
+		o.PriorityClassifier = classifier
+	}
+}
+
 // WithCapabilities overrides the default Capabilities() function for a Consumer.
 // The default is non-mutable data.
 // TODO: Verify if we can change the default to be mutable as we do for processors.
@@ -171,6 +269,7 @@ type baseExporter struct {
 	obsrep   *obsExporter
 	sender   requestSender
 	qrSender *queuedRetrySender
+	batcher  *batchSender
 }
 
 func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings, bs *baseSettings, signal config.DataType, reqUnmarshaler internal.RequestUnmarshaler) *baseExporter {
@@ -180,9 +279,18 @@ func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings,
 		Level:                  set.MetricsLevel,
 		ExporterID:             cfg.ID(),
 		ExporterCreateSettings: set,
-	}, globalInstruments)
-	be.qrSender = newQueuedRetrySender(cfg.ID(), signal, bs.QueueSettings, bs.RetrySettings, reqUnmarshaler, &timeoutSender{cfg: bs.TimeoutSettings}, set.Logger)
+	}, signal, globalInstruments)
+	be.qrSender = newQueuedRetrySender(cfg.ID(), signal, bs, reqUnmarshaler, &timeoutSender{cfg: bs.TimeoutSettings}, be.obsrep, set.Logger)
 	be.sender = be.qrSender
+	if record := be.obsrep.enqueueFailureRecorder(signal); record != nil {
+		be.sender = &enqueueFailureSender{record: record, nextSender: be.sender}
+	}
+	if bs.BatcherSettings.Enabled {
+		// The batches are counted when they fail to be added to the sending queue, not the requests merged into them.
+		be.batcher = newBatchSender(bs.BatcherSettings, set.Logger, be.sender)
+		be.qrSender.batcher = be.batcher
+		be.sender = be.batcher
+	}
 	be.StartFunc = func(ctx context.Context, host component.Host) error {
 		// First start the wrapped exporter.
 		if err := bs.StartFunc.Start(ctx, host); err != nil {
@@ -193,8 +301,11 @@ func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings,
 		return be.qrSender.start(ctx, host)
 	}
 	be.ShutdownFunc = func(ctx context.Context) error {
-		// First shutdown the queued retry sender
-		be.qrSender.shutdown()
+		// First send the pending batch and shutdown the queued retry sender
+		if be.batcher != nil {
+			be.batcher.shutdown()
+		}
+		be.qrSender.shutdown(ctx)
 		// Last shutdown the wrapped exporter itself.
 		return bs.ShutdownFunc.Shutdown(ctx)
 	}
@@ -207,6 +318,21 @@ func (be *baseExporter) wrapConsumerSender(f func(consumer requestSender) reques
 	be.qrSender.consumerSender = f(be.qrSender.consumerSender)
 }
 
+// enqueueFailureSender records the items of the requests which failed to be added to the sending queue.
+type enqueueFailureSender struct {
+	record     func(ctx context.Context, numItems int64)
+	nextSender requestSender
+}
+
+// send implements the requestSender interface
+func (es *enqueueFailureSender) send(req request) error {
+	err := es.nextSender.send(req)
+	if errors.Is(err, errSendingQueueIsFull) {
+		es.record(req.context(), int64(req.count()))
+	}
+	return err
+}
+
 // timeoutSender is a request sender that adds a `timeout` to every request that passes this sender.
 type timeoutSender struct {
 	cfg TimeoutSettings
@@ -214,12 +340,16 @@ type timeoutSender struct {
 
 // send implements the requestSender interface
 func (ts *timeoutSender) send(req request) error {
+	return ts.export(req.context(), req)
+}
+
+// export exports the request with the given context, limited by the timeout.
+func (ts *timeoutSender) export(ctx context.Context, req request) error {
 	// Intentionally don't overwrite the context inside the request, because in case of retries deadline will not be
 	// updated because this deadline most likely is before the next one.
-	ctx := req.context()
 	if ts.cfg.Timeout > 0 {
 		var cancelFunc func()
-		ctx, cancelFunc = context.WithTimeout(req.context(), ts.cfg.Timeout)
+		ctx, cancelFunc = context.WithTimeout(ctx, ts.cfg.Timeout)
 		defer cancelFunc()
 	}
 	return req.export(ctx)
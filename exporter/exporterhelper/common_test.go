@@ -34,11 +34,17 @@ import (
 var (
 	defaultExporterCfg  = config.NewExporterSettings(config.NewComponentID("test"))
 	exporterTag, _      = tag.NewKey("exporter")
+	signalTag, _        = tag.NewKey("signal")
 	defaultExporterTags = []tag.Tag{
 		{Key: exporterTag, Value: "test"},
 	}
 )
 
+// queueGaugeTags returns the tags of the queue gauges of the default exporter of the signal.
+func queueGaugeTags(signal config.DataType) []tag.Tag {
+	return []tag.Tag{{Key: exporterTag, Value: "test"}, {Key: signalTag, Value: string(signal)}}
+}
+
 func TestBaseExporter(t *testing.T) {
 	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(), "", nopRequestUnmarshaler())
 	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
@@ -61,6 +67,18 @@ func TestBaseExporterWithOptions(t *testing.T) {
 	require.Equal(t, want, be.Shutdown(context.Background()))
 }
 
+func TestBaseSettings_Validate(t *testing.T) {
+	require.NoError(t, fromOptions().validate())
+	require.EqualError(t, fromOptions(WithCircuitBreaker(CircuitBreakerSettings{Enabled: true})).validate(),
+		"circuit breaker has invalid configuration: failure threshold must be positive")
+	require.EqualError(t, fromOptions(WithRateLimit(RateLimitSettings{Enabled: true})).validate(),
+		"rate limit has invalid configuration: at least one of items per second or requests per second must be set")
+	require.EqualError(t, fromOptions(WithBatcher(BatcherSettings{Enabled: true})).validate(),
+		"batcher has invalid configuration: flush timeout must be positive")
+	require.EqualError(t, fromOptions(WithHedging(HedgingSettings{Enabled: true})).validate(),
+		"hedging has invalid configuration: quantile must be in the range (0, 1]")
+}
+
 func checkStatus(t *testing.T, sd sdktrace.ReadOnlySpan, err error) {
 	if err != nil {
 		require.Equal(t, codes.Error, sd.Status().Code, "SpanData %v", sd)
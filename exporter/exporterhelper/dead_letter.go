@@ -0,0 +1,405 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uatomic "go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+var (
+	errDeadLetterNoDestination        = errors.New("dead letter requires either a directory or a storage extension")
+	errDeadLetterMultipleDestinations = errors.New("dead letter requires only one of directory or storage extension")
+	errDeadLetterStorageNotFound      = errors.New("dead letter storage extension not found")
+	errDeadLetterNotStorageExtension  = errors.New("requested dead letter extension is not a storage extension")
+	errDeadLetterDisabled             = errors.New("dead letter is not enabled")
+	errDrainAbandoned                 = errors.New("sending queue drain abandoned")
+)
+
+// DeadLetterSettings defines configuration for storing the batches which could not be sent after all retries
+// were exhausted, so they can be replayed into the exporter later instead of being lost.
+type DeadLetterSettings struct {
+	// Enabled indicates whether to store the batches which exhausted retries instead of dropping them.
+	Enabled bool `mapstructure:"enabled"`
+	// Directory is the path to a local directory where the batches are stored as files.
+	Directory string `mapstructure:"directory"`
+	// StorageID if not nil, identifies the storage extension used to store the batches instead of Directory.
+	StorageID *config.ComponentID `mapstructure:"storage"`
+	// ReplayOnStart indicates whether the stored batches are replayed into the exporter in the background
+	// after it starts.
+	ReplayOnStart bool `mapstructure:"replay_on_start"`
+}
+
+// Validate checks if the DeadLetterSettings configuration is valid
+func (dlCfg *DeadLetterSettings) Validate() error {
+	if !dlCfg.Enabled {
+		return nil
+	}
+
+	if dlCfg.Directory == "" && dlCfg.StorageID == nil {
+		return errDeadLetterNoDestination
+	}
+
+	if dlCfg.Directory != "" && dlCfg.StorageID != nil {
+		return errDeadLetterMultipleDestinations
+	}
+
+	return nil
+}
+
+// DeadLetterReplayer is implemented by the exporters created by this package. It allows sending the batches stored
+// in the dead letter destination into the same exporter again, e.g. once a long backend outage is resolved.
+type DeadLetterReplayer interface {
+	// ReplayDeadLetters sends the stored batches to the exporter in the order they were stored, and returns
+	// the number of batches replayed. Batches are removed from the dead letter destination once they are accepted
+	// by the exporter; replaying stops on the first batch which is not accepted.
+	ReplayDeadLetters(ctx context.Context) (int, error)
+}
+
+// ReplayDeadLetters implements the DeadLetterReplayer interface.
+func (be *baseExporter) ReplayDeadLetters(ctx context.Context) (int, error) {
+	return be.qrSender.replayDeadLetters(ctx)
+}
+
+// deadLetterSink stores serialized requests which could not be sent.
+type deadLetterSink interface {
+	// put stores the serialized request.
+	put(ctx context.Context, data []byte) error
+	// replay calls fn for every request stored before the call, in the order they were stored, and removes
+	// each request once fn succeeds for it. It stops on the first error and returns the number of removed requests.
+	// Concurrent calls are serialized, so every request is replayed once.
+	replay(ctx context.Context, fn func([]byte) error) (int, error)
+	// shutdown releases the resources held by the sink.
+	shutdown(ctx context.Context) error
+}
+
+func newDeadLetterSink(ctx context.Context, host component.Host, cfg DeadLetterSettings, id config.ComponentID, signal config.DataType) (deadLetterSink, error) {
+	if cfg.StorageID == nil {
+		return newFileDeadLetterSink(cfg.Directory, id, signal)
+	}
+
+	ext, found := host.GetExtensions()[*cfg.StorageID]
+	if !found {
+		return nil, fmt.Errorf("failed to resolve dead letter storage %q: %w", *cfg.StorageID, errDeadLetterStorageNotFound)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, errDeadLetterNotStorageExtension
+	}
+	client, err := storageExt.GetClient(ctx, component.KindExporter, id, string(signal)+"_dead_letter")
+	if err != nil {
+		return nil, err
+	}
+	return newStorageDeadLetterSink(ctx, client)
+}
+
+const deadLetterFileExt = ".pb"
+
+// fileDeadLetterSink stores every request in a separate file, named so the lexical order matches the order
+// in which the requests were stored.
+type fileDeadLetterSink struct {
+	dir      string
+	seq      *uatomic.Uint64
+	replayMu sync.Mutex
+}
+
+func newFileDeadLetterSink(directory string, id config.ComponentID, signal config.DataType) (*fileDeadLetterSink, error) {
+	name := strings.ReplaceAll(id.String(), "/", "_")
+	if signal != "" {
+		name += "_" + string(signal)
+	}
+	dir := filepath.Join(directory, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+	return &fileDeadLetterSink{dir: dir, seq: uatomic.NewUint64(0)}, nil
+}
+
+func (fs *fileDeadLetterSink) put(_ context.Context, data []byte) error {
+	name := fmt.Sprintf("%020d-%010d", time.Now().UnixNano(), fs.seq.Inc())
+	// Write to a temporary file first, so a partially written file is never replayed.
+	tmpPath := filepath.Join(fs.dir, name+".tmp")
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(fs.dir, name+deadLetterFileExt))
+}
+
+func (fs *fileDeadLetterSink) replay(_ context.Context, fn func([]byte) error) (int, error) {
+	fs.replayMu.Lock()
+	defer fs.replayMu.Unlock()
+
+	entries, err := ioutil.ReadDir(fs.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == deadLetterFileExt {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	replayed := 0
+	for _, name := range names {
+		path := filepath.Join(fs.dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return replayed, err
+		}
+		if err = fn(data); err != nil {
+			return replayed, err
+		}
+		if err = os.Remove(path); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (fs *fileDeadLetterSink) shutdown(context.Context) error {
+	return nil
+}
+
+const (
+	deadLetterReadIndexKey  = "dl_ri"
+	deadLetterWriteIndexKey = "dl_wi"
+)
+
+// storageDeadLetterSink stores the requests using a storage extension client, under contiguous indexes
+// between the read index (the oldest request) and the write index (where the next request is stored).
+type storageDeadLetterSink struct {
+	client storage.Client
+	// replayMu serializes the replays, mu protects the indexes so the requests can be stored while replaying.
+	replayMu   sync.Mutex
+	mu         sync.Mutex
+	readIndex  uint64
+	writeIndex uint64
+}
+
+func newStorageDeadLetterSink(ctx context.Context, client storage.Client) (*storageDeadLetterSink, error) {
+	readOp := storage.GetOperation(deadLetterReadIndexKey)
+	writeOp := storage.GetOperation(deadLetterWriteIndexKey)
+	if err := client.Batch(ctx, readOp, writeOp); err != nil {
+		return nil, err
+	}
+
+	ss := &storageDeadLetterSink{client: client}
+	var err error
+	if ss.readIndex, err = bytesToDeadLetterIndex(readOp.Value); err != nil {
+		return nil, err
+	}
+	if ss.writeIndex, err = bytesToDeadLetterIndex(writeOp.Value); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (ss *storageDeadLetterSink) put(ctx context.Context, data []byte) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	err := ss.client.Batch(ctx,
+		storage.SetOperation(deadLetterItemKey(ss.writeIndex), data),
+		storage.SetOperation(deadLetterWriteIndexKey, deadLetterIndexToBytes(ss.writeIndex+1)))
+	if err != nil {
+		return err
+	}
+	ss.writeIndex++
+	return nil
+}
+
+func (ss *storageDeadLetterSink) replay(ctx context.Context, fn func([]byte) error) (int, error) {
+	ss.replayMu.Lock()
+	defer ss.replayMu.Unlock()
+
+	ss.mu.Lock()
+	end := ss.writeIndex
+	ss.mu.Unlock()
+
+	replayed := 0
+	for {
+		ss.mu.Lock()
+		index := ss.readIndex
+		ss.mu.Unlock()
+		if index >= end {
+			return replayed, nil
+		}
+
+		data, err := ss.client.Get(ctx, deadLetterItemKey(index))
+		if err != nil {
+			return replayed, err
+		}
+		// Missing items are skipped, there is nothing to replay for them.
+		if data != nil {
+			if err = fn(data); err != nil {
+				return replayed, err
+			}
+			replayed++
+		}
+
+		ss.mu.Lock()
+		ss.readIndex++
+		err = ss.client.Batch(ctx,
+			storage.DeleteOperation(deadLetterItemKey(index)),
+			storage.SetOperation(deadLetterReadIndexKey, deadLetterIndexToBytes(ss.readIndex)))
+		ss.mu.Unlock()
+		if err != nil {
+			return replayed, err
+		}
+	}
+}
+
+func (ss *storageDeadLetterSink) shutdown(ctx context.Context) error {
+	return ss.client.Close(ctx)
+}
+
+func deadLetterItemKey(index uint64) string {
+	return "dl_" + strconv.FormatUint(index, 10)
+}
+
+func deadLetterIndexToBytes(index uint64) []byte {
+	var buf bytes.Buffer
+	// Writing a fixed-size value to a bytes.Buffer cannot fail.
+	_ = binary.Write(&buf, binary.LittleEndian, index)
+	return buf.Bytes()
+}
+
+func bytesToDeadLetterIndex(b []byte) (uint64, error) {
+	if b == nil {
+		return 0, nil
+	}
+	var index uint64
+	err := binary.Read(bytes.NewReader(b), binary.LittleEndian, &index)
+	return index, err
+}
+
+// startDeadLetter creates the dead letter sink if it is enabled, and starts replaying the stored requests in the
+// background if configured, so sending them does not block starting the exporter.
+func (qrs *queuedRetrySender) startDeadLetter(ctx context.Context, host component.Host) error {
+	if !qrs.deadLetterCfg.Enabled {
+		return nil
+	}
+
+	sink, err := newDeadLetterSink(ctx, host, qrs.deadLetterCfg, qrs.id, qrs.signal)
+	if err != nil {
+		return err
+	}
+	qrs.deadLetter = sink
+
+	if qrs.deadLetterCfg.ReplayOnStart {
+		// The start context is only valid during Start, the replay is cancelled on shutdown instead.
+		replayCtx, cancel := context.WithCancel(context.Background())
+		qrs.replayCancel = cancel
+		qrs.replayWG.Add(1)
+		go func() {
+			defer qrs.replayWG.Done()
+			replayed, err := qrs.replayDeadLetters(replayCtx)
+			if err != nil {
+				// The requests which were not replayed stay in the dead letter.
+				qrs.logger.Error("Failed replaying dead letter data.", zap.Error(err), zap.Int("replayed_batches", replayed))
+			} else if replayed > 0 {
+				qrs.logger.Info("Replayed dead letter data.", zap.Int("replayed_batches", replayed))
+			}
+		}()
+	}
+	return nil
+}
+
+// stopDeadLetterReplay cancels the replay started with the exporter and waits for it to return.
+func (qrs *queuedRetrySender) stopDeadLetterReplay() {
+	if qrs.replayCancel != nil {
+		qrs.replayCancel()
+	}
+	qrs.replayWG.Wait()
+}
+
+// shutdownDeadLetter releases the resources held by the dead letter sink.
+func (qrs *queuedRetrySender) shutdownDeadLetter() {
+	if qrs.deadLetter != nil {
+		if err := qrs.deadLetter.shutdown(context.Background()); err != nil {
+			qrs.logger.Error("Failed shutting down dead letter.", zap.Error(err))
+		}
+	}
+}
+
+// putDeadLetter stores the request which could not be sent, after exhausting retries, being interrupted by shutdown,
+// or being abandoned when draining the queue, in the dead letter sink and logs the outcome.
+// Returns false if the dead letter is not enabled, so the caller is responsible for handling the request.
+func (qrs *queuedRetrySender) putDeadLetter(logger *zap.Logger, req request, err error) bool {
+	if qrs.deadLetter == nil {
+		return false
+	}
+
+	data, marshalErr := req.Marshal()
+	if marshalErr == nil {
+		marshalErr = qrs.deadLetter.put(req.context(), data)
+	}
+	if marshalErr != nil {
+		logger.Error(
+			"Exporting failed. Failed storing data in dead letter. Dropping data.",
+			zap.Error(err),
+			zap.NamedError("dead_letter_error", marshalErr),
+			zap.Int("dropped_items", req.count()),
+		)
+		return true
+	}
+
+	logger.Error(
+		"Exporting failed. Storing data in dead letter.",
+		zap.Error(err),
+		zap.Int("dead_letter_items", req.count()),
+	)
+	return true
+}
+
+func (qrs *queuedRetrySender) replayDeadLetters(ctx context.Context) (int, error) {
+	if qrs.deadLetter == nil {
+		return 0, errDeadLetterDisabled
+	}
+
+	return qrs.deadLetter.replay(ctx, func(data []byte) error {
+		pReq, err := qrs.requestUnmarshaler(data)
+		if err != nil {
+			// The data can never be replayed, so it is removed instead of blocking the following requests.
+			qrs.logger.Error("Failed unmarshalling dead letter data. Dropping data.", zap.Error(err))
+			return nil
+		}
+		req := pReq.(request)
+		req.setContext(ctx)
+		return qrs.send(req)
+	})
+}
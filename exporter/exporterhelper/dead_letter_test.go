@@ -0,0 +1,434 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	uatomic "go.uber.org/atomic"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestDeadLetterSettings_Validate(t *testing.T) {
+	dlCfg := DeadLetterSettings{}
+	assert.NoError(t, dlCfg.Validate())
+
+	dlCfg.Enabled = true
+	assert.Equal(t, errDeadLetterNoDestination, dlCfg.Validate())
+
+	dlCfg.Directory = t.TempDir()
+	assert.NoError(t, dlCfg.Validate())
+
+	storageID := config.NewComponentID("storage")
+	dlCfg.StorageID = &storageID
+	assert.Equal(t, errDeadLetterMultipleDestinations, dlCfg.Validate())
+
+	dlCfg.Directory = ""
+	assert.NoError(t, dlCfg.Validate())
+}
+
+func TestDeadLetterSinks(t *testing.T) {
+	fileSink, err := newFileDeadLetterSink(t.TempDir(), config.NewComponentIDWithName("otlp", "1"), config.TracesDataType)
+	require.NoError(t, err)
+	storageSink, err := newStorageDeadLetterSink(context.Background(), newMockStorageClient())
+	require.NoError(t, err)
+
+	for name, sink := range map[string]deadLetterSink{"file": fileSink, "storage": storageSink} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, data := range []string{"a", "b", "c"} {
+				require.NoError(t, sink.put(ctx, []byte(data)))
+			}
+
+			var replayed []string
+			fail := errors.New("failed")
+			n, err := sink.replay(ctx, func(data []byte) error {
+				if string(data) == "b" {
+					return fail
+				}
+				replayed = append(replayed, string(data))
+				return nil
+			})
+			assert.Equal(t, fail, err)
+			assert.Equal(t, 1, n)
+			assert.Equal(t, []string{"a"}, replayed)
+
+			n, err = sink.replay(ctx, func(data []byte) error {
+				replayed = append(replayed, string(data))
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 2, n)
+			assert.Equal(t, []string{"a", "b", "c"}, replayed)
+
+			n, err = sink.replay(ctx, func(data []byte) error {
+				return fail
+			})
+			assert.NoError(t, err)
+			assert.Zero(t, n)
+			assert.NoError(t, sink.shutdown(ctx))
+		})
+	}
+}
+
+func TestStorageDeadLetterSink_Restart(t *testing.T) {
+	client := newMockStorageClient()
+	sink, err := newStorageDeadLetterSink(context.Background(), client)
+	require.NoError(t, err)
+	require.NoError(t, sink.put(context.Background(), []byte("a")))
+	require.NoError(t, sink.put(context.Background(), []byte("b")))
+
+	restarted, err := newStorageDeadLetterSink(context.Background(), client)
+	require.NoError(t, err)
+	var replayed []string
+	n, err := restarted.replay(context.Background(), func(data []byte) error {
+		replayed = append(replayed, string(data))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"a", "b"}, replayed)
+}
+
+func TestDeadLetter_StorageNotFound(t *testing.T) {
+	rCfg := NewDefaultRetrySettings()
+	storageID := config.NewComponentID("storage")
+	rCfg.DeadLetter = DeadLetterSettings{Enabled: true, StorageID: &storageID}
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), componenttest.NewNopHost()), errDeadLetterStorageNotFound)
+}
+
+func TestDeadLetter_StoreAndReplay(t *testing.T) {
+	storageID := config.NewComponentID("storage")
+	host := &storageHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		storageID: &mockStorageExtension{client: newMockStorageClient()},
+	}}
+
+	for name, dlCfg := range map[string]DeadLetterSettings{
+		"file":    {Enabled: true, Directory: t.TempDir()},
+		"storage": {Enabled: true, StorageID: &storageID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var backendDown = true
+			var received []pdata.Traces
+			pusher := func(_ context.Context, td pdata.Traces) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if backendDown {
+					return errors.New("backend down")
+				}
+				received = append(received, td)
+				return nil
+			}
+
+			rCfg := NewDefaultRetrySettings()
+			rCfg.InitialInterval = time.Millisecond
+			rCfg.MaxElapsedTime = 10 * time.Millisecond
+			rCfg.DeadLetter = dlCfg
+			te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithRetry(rCfg))
+			require.NoError(t, err)
+			require.NoError(t, te.Start(context.Background(), host))
+			t.Cleanup(func() {
+				assert.NoError(t, te.Shutdown(context.Background()))
+			})
+
+			td := testdata.GenerateTracesTwoSpansSameResource()
+			assert.Error(t, te.ConsumeTraces(context.Background(), td))
+
+			mu.Lock()
+			backendDown = false
+			mu.Unlock()
+
+			replayer, ok := te.(DeadLetterReplayer)
+			require.True(t, ok)
+			n, err := replayer.ReplayDeadLetters(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			require.Len(t, received, 1)
+			assert.Equal(t, td, received[0])
+
+			// Nothing is left to replay.
+			n, err = replayer.ReplayDeadLetters(context.Background())
+			require.NoError(t, err)
+			assert.Zero(t, n)
+		})
+	}
+}
+
+func TestDeadLetter_ReplayOnStart(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newFileDeadLetterSink(dir, fakeTracesExporterName, config.TracesDataType)
+	require.NoError(t, err)
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	data, err := newTracesRequest(context.Background(), td, nil).Marshal()
+	require.NoError(t, err)
+	require.NoError(t, sink.put(context.Background(), data))
+
+	release := make(chan struct{})
+	received := make(chan pdata.Traces, 1)
+	pusher := func(_ context.Context, td pdata.Traces) error {
+		<-release
+		received <- td
+		return nil
+	}
+	rCfg := NewDefaultRetrySettings()
+	rCfg.DeadLetter = DeadLetterSettings{Enabled: true, Directory: dir, ReplayOnStart: true}
+	qCfg := NewDefaultQueueSettings()
+	qCfg.Enabled = false
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithRetry(rCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+
+	// Starting is not blocked by sending the replayed data.
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+	close(release)
+	select {
+	case got := <-received:
+		assert.Equal(t, td, got)
+	case <-time.After(time.Second):
+		assert.Fail(t, "dead letter data was not replayed")
+	}
+	assert.NoError(t, te.Shutdown(context.Background()))
+}
+
+func TestDeadLetter_ConcurrentReplays(t *testing.T) {
+	storageID := config.NewComponentID("storage")
+	host := &storageHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		storageID: &mockStorageExtension{client: newMockStorageClient()},
+	}}
+
+	for name, dlCfg := range map[string]DeadLetterSettings{
+		"file":    {Enabled: true, Directory: t.TempDir()},
+		"storage": {Enabled: true, StorageID: &storageID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			backendDown := uatomic.NewBool(true)
+			received := uatomic.NewInt64(0)
+			pusher := func(_ context.Context, td pdata.Traces) error {
+				if backendDown.Load() {
+					return errors.New("backend down")
+				}
+				// Let the other replay run while the batch is being sent.
+				time.Sleep(time.Millisecond)
+				received.Inc()
+				return nil
+			}
+
+			rCfg := NewDefaultRetrySettings()
+			rCfg.MaxElapsedTime = time.Nanosecond
+			rCfg.DeadLetter = dlCfg
+			qCfg := NewDefaultQueueSettings()
+			qCfg.Enabled = false
+			te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithRetry(rCfg), WithQueue(qCfg))
+			require.NoError(t, err)
+			require.NoError(t, te.Start(context.Background(), host))
+			t.Cleanup(func() {
+				assert.NoError(t, te.Shutdown(context.Background()))
+			})
+
+			const numBatches = 10
+			for i := 0; i < numBatches; i++ {
+				assert.Error(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+			}
+			backendDown.Store(false)
+
+			var wg sync.WaitGroup
+			replayed := uatomic.NewInt64(0)
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					n, err := te.(DeadLetterReplayer).ReplayDeadLetters(context.Background())
+					assert.NoError(t, err)
+					replayed.Add(int64(n))
+				}()
+			}
+			wg.Wait()
+
+			// Every batch is sent once.
+			assert.EqualValues(t, numBatches, replayed.Load())
+			assert.EqualValues(t, numBatches, received.Load())
+		})
+	}
+}
+
+// replayDeadLetterDir replays the data stored in the dead letter directory by a traces exporter.
+func replayDeadLetterDir(t *testing.T, dir string) []pdata.Traces {
+	var received []pdata.Traces
+	pusher := func(_ context.Context, td pdata.Traces) error {
+		received = append(received, td)
+		return nil
+	}
+	rCfg := NewDefaultRetrySettings()
+	rCfg.DeadLetter = DeadLetterSettings{Enabled: true, Directory: dir}
+	qCfg := NewDefaultQueueSettings()
+	qCfg.Enabled = false
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithRetry(rCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+	_, err = te.(DeadLetterReplayer).ReplayDeadLetters(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, te.Shutdown(context.Background()))
+	return received
+}
+
+func TestDeadLetter_InterruptedByShutdown(t *testing.T) {
+	dir := t.TempDir()
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Traces) error {
+		attempts.Inc()
+		return errors.New("backend down")
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Hour
+	rCfg.DeadLetter = DeadLetterSettings{Enabled: true, Directory: dir}
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithRetry(rCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	require.NoError(t, te.ConsumeTraces(context.Background(), td))
+	assert.Eventually(t, func() bool {
+		return attempts.Load() == 1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	assert.Equal(t, []pdata.Traces{td}, replayDeadLetterDir(t, dir))
+}
+
+func TestDeadLetter_DrainAbandoned(t *testing.T) {
+	dir := t.TempDir()
+	release := make(chan struct{})
+	pusher := func(context.Context, pdata.Traces) error {
+		<-release
+		return nil
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.DeadLetter = DeadLetterSettings{Enabled: true, Directory: dir}
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.DrainTimeout = 50 * time.Millisecond
+	be := newBaseExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), config.TracesDataType, newTraceRequestUnmarshalerFunc(pusher))
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The first traces are taken by the consumer, which is blocked until the drain timeout expires.
+	require.NoError(t, be.sender.send(newTracesRequest(context.Background(), testdata.GenerateTracesOneSpan(), pusher)))
+	assert.Eventually(t, func() bool {
+		return be.qrSender.queue.Size() == 0
+	}, time.Second, time.Millisecond)
+	abandoned := testdata.GenerateTracesTwoSpansSameResource()
+	require.NoError(t, be.sender.send(newTracesRequest(context.Background(), abandoned, pusher)))
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+		close(shutdownDone)
+	}()
+	assert.Eventually(t, func() bool {
+		return be.qrSender.drainer.abandoning.Load()
+	}, time.Second, time.Millisecond)
+	close(release)
+	<-shutdownDone
+
+	assert.Equal(t, []pdata.Traces{abandoned}, replayDeadLetterDir(t, dir))
+}
+
+func TestDeadLetter_ReplayDisabled(t *testing.T) {
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil))
+	require.NoError(t, err)
+	_, err = te.(DeadLetterReplayer).ReplayDeadLetters(context.Background())
+	assert.Equal(t, errDeadLetterDisabled, err)
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (sh *storageHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return sh.extensions
+}
+
+type mockStorageExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	client storage.Client
+}
+
+func (mse *mockStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return mse.client, nil
+}
+
+type mockStorageClient struct {
+	st  map[string][]byte
+	mux sync.Mutex
+}
+
+func newMockStorageClient() *mockStorageClient {
+	return &mockStorageClient{st: map[string][]byte{}}
+}
+
+func (m *mockStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := m.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (m *mockStorageClient) Set(ctx context.Context, key string, value []byte) error {
+	return m.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (m *mockStorageClient) Delete(ctx context.Context, key string) error {
+	return m.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (m *mockStorageClient) Close(context.Context) error {
+	return nil
+}
+
+func (m *mockStorageClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = m.st[op.Key]
+		case storage.Set:
+			m.st[op.Key] = op.Value
+		case storage.Delete:
+			delete(m.st, op.Key)
+		default:
+			return errors.New("wrong operation type")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/metric/metricdata"
+	uatomic "go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+)
+
+// drainPollInterval is the interval at which the queue is checked for being empty while draining.
+var drainPollInterval = 10 * time.Millisecond
+
+const (
+	drainOutcomeFlushed   = "flushed"
+	drainOutcomeAbandoned = "abandoned"
+)
+
+// queueDrainer keeps the queue consumers sending the queued requests on shutdown, until the queue is empty
+// or the drain timeout expires, after which the remaining requests are abandoned without being sent.
+type queueDrainer struct {
+	timeout     time.Duration
+	labelValues []metricdata.LabelValue
+	logger      *zap.Logger
+	// onAbandoned is called for every request abandoned without being sent.
+	onAbandoned func(req request)
+
+	// inFlight is the number of requests taken from the queue and not finished yet.
+	inFlight   *uatomic.Int64
+	draining   *uatomic.Bool
+	abandoning *uatomic.Bool
+	flushed    *uatomic.Int64
+	abandoned  *uatomic.Int64
+}
+
+func newQueueDrainer(timeout time.Duration, labelValues []metricdata.LabelValue, logger *zap.Logger, onAbandoned func(req request)) *queueDrainer {
+	return &queueDrainer{
+		timeout:     timeout,
+		labelValues: labelValues,
+		logger:      logger,
+		onAbandoned: onAbandoned,
+		inFlight:    uatomic.NewInt64(0),
+		draining:    uatomic.NewBool(false),
+		abandoning:  uatomic.NewBool(false),
+		flushed:     uatomic.NewInt64(0),
+		abandoned:   uatomic.NewInt64(0),
+	}
+}
+
+// consume sends the request using send, unless the drain timeout expired. While draining,
+// the items of the request are accounted as flushed or abandoned.
+func (qd *queueDrainer) consume(req request, send func(req request) error) {
+	qd.inFlight.Inc()
+	defer qd.inFlight.Dec()
+
+	if qd.abandoning.Load() {
+		qd.record(drainOutcomeAbandoned, qd.abandoned, req.count())
+		qd.onAbandoned(req)
+		return
+	}
+	err := send(req)
+	if !qd.draining.Load() {
+		return
+	}
+	if err != nil {
+		qd.record(drainOutcomeAbandoned, qd.abandoned, req.count())
+	} else {
+		qd.record(drainOutcomeFlushed, qd.flushed, req.count())
+	}
+}
+
+func (qd *queueDrainer) record(outcome string, counter *uatomic.Int64, items int) {
+	counter.Add(int64(items))
+	entry, err := globalInstruments.queueDrainedItems.GetEntry(
+		append(append([]metricdata.LabelValue{}, qd.labelValues...), metricdata.NewLabelValue(outcome))...)
+	if err == nil {
+		entry.Inc(int64(items))
+	}
+}
+
+// begin starts accounting the sent requests as drained, it returns false if draining is disabled.
+// It must be called before stopping the retry sender, so the requests interrupted are accounted.
+func (qd *queueDrainer) begin() bool {
+	if qd.timeout <= 0 {
+		return false
+	}
+	qd.draining.Store(true)
+	return true
+}
+
+// drain blocks until the queue is empty and no request is being sent, or the drain timeout expires, or the shutdown
+// context is done. The retry sender must be stopped before, so the requests are tried at most once. stopSending is
+// called when the drain timeout expires to unblock the requests waiting to be sent, the remaining requests are then
+// taken from the queue and abandoned. The requests still queued when the shutdown context is done are dropped.
+func (qd *queueDrainer) drain(ctx context.Context, queue internal.ProducerConsumerQueue, stopSending func()) {
+	deadline := time.NewTimer(qd.timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for queue.Size() > 0 || qd.inFlight.Load() > 0 {
+		select {
+		case <-ticker.C:
+			continue
+		case <-deadline.C:
+			qd.logger.Warn("Drain timeout expired, abandoning the remaining data in the sending queue.",
+				zap.Int("queued_batches", queue.Size()))
+		case <-ctx.Done():
+			qd.logger.Warn("Shutdown context done before the sending queue was drained, abandoning the remaining data.",
+				zap.Int("queued_batches", queue.Size()))
+		}
+		qd.abandoning.Store(true)
+		stopSending()
+		break
+	}
+	// Wait for the consumers to abandon the remaining requests, they are freed as soon as the sends in progress end.
+	for queue.Size() > 0 {
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			qd.logger.Warn("Shutdown context done, dropping the data remaining in the sending queue.",
+				zap.Int("dropped_batches", queue.Size()),
+				zap.Int64("abandoned_items", qd.abandoned.Load()))
+			return
+		}
+	}
+
+	qd.logger.Info("Sending queue drained.",
+		zap.Int64("flushed_items", qd.flushed.Load()),
+		zap.Int64("abandoned_items", qd.abandoned.Load()))
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+	uatomic "go.uber.org/atomic"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestQueueSettings_ValidateDrainTimeout(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.DrainTimeout = time.Second
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.DrainTimeout = -time.Second
+	assert.EqualError(t, qCfg.Validate(), "drain timeout must not be negative")
+}
+
+func drainedTags(name, outcome string) []tag.Tag {
+	outcomeTag, _ := tag.NewKey("outcome")
+	return []tag.Tag{{Key: exporterTag, Value: "test/" + name}, {Key: signalTag, Value: ""}, {Key: outcomeTag, Value: outcome}}
+}
+
+func newDrainTestExporter(t *testing.T, name string, drainTimeout time.Duration, rCfg RetrySettings) *baseExporter {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.DrainTimeout = drainTimeout
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", name))
+	be := newBaseExporter(&cfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg), WithRetry(rCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	return be
+}
+
+func TestQueuedRetry_DrainOnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	received := uatomic.NewInt64(0)
+	pusher := func(_ context.Context, ld pdata.Logs) error {
+		<-release
+		received.Add(int64(ld.LogRecordCount()))
+		return nil
+	}
+
+	be := newDrainTestExporter(t, "drain", 5*time.Second, NewDefaultRetrySettings())
+	flushed := valueForGlobalManager(drainedTags("drain", drainOutcomeFlushed), "exporter/queue_drained_items")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(2), pusher)))
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+		close(shutdownDone)
+	}()
+	assert.Eventually(t, func() bool {
+		return be.qrSender.drainer.draining.Load()
+	}, time.Second, time.Millisecond)
+	close(release)
+	<-shutdownDone
+
+	assert.EqualValues(t, 6, received.Load())
+	checkValueForGlobalManager(t, drainedTags("drain", drainOutcomeFlushed), flushed+6, "exporter/queue_drained_items")
+}
+
+func TestQueuedRetry_DrainTimeout(t *testing.T) {
+	release := make(chan struct{})
+	received := uatomic.NewInt64(0)
+	pusher := func(_ context.Context, ld pdata.Logs) error {
+		<-release
+		received.Add(int64(ld.LogRecordCount()))
+		return nil
+	}
+
+	be := newDrainTestExporter(t, "drain_timeout", 50*time.Millisecond, NewDefaultRetrySettings())
+	flushed := valueForGlobalManager(drainedTags("drain_timeout", drainOutcomeFlushed), "exporter/queue_drained_items")
+	abandoned := valueForGlobalManager(drainedTags("drain_timeout", drainOutcomeAbandoned), "exporter/queue_drained_items")
+	// The first logs are taken by the consumer, which is blocked until the drain timeout expires.
+	require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(1), pusher)))
+	assert.Eventually(t, func() bool {
+		return be.qrSender.queue.Size() == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(2), pusher)))
+	require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(3), pusher)))
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+		close(shutdownDone)
+	}()
+	assert.Eventually(t, func() bool {
+		return be.qrSender.drainer.abandoning.Load()
+	}, time.Second, time.Millisecond)
+	close(release)
+	<-shutdownDone
+
+	// The logs being sent when the timeout expired are flushed, the queued ones are abandoned.
+	assert.EqualValues(t, 1, received.Load())
+	checkValueForGlobalManager(t, drainedTags("drain_timeout", drainOutcomeFlushed), flushed+1, "exporter/queue_drained_items")
+	checkValueForGlobalManager(t, drainedTags("drain_timeout", drainOutcomeAbandoned), abandoned+5, "exporter/queue_drained_items")
+}
+
+func TestQueuedRetry_DrainWithoutBackoff(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		return errors.New("transient error")
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Hour
+	rCfg.MaxInterval = time.Hour
+	rCfg.MaxElapsedTime = 0
+	be := newDrainTestExporter(t, "drain_no_backoff", time.Hour, rCfg)
+	abandoned := valueForGlobalManager(drainedTags("drain_no_backoff", drainOutcomeAbandoned), "exporter/queue_drained_items")
+	// The first logs wait for the retry backoff, the others stay queued until shutdown.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(1), pusher)))
+	}
+	assert.Eventually(t, func() bool {
+		return attempts.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, be.Shutdown(context.Background()))
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.EqualValues(t, 3, attempts.Load())
+	checkValueForGlobalManager(t, drainedTags("drain_no_backoff", drainOutcomeAbandoned), abandoned+3, "exporter/queue_drained_items")
+}
+
+func TestQueuedRetry_DrainShutdownContextDone(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		time.Sleep(20 * time.Millisecond)
+		return errors.New("backend down")
+	}
+
+	be := newDrainTestExporter(t, "drain_shutdown_context", time.Hour, NewDefaultRetrySettings())
+	for i := 0; i < 20; i++ {
+		require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(1), pusher)))
+	}
+
+	// The drain stops with the shutdown context instead of sending the queued logs until the drain timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, be.Shutdown(ctx))
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+	assert.True(t, be.qrSender.drainer.abandoning.Load())
+	assert.Less(t, attempts.Load(), int64(20))
+	assert.Positive(t, be.qrSender.drainer.abandoned.Load())
+}
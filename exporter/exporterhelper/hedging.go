@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+// HedgingSettings defines configuration for hedged requests: when an attempt to send data gets no response after
+// the usual latency of the backend, a duplicate is sent and the first successful response is used, which cuts the
+// tail latency when some backend instances are slow. The exporter must not modify the data it sends.
+type HedgingSettings struct {
+	// Enabled indicates whether to send hedged requests.
+	Enabled bool `mapstructure:"enabled"`
+	// Quantile of the latencies of the recent successful requests after which a hedged request is sent.
+	Quantile float64 `mapstructure:"quantile"`
+	// InitialDelay is the delay after which a hedged request is sent until enough latencies are observed.
+	InitialDelay time.Duration `mapstructure:"initial_delay"`
+	// MaxHedgedRequests is the maximum number of hedged requests sent in addition to the original one.
+	MaxHedgedRequests int `mapstructure:"max_hedged_requests"`
+}
+
+// NewDefaultHedgingSettings returns the default settings for HedgingSettings.
+func NewDefaultHedgingSettings() HedgingSettings {
+	return HedgingSettings{
+		Enabled:           false,
+		Quantile:          0.95,
+		InitialDelay:      time.Second,
+		MaxHedgedRequests: 1,
+	}
+}
+
+// Validate checks if the HedgingSettings configuration is valid
+func (hCfg *HedgingSettings) Validate() error {
+	if !hCfg.Enabled {
+		return nil
+	}
+
+	if hCfg.Quantile <= 0 || hCfg.Quantile > 1 {
+		return errors.New("quantile must be in the range (0, 1]")
+	}
+
+	if hCfg.InitialDelay <= 0 {
+		return errors.New("initial delay must be positive")
+	}
+
+	if hCfg.MaxHedgedRequests < 1 {
+		return errors.New("max hedged requests must be at least 1")
+	}
+
+	return nil
+}
+
+const (
+	// hedgingLatencyWindow is the number of recent latencies the hedging delay is computed from.
+	hedgingLatencyWindow = 100
+	// hedgingMinLatencies is the number of latencies observed before the hedging delay is computed from them.
+	hedgingMinLatencies = 10
+)
+
+// hedgePermits are the permits of the concurrency limiter, one is needed by every duplicate of a request.
+type hedgePermits interface {
+	// tryAcquire acquires a permit if one is available without waiting.
+	tryAcquire() bool
+	release()
+}
+
+// hedgeTokens are the tokens of the rate limiter, taken by every duplicate of a request.
+type hedgeTokens interface {
+	// tryTake takes the tokens needed to send the request if they are available without waiting.
+	tryTake(req request) bool
+}
+
+// hedgingSender is a requestSender sending every attempt of a request, and sending it again each time the hedging
+// delay expires without a response. It is placed after the rate limiter, and the original request already holds
+// a permit of the concurrency limiter, so the hedging delay only starts once the attempt is actually sent, and
+// local throttling never triggers a duplicate. Every duplicate needs a permit of the concurrency limiter and the
+// tokens of the rate limiter, if any, without waiting for them, and goes through the next senders, so it is limited
+// by the timeout.
+type hedgingSender struct {
+	cfg        HedgingSettings
+	permits    hedgePermits
+	tokens     hedgeTokens
+	nextSender requestSender
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newHedgingSender(cfg HedgingSettings, permits hedgePermits, tokens hedgeTokens, nextSender requestSender) *hedgingSender {
+	return &hedgingSender{
+		cfg:        cfg,
+		permits:    permits,
+		tokens:     tokens,
+		nextSender: nextSender,
+		latencies:  make([]time.Duration, 0, hedgingLatencyWindow),
+	}
+}
+
+// hedgedRequest is a request sent with its own context, canceled once a response of another export is used.
+// The senders handling the duplicates of a request concurrently must not change the shared request.
+type hedgedRequest struct {
+	request
+	ctx context.Context
+}
+
+func (hr *hedgedRequest) context() context.Context {
+	return hr.ctx
+}
+
+func (hr *hedgedRequest) setContext(ctx context.Context) {
+	hr.ctx = ctx
+}
+
+// send implements the requestSender interface
+func (hs *hedgingSender) send(req request) error {
+	// Cancel the exports still in flight once a response is used.
+	ctx, cancel := context.WithCancel(req.context())
+	defer cancel()
+
+	start := time.Now()
+	// The channel is large enough for all the exports, so the ones finishing late never block.
+	results := make(chan error, hs.cfg.MaxHedgedRequests+1)
+	export := func(release func()) {
+		if release != nil {
+			defer release()
+		}
+		results <- hs.nextSender.send(&hedgedRequest{request: req, ctx: ctx})
+	}
+	go export(nil)
+	sent, pending := 1, 1
+
+	timer := time.NewTimer(hs.delay())
+	defer timer.Stop()
+	var lastErr error
+	for {
+		select {
+		case err := <-results:
+			pending--
+			if err == nil {
+				hs.recordLatency(time.Since(start))
+				return nil
+			}
+			lastErr = err
+			// A permanent error would be returned for the duplicates too. When no export is in flight, the attempt
+			// fails instead of waiting for the next hedge, the retry handles the backoff.
+			if consumererror.IsPermanent(err) || pending == 0 {
+				return err
+			}
+		case <-timer.C:
+			if sent > hs.cfg.MaxHedgedRequests {
+				continue
+			}
+			var release func()
+			if hs.permits != nil {
+				if !hs.permits.tryAcquire() {
+					// All the permits are used, the duplicate is sent later if there is still no response.
+					timer.Reset(hs.delay())
+					continue
+				}
+				release = hs.permits.release
+			}
+			if hs.tokens != nil && !hs.tokens.tryTake(req) {
+				// The rate limit is reached, the duplicate is sent later if there is still no response.
+				if release != nil {
+					release()
+				}
+				timer.Reset(hs.delay())
+				continue
+			}
+			go export(release)
+			sent++
+			pending++
+			timer.Reset(hs.delay())
+		case <-req.context().Done():
+			if lastErr != nil {
+				return lastErr
+			}
+			return req.context().Err()
+		}
+	}
+}
+
+// delay returns the configured quantile of the recent latencies, or the initial delay if too few are known.
+func (hs *hedgingSender) delay() time.Duration {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(hs.latencies) < hedgingMinLatencies {
+		return hs.cfg.InitialDelay
+	}
+	sorted := make([]time.Duration, len(hs.latencies))
+	copy(sorted, hs.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(hs.cfg.Quantile*float64(len(sorted))+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+// recordLatency adds the latency of a successful request to the window, replacing the oldest one when it is full.
+func (hs *hedgingSender) recordLatency(latency time.Duration) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(hs.latencies) < hedgingLatencyWindow {
+		hs.latencies = append(hs.latencies, latency)
+		return
+	}
+	hs.latencies[hs.next] = latency
+	hs.next = (hs.next + 1) % hedgingLatencyWindow
+}
@@ -0,0 +1,320 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	uatomic "go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestHedgingSettings_Validate(t *testing.T) {
+	hCfg := NewDefaultHedgingSettings()
+	assert.NoError(t, hCfg.Validate())
+
+	hCfg.Enabled = true
+	assert.NoError(t, hCfg.Validate())
+
+	hCfg.Quantile = 0
+	assert.EqualError(t, hCfg.Validate(), "quantile must be in the range (0, 1]")
+	hCfg.Quantile = 1.5
+	assert.EqualError(t, hCfg.Validate(), "quantile must be in the range (0, 1]")
+
+	hCfg = NewDefaultHedgingSettings()
+	hCfg.Enabled = true
+	hCfg.InitialDelay = 0
+	assert.EqualError(t, hCfg.Validate(), "initial delay must be positive")
+
+	hCfg = NewDefaultHedgingSettings()
+	hCfg.Enabled = true
+	hCfg.MaxHedgedRequests = 0
+	assert.EqualError(t, hCfg.Validate(), "max hedged requests must be at least 1")
+}
+
+func TestHedgingSender_Delay(t *testing.T) {
+	hs := newHedgingSender(NewDefaultHedgingSettings(), nil, nil, &timeoutSender{})
+	for i := 1; i < hedgingMinLatencies; i++ {
+		hs.recordLatency(time.Millisecond)
+	}
+	// The initial delay is used until enough latencies are known.
+	assert.Equal(t, time.Second, hs.delay())
+
+	for i := hedgingMinLatencies; i <= hedgingLatencyWindow; i++ {
+		hs.recordLatency(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 95*time.Millisecond, hs.delay())
+
+	// The oldest latencies are replaced by the new ones.
+	for i := 0; i < hedgingLatencyWindow; i++ {
+		hs.recordLatency(time.Second)
+	}
+	assert.Equal(t, time.Second, hs.delay())
+}
+
+func newHedgingTestSender(maxHedgedRequests int, timeout time.Duration) *hedgingSender {
+	hCfg := NewDefaultHedgingSettings()
+	hCfg.Enabled = true
+	hCfg.InitialDelay = 10 * time.Millisecond
+	hCfg.MaxHedgedRequests = maxHedgedRequests
+	return newHedgingSender(hCfg, nil, nil, &timeoutSender{cfg: TimeoutSettings{Timeout: timeout}})
+}
+
+func TestHedgingSender_FirstSuccessWins(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	cancelled := make(chan struct{})
+	pusher := func(ctx context.Context, _ pdata.Logs) error {
+		if attempts.Inc() == 1 {
+			// The slow export is cancelled once the hedged one succeeds.
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	hs := newHedgingTestSender(1, 0)
+	assert.NoError(t, hs.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	<-cancelled
+	assert.EqualValues(t, 2, attempts.Load())
+	assert.Len(t, hs.latencies, 1)
+}
+
+func TestHedgingSender_MaxHedgedRequests(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(ctx context.Context, _ pdata.Logs) error {
+		attempts.Inc()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	hs := newHedgingTestSender(2, 200*time.Millisecond)
+	err := hs.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.EqualValues(t, 3, attempts.Load())
+	assert.Empty(t, hs.latencies)
+}
+
+func TestHedgingSender_FailsWithoutPendingExports(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		return errors.New("transient error")
+	}
+
+	hs := newHedgingTestSender(1, 0)
+	assert.EqualError(t, hs.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)), "transient error")
+	assert.EqualValues(t, 1, attempts.Load())
+}
+
+func TestHedgingSender_PermanentError(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	release := make(chan struct{})
+	pusher := func(ctx context.Context, _ pdata.Logs) error {
+		if attempts.Inc() == 1 {
+			<-release
+			return consumererror.NewPermanent(errors.New("bad data"))
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	hs := newHedgingTestSender(1, 0)
+	go func() {
+		assert.Eventually(t, func() bool { return attempts.Load() == 2 }, time.Second, time.Millisecond)
+		close(release)
+	}()
+	// The permanent error is returned without waiting for the hedged export.
+	err := hs.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher))
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+func TestHedgingSender_WithExporter(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(ctx context.Context, _ pdata.Logs) error {
+		if attempts.Inc() == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	hCfg := NewDefaultHedgingSettings()
+	hCfg.Enabled = true
+	hCfg.InitialDelay = 10 * time.Millisecond
+	qCfg := NewDefaultQueueSettings()
+	qCfg.Enabled = false
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithHedging(hCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	// The first export is stuck until the default timeout of 5s, the hedged one succeeds.
+	start := time.Now()
+	assert.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.EqualValues(t, 2, attempts.Load())
+}
+
+type testHedgePermits struct {
+	available *uatomic.Int64
+	acquired  *uatomic.Int64
+	released  *uatomic.Int64
+}
+
+func newTestHedgePermits(available int64) *testHedgePermits {
+	return &testHedgePermits{available: uatomic.NewInt64(available), acquired: uatomic.NewInt64(0), released: uatomic.NewInt64(0)}
+}
+
+func (p *testHedgePermits) tryAcquire() bool {
+	if p.available.Dec() < 0 {
+		p.available.Inc()
+		return false
+	}
+	p.acquired.Inc()
+	return true
+}
+
+func (p *testHedgePermits) release() {
+	p.available.Inc()
+	p.released.Inc()
+}
+
+func TestHedgingSender_ConcurrencyPermits(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(ctx context.Context, _ pdata.Logs) error {
+		attempts.Inc()
+		select {
+		case <-ctx.Done():
+		case <-time.After(50 * time.Millisecond):
+		}
+		return nil
+	}
+	hCfg := NewDefaultHedgingSettings()
+	hCfg.Enabled = true
+	hCfg.InitialDelay = 10 * time.Millisecond
+
+	// No duplicate is sent without a permit.
+	permits := newTestHedgePermits(0)
+	hs := newHedgingSender(hCfg, permits, nil, &timeoutSender{})
+	assert.NoError(t, hs.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.EqualValues(t, 1, attempts.Load())
+	assert.Zero(t, permits.acquired.Load())
+
+	// The permit of the duplicate is released once it is done.
+	attempts.Store(0)
+	permits = newTestHedgePermits(1)
+	hs = newHedgingSender(hCfg, permits, nil, &timeoutSender{})
+	assert.NoError(t, hs.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.EqualValues(t, 2, attempts.Load())
+	assert.Eventually(t, func() bool { return permits.released.Load() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestHedgingSender_RateLimited(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+
+	hCfg := NewDefaultHedgingSettings()
+	hCfg.Enabled = true
+	hCfg.InitialDelay = 10 * time.Millisecond
+	qCfg := NewDefaultQueueSettings()
+	qCfg.Enabled = false
+	rlCfg := RateLimitSettings{Enabled: true, RequestsPerSecond: 5, RequestsBurst: 1}
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithHedging(hCfg), WithQueue(qCfg), WithRateLimit(rlCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	// No duplicate is sent while the rate limit is reached.
+	assert.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.EqualValues(t, 1, attempts.Load())
+
+	// The time spent waiting for the rate limit does not trigger a duplicate.
+	assert.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.EqualValues(t, 2, attempts.Load())
+}
+
+func TestRetrySender_RequestTimeout(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		return errors.New("transient error")
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = 10 * time.Millisecond
+	rCfg.MaxInterval = 10 * time.Millisecond
+	qCfg := NewDefaultQueueSettings()
+	qCfg.Enabled = false
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg), WithTimeout(TimeoutSettings{RequestTimeout: 100 * time.Millisecond})), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	start := time.Now()
+	req := newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)
+	err := be.sender.send(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request timeout expired")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Greater(t, attempts.Load(), int64(1))
+
+	// The request gets back its own context, without the deadline.
+	_, hasDeadline := req.context().Deadline()
+	assert.False(t, hasDeadline)
+}
+
+type requeuedContextKey struct{}
+
+func TestRetrySender_RequestTimeoutRequeued(t *testing.T) {
+	rCfg := NewDefaultRetrySettings()
+	rCfg.MaxElapsedTime = time.Nanosecond
+	// The requeued request is owned by the queue or the batcher, which may set its context at any time.
+	requeuedCtx := context.WithValue(context.Background(), requeuedContextKey{}, true)
+	rs := &retrySender{
+		cfg:            rCfg,
+		requestTimeout: time.Second,
+		nextSender:     &timeoutSender{},
+		stopCh:         make(chan struct{}),
+		logger:         zap.NewNop(),
+		onTemporaryFailure: func(_ *zap.Logger, req request, err error) error {
+			_, hasDeadline := req.context().Deadline()
+			assert.False(t, hasDeadline)
+			req.setContext(requeuedCtx)
+			return err
+		},
+	}
+
+	req := newErrorRequest(context.Background())
+	require.Error(t, rs.send(req))
+	assert.Equal(t, requeuedCtx, req.context())
+}
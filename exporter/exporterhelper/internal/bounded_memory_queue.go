@@ -17,6 +17,7 @@
 package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 
 import (
+	"context"
 	"sync"
 
 	uatomic "go.uber.org/atomic"
@@ -32,22 +33,41 @@ type boundedMemoryQueue struct {
 	stopWG        sync.WaitGroup
 	size          *uatomic.Uint32
 	capacity      *uatomic.Uint32
+	sizeBytes     *uatomic.Int64
+	capacityBytes int64
+	sizer         ByteSizer
 	stopped       *uatomic.Uint32
-	items         *chan interface{}
+	items         *chan queueItem
 	onDroppedItem func(item interface{})
 	factory       func() consumer
 	stopCh        chan struct{}
+	overflow      *overflowHandler
+}
+
+// queueItem is an item stored in the boundedMemoryQueue along with its size in bytes,
+// so the size does not need to be calculated again when the item is consumed
+type queueItem struct {
+	item      interface{}
+	sizeBytes int64
 }
 
 // NewBoundedMemoryQueue constructs the new queue of specified capacity, and with an optional
-// callback for dropped items (e.g. useful to emit metrics).
-func NewBoundedMemoryQueue(capacity int, onDroppedItem func(item interface{})) ProducerConsumerQueue {
-	queue := make(chan interface{}, capacity)
+// callback for dropped items (e.g. useful to emit metrics). When capacityBytes is positive,
+// the queue is additionally bounded by the total size in bytes of the items it holds, as
+// reported by the sizer. The overflow settings define what to do with the items produced
+// while the queue is full.
+func NewBoundedMemoryQueue(capacity int, capacityBytes int64, sizer ByteSizer, overflow OverflowSettings, onDroppedItem func(item interface{})) ProducerConsumerQueue {
+	queue := make(chan queueItem, capacity)
+	stopCh := make(chan struct{})
 	return &boundedMemoryQueue{
 		onDroppedItem: onDroppedItem,
 		items:         &queue,
-		stopCh:        make(chan struct{}),
+		stopCh:        stopCh,
+		overflow:      newOverflowHandler(overflow, stopCh),
 		capacity:      uatomic.NewUint32(uint32(capacity)),
+		sizeBytes:     uatomic.NewInt64(0),
+		capacityBytes: capacityBytes,
+		sizer:         sizer,
 		stopped:       uatomic.NewUint32(0),
 		size:          uatomic.NewUint32(0),
 	}
@@ -72,10 +92,12 @@ func (q *boundedMemoryQueue) StartConsumers(num int, callback func(item interfac
 			queue := *q.items
 			for {
 				select {
-				case item, ok := <-queue:
+				case qi, ok := <-queue:
 					if ok {
 						q.size.Sub(1)
-						itemConsumer.consume(item)
+						q.sizeBytes.Sub(qi.sizeBytes)
+						q.overflow.notifySpace()
+						itemConsumer.consume(qi.item)
 					} else {
 						// channel closed, finish worker
 						return
@@ -100,32 +122,69 @@ func (c consumerFunc) consume(item interface{}) {
 }
 
 // Produce is used by the producer to submit new item to the queue. Returns false in case of queue overflow.
-func (q *boundedMemoryQueue) Produce(item interface{}) bool {
-	if q.stopped.Load() != 0 {
-		q.onDroppedItem(item)
+func (q *boundedMemoryQueue) Produce(ctx context.Context, item interface{}) bool {
+	// the size in bytes is only calculated when the queue is bounded by it, as it may be expensive
+	var sizeBytes int64
+	if q.capacityBytes > 0 {
+		sizeBytes = q.sizer.size(item)
+	}
+
+	if !q.overflow.produce(ctx, func() error { return q.tryProduce(item, sizeBytes) }, q.dropOldest) {
+		// note that all items will be dropped if the capacity is 0
+		if q.onDroppedItem != nil {
+			q.onDroppedItem(item)
+		}
 		return false
 	}
+	return true
+}
+
+func (q *boundedMemoryQueue) tryProduce(item interface{}, sizeBytes int64) error {
+	if q.stopped.Load() != 0 {
+		return errQueueStopped
+	}
+
+	// items which never fit are rejected before checking the room left, so no room is made for them
+	if q.capacityBytes > 0 && sizeBytes > q.capacityBytes {
+		return errItemTooLarge
+	}
 
 	// we might have two concurrent backing queues at the moment
 	// their combined size is stored in q.size, and their combined capacity
 	// should match the capacity of the new queue
 	if q.Size() >= q.Capacity() {
-		// note that all items will be dropped if the capacity is 0
-		q.onDroppedItem(item)
-		return false
+		return errQueueFull
+	}
+
+	if q.capacityBytes > 0 {
+		// reserve the bytes first, so concurrent producers cannot exceed the capacity together
+		if q.sizeBytes.Add(sizeBytes) > q.capacityBytes {
+			q.sizeBytes.Sub(sizeBytes)
+			return errQueueFull
+		}
 	}
 
 	q.size.Add(1)
 	select {
-	case *q.items <- item:
-		return true
+	case *q.items <- queueItem{item: item, sizeBytes: sizeBytes}:
+		return nil
 	default:
-		// should not happen, as overflows should have been captured earlier
+		// concurrent producers filled the queue after the size check
 		q.size.Sub(1)
-		if q.onDroppedItem != nil {
-			q.onDroppedItem(item)
-		}
-		return false
+		q.sizeBytes.Sub(sizeBytes)
+		return errQueueFull
+	}
+}
+
+// dropOldest removes the oldest item from the queue, returning false if the queue is empty.
+func (q *boundedMemoryQueue) dropOldest() (interface{}, bool) {
+	select {
+	case qi := <-*q.items:
+		q.size.Sub(1)
+		q.sizeBytes.Sub(qi.sizeBytes)
+		return qi.item, true
+	default:
+		return nil, false
 	}
 }
 
@@ -147,3 +206,13 @@ func (q *boundedMemoryQueue) Size() int {
 func (q *boundedMemoryQueue) Capacity() int {
 	return int(q.capacity.Load())
 }
+
+// SizeBytes returns the current size of the queue in bytes
+func (q *boundedMemoryQueue) SizeBytes() int64 {
+	return q.sizeBytes.Load()
+}
+
+// CapacityBytes returns capacity of the queue in bytes
+func (q *boundedMemoryQueue) CapacityBytes() int64 {
+	return q.capacityBytes
+}
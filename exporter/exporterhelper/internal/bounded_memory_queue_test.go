@@ -17,6 +17,8 @@
 package internal
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -31,7 +33,7 @@ import (
 // We want to test the overflow behavior, so we block the consumer
 // by holding a startLock before submitting items to the queue.
 func helper(t *testing.T, startConsumers func(q ProducerConsumerQueue, consumerFn func(item interface{}))) {
-	q := NewBoundedMemoryQueue(1, func(item interface{}) {})
+	q := NewBoundedMemoryQueue(1, 0, nil, OverflowSettings{}, func(item interface{}) {})
 	assert.Equal(t, 1, q.Capacity())
 
 	var startLock sync.Mutex
@@ -48,7 +50,7 @@ func helper(t *testing.T, startConsumers func(q ProducerConsumerQueue, consumerF
 		startLock.Unlock()
 	})
 
-	assert.True(t, q.Produce("a"))
+	assert.True(t, q.Produce(context.Background(), "a"))
 
 	// at this point "a" may or may not have been received by the consumer go-routine
 	// so let's make sure it has been
@@ -61,10 +63,10 @@ func helper(t *testing.T, startConsumers func(q ProducerConsumerQueue, consumerF
 	})
 
 	// produce two more items. The first one should be accepted, but not consumed.
-	assert.True(t, q.Produce("b"))
+	assert.True(t, q.Produce(context.Background(), "b"))
 	assert.Equal(t, 1, q.Size())
 	// the second should be rejected since the queue is full
-	assert.False(t, q.Produce("c"))
+	assert.False(t, q.Produce(context.Background(), "c"))
 	assert.Equal(t, 1, q.Size())
 
 	startLock.Unlock() // unblock consumer
@@ -80,13 +82,13 @@ func helper(t *testing.T, startConsumers func(q ProducerConsumerQueue, consumerF
 		"b": true,
 	}
 	for _, item := range []string{"d", "e", "f"} {
-		assert.True(t, q.Produce(item))
+		assert.True(t, q.Produce(context.Background(), item))
 		expected[item] = true
 		consumerState.assertConsumed(expected)
 	}
 
 	q.Stop()
-	assert.False(t, q.Produce("x"), "cannot push to closed queue")
+	assert.False(t, q.Produce(context.Background(), "x"), "cannot push to closed queue")
 }
 
 func TestBoundedQueue(t *testing.T) {
@@ -145,34 +147,74 @@ func (s *consumerState) assertConsumed(expected map[string]bool) {
 }
 
 func TestZeroSize(t *testing.T) {
-	q := NewBoundedMemoryQueue(0, func(item interface{}) {
+	q := NewBoundedMemoryQueue(0, 0, nil, OverflowSettings{}, func(item interface{}) {
 	})
 
 	q.StartConsumers(1, func(item interface{}) {
 	})
 
-	assert.False(t, q.Produce("a")) // in process
+	assert.False(t, q.Produce(context.Background(), "a")) // in process
+}
+
+type sizedItem int
+
+// testSizer returns the size of the sizedItems, the other items are accounted as zero bytes.
+func testSizer(item interface{}) int64 {
+	if i, ok := item.(sizedItem); ok {
+		return int64(i)
+	}
+	return 0
+}
+
+func TestBoundedQueueCapacityBytes(t *testing.T) {
+	q := NewBoundedMemoryQueue(10, 100, testSizer, OverflowSettings{}, func(item interface{}) {})
+	assert.EqualValues(t, 100, q.CapacityBytes())
+
+	assert.True(t, q.Produce(context.Background(), sizedItem(60)))
+	assert.False(t, q.Produce(context.Background(), sizedItem(50)))
+	assert.True(t, q.Produce(context.Background(), sizedItem(40)))
+	// items not sized by the ByteSizer are accounted as zero bytes
+	assert.True(t, q.Produce(context.Background(), "a"))
+	assert.EqualValues(t, 100, q.SizeBytes())
+	assert.Equal(t, 3, q.Size())
+
+	consumerState := newConsumerState(t)
+	q.StartConsumers(1, func(item interface{}) {
+		if si, ok := item.(sizedItem); ok {
+			consumerState.record(fmt.Sprint(int(si)))
+		}
+	})
+	consumerState.assertConsumed(map[string]bool{
+		"60": true,
+		"40": true,
+	})
+	assert.Eventually(t, func() bool {
+		return q.SizeBytes() == 0
+	}, time.Second, time.Millisecond)
+	assert.True(t, q.Produce(context.Background(), sizedItem(100)))
+
+	q.Stop()
 }
 
 func BenchmarkBoundedQueue(b *testing.B) {
-	q := NewBoundedMemoryQueue(1000, func(item interface{}) {
+	q := NewBoundedMemoryQueue(1000, 0, nil, OverflowSettings{}, func(item interface{}) {
 	})
 
 	q.StartConsumers(10, func(item interface{}) {
 	})
 
 	for n := 0; n < b.N; n++ {
-		q.Produce(n)
+		q.Produce(context.Background(), n)
 	}
 }
 
 func BenchmarkBoundedQueueWithFactory(b *testing.B) {
-	q := NewBoundedMemoryQueue(1000, func(item interface{}) {
+	q := NewBoundedMemoryQueue(1000, 0, nil, OverflowSettings{}, func(item interface{}) {
 	})
 
 	q.StartConsumers(10, func(item interface{}) {})
 
 	for n := 0; n < b.N; n++ {
-		q.Produce(n)
+		q.Produce(context.Background(), n)
 	}
 }
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy defines what a queue does with an item produced while the queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowRejectNew rejects the produced item.
+	OverflowRejectNew OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest items of the queue to make room for the produced item.
+	OverflowDropOldest
+	// OverflowBlock waits until there is room in the queue for the produced item, up to a timeout.
+	OverflowBlock
+)
+
+// OverflowSettings defines the behavior of a queue when it is full.
+type OverflowSettings struct {
+	Policy OverflowPolicy
+	// BlockTimeout is the maximum time to wait for room in the queue with the OverflowBlock policy.
+	BlockTimeout time.Duration
+	// OnEvicted is called with every item dropped to make room with the OverflowDropOldest policy.
+	OnEvicted func(item interface{})
+}
+
+var (
+	// errQueueFull is returned when an item cannot be added because the queue is full,
+	// as opposed to the following errors, returned for items which cannot be added even if room is made.
+	errQueueFull    = errors.New("queue is full")
+	errQueueStopped = errors.New("queue is stopped")
+	errItemTooLarge = errors.New("item is larger than the capacity in bytes of the queue")
+)
+
+// overflowHandler applies the OverflowSettings for the queue implementations.
+type overflowHandler struct {
+	cfg    OverflowSettings
+	stopCh <-chan struct{}
+
+	mu sync.Mutex
+	// spaceCh is closed and replaced every time room is made in the queue, waking up the blocked producers.
+	spaceCh chan struct{}
+}
+
+func newOverflowHandler(cfg OverflowSettings, stopCh <-chan struct{}) *overflowHandler {
+	return &overflowHandler{
+		cfg:     cfg,
+		stopCh:  stopCh,
+		spaceCh: make(chan struct{}),
+	}
+}
+
+// notifySpace must be called by the queue every time an item is taken from the queue.
+func (oh *overflowHandler) notifySpace() {
+	if oh.cfg.Policy != OverflowBlock {
+		return
+	}
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	close(oh.spaceCh)
+	oh.spaceCh = make(chan struct{})
+}
+
+func (oh *overflowHandler) waitSpace() <-chan struct{} {
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	return oh.spaceCh
+}
+
+// produce adds an item using tryProduce, which returns errQueueFull if the queue is full, applying the policy then.
+// dropOldest removes the oldest item of the queue, it returns false if there is none. With the OverflowBlock policy,
+// the wait for room is canceled with the context.
+func (oh *overflowHandler) produce(ctx context.Context, tryProduce func() error, dropOldest func() (interface{}, bool)) bool {
+	switch oh.cfg.Policy {
+	case OverflowDropOldest:
+		for {
+			err := tryProduce()
+			if err != errQueueFull {
+				return err == nil
+			}
+			evicted, ok := dropOldest()
+			if !ok {
+				return false
+			}
+			if evicted != nil && oh.cfg.OnEvicted != nil {
+				oh.cfg.OnEvicted(evicted)
+			}
+		}
+	case OverflowBlock:
+		timer := time.NewTimer(oh.cfg.BlockTimeout)
+		defer timer.Stop()
+		for {
+			// Get the channel before trying, so room made in between is not missed.
+			spaceCh := oh.waitSpace()
+			err := tryProduce()
+			if err != errQueueFull {
+				return err == nil
+			}
+			select {
+			case <-spaceCh:
+			case <-timer.C:
+				return false
+			case <-ctx.Done():
+				return false
+			case <-oh.stopCh:
+				return false
+			}
+		}
+	}
+	return tryProduce() == nil
+}
@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundedQueue_DropOldest(t *testing.T) {
+	var evicted []interface{}
+	var dropped []interface{}
+	q := NewBoundedMemoryQueue(2, 10, testSizer, OverflowSettings{
+		Policy:    OverflowDropOldest,
+		OnEvicted: func(item interface{}) { evicted = append(evicted, item) },
+	}, func(item interface{}) { dropped = append(dropped, item) })
+
+	assert.True(t, q.Produce(context.Background(), sizedItem(1)))
+	assert.True(t, q.Produce(context.Background(), sizedItem(2)))
+	assert.True(t, q.Produce(context.Background(), sizedItem(3)))
+	assert.Equal(t, []interface{}{sizedItem(1)}, evicted)
+	// Room is made in bytes as well
+	assert.True(t, q.Produce(context.Background(), sizedItem(7)))
+	assert.Equal(t, []interface{}{sizedItem(1), sizedItem(2)}, evicted)
+	assert.Equal(t, 2, q.Size())
+	assert.EqualValues(t, 10, q.SizeBytes())
+
+	// Items larger than the capacity do not empty the queue
+	assert.False(t, q.Produce(context.Background(), sizedItem(11)))
+	assert.Equal(t, []interface{}{sizedItem(11)}, dropped)
+	assert.Equal(t, 2, q.Size())
+
+	consumerState := newConsumerState(t)
+	q.StartConsumers(1, func(item interface{}) {
+		consumerState.record(fmt.Sprint(int(item.(sizedItem))))
+	})
+	consumerState.assertConsumed(map[string]bool{"3": true, "7": true})
+	q.Stop()
+}
+
+func TestBoundedQueue_DropOldestZeroSize(t *testing.T) {
+	var dropped int
+	q := NewBoundedMemoryQueue(0, 0, nil, OverflowSettings{Policy: OverflowDropOldest}, func(item interface{}) { dropped++ })
+	assert.False(t, q.Produce(context.Background(), sizedItem(1)))
+	assert.Equal(t, 1, dropped)
+}
+
+func TestBoundedQueue_Block(t *testing.T) {
+	var dropped int
+	q := NewBoundedMemoryQueue(1, 0, nil, OverflowSettings{Policy: OverflowBlock, BlockTimeout: 10 * time.Millisecond},
+		func(item interface{}) { dropped++ })
+
+	assert.True(t, q.Produce(context.Background(), sizedItem(1)))
+	// Times out while nothing is consumed
+	assert.False(t, q.Produce(context.Background(), sizedItem(2)))
+	assert.Equal(t, 1, dropped)
+
+	q = NewBoundedMemoryQueue(1, 0, nil, OverflowSettings{Policy: OverflowBlock, BlockTimeout: 5 * time.Second},
+		func(item interface{}) { dropped++ })
+	assert.True(t, q.Produce(context.Background(), sizedItem(1)))
+	produced := make(chan bool)
+	go func() { produced <- q.Produce(context.Background(), sizedItem(2)) }()
+
+	consumerState := newConsumerState(t)
+	q.StartConsumers(1, func(item interface{}) {
+		consumerState.record(fmt.Sprint(int(item.(sizedItem))))
+	})
+	require.True(t, <-produced)
+	consumerState.assertConsumed(map[string]bool{"1": true, "2": true})
+	q.Stop()
+}
+
+func TestBoundedQueue_BlockStopped(t *testing.T) {
+	q := NewBoundedMemoryQueue(1, 0, nil, OverflowSettings{Policy: OverflowBlock, BlockTimeout: time.Minute}, nil)
+	assert.True(t, q.Produce(context.Background(), sizedItem(1)))
+
+	produced := make(chan bool)
+	go func() { produced <- q.Produce(context.Background(), sizedItem(2)) }()
+	// Without consumers, the producer is released by stopping the queue
+	q.Stop()
+	assert.False(t, <-produced)
+}
+
+func TestBoundedQueue_BlockCanceled(t *testing.T) {
+	q := NewBoundedMemoryQueue(1, 0, nil, OverflowSettings{Policy: OverflowBlock, BlockTimeout: time.Minute}, nil)
+	assert.True(t, q.Produce(context.Background(), sizedItem(1)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	produced := make(chan bool)
+	go func() { produced <- q.Produce(ctx, sizedItem(2)) }()
+	// Without consumers, the producer is released by canceling its context
+	cancel()
+	assert.False(t, <-produced)
+	q.Stop()
+}
+
+func TestPriorityQueue_DropOldest(t *testing.T) {
+	var evicted []interface{}
+	pq := NewPriorityQueue([]PriorityLane{{Weight: 1, Capacity: 1}, {Weight: 1, Capacity: 2}}, 0, nil, OverflowSettings{
+		Policy:    OverflowDropOldest,
+		OnEvicted: func(item interface{}) { evicted = append(evicted, item) },
+	}, laneOf, func(interface{}, int) {})
+
+	assert.True(t, pq.Produce(context.Background(), "low-1"))
+	assert.True(t, pq.Produce(context.Background(), "low-2"))
+	assert.True(t, pq.Produce(context.Background(), "high-1"))
+	// The oldest item is dropped from the lane of the produced item
+	assert.True(t, pq.Produce(context.Background(), "high-2"))
+	assert.Equal(t, []interface{}{"high-1"}, evicted)
+	assert.True(t, pq.Produce(context.Background(), "low-3"))
+	assert.Equal(t, []interface{}{"high-1", "low-1"}, evicted)
+	assert.Equal(t, 1, pq.LaneSize(0))
+	assert.Equal(t, 2, pq.LaneSize(1))
+}
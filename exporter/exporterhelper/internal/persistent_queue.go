@@ -12,13 +12,11 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build enable_unstable
-// +build enable_unstable
-
 package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 
 import (
 	"context"
+	"errors"
 	"sync"
 
 	"go.uber.org/zap"
@@ -28,21 +26,33 @@ import (
 
 // persistentQueue holds the queue backed by file storage
 type persistentQueue struct {
-	logger     *zap.Logger
-	stopWG     sync.WaitGroup
-	stopOnce   sync.Once
-	stopChan   chan struct{}
-	numWorkers int
-	storage    persistentStorage
+	logger        *zap.Logger
+	stopWG        sync.WaitGroup
+	stopOnce      sync.Once
+	stopChan      chan struct{}
+	numWorkers    int
+	capacityBytes int64
+	storage       persistentStorage
+	overflow      *overflowHandler
 }
 
 // NewPersistentQueue creates a new queue backed by file storage; name parameter must be a unique value that identifies the queue
-func NewPersistentQueue(ctx context.Context, name string, capacity int, logger *zap.Logger, client storage.Client, unmarshaler RequestUnmarshaler) ProducerConsumerQueue {
-	return &persistentQueue{
-		logger:   logger,
-		stopChan: make(chan struct{}),
-		storage:  newPersistentContiguousStorage(ctx, name, uint64(capacity), logger, client, unmarshaler),
+// When capacityBytes is positive, the queue is additionally bounded by the total size of the serialized items it holds.
+// The overflow settings define what to do with the items produced while the queue is full.
+// The data in the storage is migrated to the current format version first, an error is returned if it is not possible.
+func NewPersistentQueue(ctx context.Context, name string, capacity int, capacityBytes int64, overflow OverflowSettings, logger *zap.Logger, client storage.Client, unmarshaler RequestUnmarshaler) (ProducerConsumerQueue, error) {
+	pcs, err := newPersistentContiguousStorage(ctx, name, uint64(capacity), capacityBytes, logger, client, unmarshaler)
+	if err != nil {
+		return nil, err
 	}
+	stopChan := make(chan struct{})
+	return &persistentQueue{
+		logger:        logger,
+		stopChan:      stopChan,
+		overflow:      newOverflowHandler(overflow, stopChan),
+		capacityBytes: capacityBytes,
+		storage:       pcs,
+	}, nil
 }
 
 // StartConsumers starts the given number of consumers which will be consuming items
@@ -61,6 +71,7 @@ func (pq *persistentQueue) StartConsumers(num int, callback func(item interface{
 			for {
 				select {
 				case req := <-pq.storage.get():
+					pq.overflow.notifySpace()
 					itemConsumer.consume(req)
 				case <-pq.stopChan:
 					return
@@ -71,9 +82,14 @@ func (pq *persistentQueue) StartConsumers(num int, callback func(item interface{
 }
 
 // Produce adds an item to the queue and returns true if it was accepted
-func (pq *persistentQueue) Produce(item interface{}) bool {
-	err := pq.storage.put(item.(PersistentRequest))
-	return err == nil
+func (pq *persistentQueue) Produce(ctx context.Context, item interface{}) bool {
+	return pq.overflow.produce(ctx, func() error {
+		err := pq.storage.put(item.(PersistentRequest))
+		if errors.Is(err, errMaxCapacityReached) || errors.Is(err, errMaxCapacityBytesReached) {
+			return errQueueFull
+		}
+		return err
+	}, pq.storage.dropOldest)
 }
 
 // Stop stops accepting items, shuts down the queue and closes the persistent queue
@@ -95,3 +111,13 @@ func (pq *persistentQueue) Size() int {
 func (pq *persistentQueue) Capacity() int {
 	return pq.Size() + 1
 }
+
+// SizeBytes returns the current size in bytes of the serialized items in the queue
+func (pq *persistentQueue) SizeBytes() int64 {
+	return pq.storage.sizeBytes()
+}
+
+// CapacityBytes returns the capacity in bytes of the persistent queue, zero if it is not bounded by size in bytes
+func (pq *persistentQueue) CapacityBytes() int64 {
+	return pq.capacityBytes
+}
@@ -12,15 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build enable_unstable
-// +build enable_unstable
-
 package internal
 
 import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -35,6 +33,10 @@ import (
 )
 
 func createTestQueue(extension storage.Extension, capacity int) *persistentQueue {
+	return createTestQueueWithOverflow(extension, capacity, OverflowSettings{})
+}
+
+func createTestQueueWithOverflow(extension storage.Extension, capacity int, overflow OverflowSettings) *persistentQueue {
 	logger := zap.NewNop()
 
 	client, err := extension.GetClient(context.Background(), component.KindReceiver, config.ComponentID{}, "")
@@ -42,7 +44,10 @@ func createTestQueue(extension storage.Extension, capacity int) *persistentQueue
 		panic(err)
 	}
 
-	wq := NewPersistentQueue(context.Background(), "foo", capacity, logger, client, newFakeTracesRequestUnmarshalerFunc())
+	wq, err := NewPersistentQueue(context.Background(), "foo", capacity, 0, overflow, logger, client, newFakeTracesRequestUnmarshalerFunc())
+	if err != nil {
+		panic(err)
+	}
 	return wq.(*persistentQueue)
 }
 
@@ -61,7 +66,7 @@ func TestPersistentQueue_Capacity(t *testing.T) {
 		req := newFakeTracesRequest(traces)
 
 		for i := 0; i < 10; i++ {
-			result := wq.Produce(req)
+			result := wq.Produce(context.Background(), req)
 			if i < 6 {
 				require.True(t, result)
 			} else {
@@ -80,6 +85,74 @@ func TestPersistentQueue_Capacity(t *testing.T) {
 	}
 }
 
+func TestPersistentQueue_DropOldest(t *testing.T) {
+	path := createTemporaryDirectory()
+	defer os.RemoveAll(path)
+
+	ext := createStorageExtension(path)
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+
+	var evicted []interface{}
+	wq := createTestQueueWithOverflow(ext, 2, OverflowSettings{
+		Policy:    OverflowDropOldest,
+		OnEvicted: func(item interface{}) { evicted = append(evicted, item) },
+	})
+
+	require.True(t, wq.Produce(context.Background(), newFakeTracesRequest(newTraces(1, 1))))
+	// Let's make sure the loop picks the first element into the channel, it is not dropped anymore
+	require.Eventually(t, func() bool {
+		return wq.Size() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for i := 2; i <= 4; i++ {
+		require.True(t, wq.Produce(context.Background(), newFakeTracesRequest(newTraces(1, i))))
+	}
+	require.Equal(t, 2, wq.Size())
+	require.Len(t, evicted, 1)
+	require.Equal(t, 2, evicted[0].(*fakeTracesRequest).td.SpanCount())
+
+	var consumed []int
+	var mu sync.Mutex
+	wq.StartConsumers(1, func(item interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed = append(consumed, item.(*fakeTracesRequest).td.SpanCount())
+	})
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(consumed) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int{1, 3, 4}, consumed)
+	wq.Stop()
+}
+
+func TestPersistentQueue_Block(t *testing.T) {
+	path := createTemporaryDirectory()
+	defer os.RemoveAll(path)
+
+	ext := createStorageExtension(path)
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+
+	wq := createTestQueueWithOverflow(ext, 1, OverflowSettings{Policy: OverflowBlock, BlockTimeout: 10 * time.Millisecond})
+	req := newFakeTracesRequest(newTraces(1, 10))
+
+	require.True(t, wq.Produce(context.Background(), req))
+	require.Eventually(t, func() bool {
+		return wq.Size() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, wq.Produce(context.Background(), req))
+	// Times out while nothing is consumed
+	require.False(t, wq.Produce(context.Background(), req))
+
+	wq.overflow.cfg.BlockTimeout = 5 * time.Second
+	produced := make(chan bool)
+	go func() { produced <- wq.Produce(context.Background(), req) }()
+	wq.StartConsumers(1, func(item interface{}) {})
+	require.True(t, <-produced)
+	wq.Stop()
+}
+
 func TestPersistentQueue_Close(t *testing.T) {
 	path := createTemporaryDirectory()
 	defer os.RemoveAll(path)
@@ -94,7 +167,7 @@ func TestPersistentQueue_Close(t *testing.T) {
 	wq.StartConsumers(100, func(item interface{}) {})
 
 	for i := 0; i < 1000; i++ {
-		wq.Produce(req)
+		wq.Produce(context.Background(), req)
 	}
 	// This will close the queue very quickly, consumers might not be able to consume anything and should finish gracefully
 	require.Eventually(t, func() bool {
@@ -157,7 +230,7 @@ func TestPersistentQueue_ConsumersProducers(t *testing.T) {
 			})
 
 			for i := 0; i < c.numMessagesProduced; i++ {
-				tq.Produce(req)
+				tq.Produce(context.Background(), req)
 			}
 
 			require.Eventually(t, func() bool {
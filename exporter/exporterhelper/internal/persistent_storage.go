@@ -12,9 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build enable_unstable
-// +build enable_unstable
-
 package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 
 import (
@@ -37,6 +34,10 @@ type persistentStorage interface {
 	get() <-chan PersistentRequest
 	// size returns the current size of the persistent storage with items waiting for processing
 	size() uint64
+	// sizeBytes returns the current size in bytes of the serialized items waiting for processing
+	sizeBytes() int64
+	// dropOldest deletes the oldest request waiting for processing, returning false if there is none
+	dropOldest() (interface{}, bool)
 	// stop gracefully stops the storage
 	stop()
 }
@@ -75,6 +76,8 @@ type persistentContiguousStorage struct {
 	stopChan chan struct{}
 	stopOnce sync.Once
 	capacity uint64
+	// capacityBytes is the maximum total size of the serialized items waiting for processing, zero means unbounded
+	capacityBytes int64
 
 	reqChan chan PersistentRequest
 
@@ -84,6 +87,7 @@ type persistentContiguousStorage struct {
 	currentlyDispatchedItems []itemIndex
 
 	itemsCount uint64
+	itemsBytes int64
 }
 
 type itemIndex uint64
@@ -97,30 +101,35 @@ const (
 	readIndexKey                = "ri"
 	writeIndexKey               = "wi"
 	currentlyDispatchedItemsKey = "di"
+	queueSizeBytesKey           = "bs"
 )
 
 var (
-	errMaxCapacityReached   = errors.New("max capacity reached")
-	errValueNotSet          = errors.New("value not set")
-	errKeyNotPresentInBatch = errors.New("key was not present in get batchStruct")
+	errMaxCapacityReached      = errors.New("max capacity reached")
+	errMaxCapacityBytesReached = errors.New("max capacity in bytes reached")
+	errValueNotSet             = errors.New("value not set")
+	errKeyNotPresentInBatch    = errors.New("key was not present in get batchStruct")
 )
 
 // newPersistentContiguousStorage creates a new file-storage extension backed queue;
 // queueName parameter must be a unique value that identifies the queue.
-// The queue needs to be initialized separately using initPersistentContiguousStorage.
-func newPersistentContiguousStorage(ctx context.Context, queueName string, capacity uint64, logger *zap.Logger, client storage.Client, unmarshaler RequestUnmarshaler) *persistentContiguousStorage {
+// It fails if the data in the storage cannot be migrated to the current format version.
+func newPersistentContiguousStorage(ctx context.Context, queueName string, capacity uint64, capacityBytes int64, logger *zap.Logger, client storage.Client, unmarshaler RequestUnmarshaler) (*persistentContiguousStorage, error) {
 	pcs := &persistentContiguousStorage{
-		logger:      logger,
-		client:      client,
-		queueName:   queueName,
-		unmarshaler: unmarshaler,
-		capacity:    capacity,
-		putChan:     make(chan struct{}, capacity),
-		reqChan:     make(chan PersistentRequest),
-		stopChan:    make(chan struct{}),
+		logger:        logger,
+		client:        client,
+		queueName:     queueName,
+		unmarshaler:   unmarshaler,
+		capacity:      capacity,
+		capacityBytes: capacityBytes,
+		putChan:       make(chan struct{}, capacity),
+		reqChan:       make(chan PersistentRequest),
+		stopChan:      make(chan struct{}),
 	}
 
-	initPersistentContiguousStorage(ctx, pcs)
+	if err := initPersistentContiguousStorage(ctx, pcs); err != nil {
+		return nil, err
+	}
 	notDispatchedReqs := pcs.retrieveNotDispatchedReqs(context.Background())
 
 	// We start the loop first so in case there are more elements in the persistent storage than the capacity,
@@ -135,10 +144,10 @@ func newPersistentContiguousStorage(ctx context.Context, queueName string, capac
 		pcs.putChan <- struct{}{}
 	}
 
-	return pcs
+	return pcs, nil
 }
 
-func initPersistentContiguousStorage(ctx context.Context, pcs *persistentContiguousStorage) {
+func initPersistentContiguousStorage(ctx context.Context, pcs *persistentContiguousStorage) error {
 	var writeIndex itemIndex
 	var readIndex itemIndex
 	batch, err := newBatch(pcs).get(readIndexKey, writeIndexKey).execute(ctx)
@@ -167,6 +176,23 @@ func initPersistentContiguousStorage(ctx context.Context, pcs *persistentContigu
 	}
 
 	atomic.StoreUint64(&pcs.itemsCount, uint64(pcs.writeIndex-pcs.readIndex))
+
+	if err = migrateFormat(ctx, pcs); err != nil {
+		return err
+	}
+
+	var itemsBytes itemIndex
+	batch, err = newBatch(pcs).get(queueSizeBytesKey).execute(ctx)
+	if err == nil {
+		itemsBytes, err = batch.getItemIndexResult(queueSizeBytesKey)
+	}
+	if err != nil && err != errValueNotSet {
+		pcs.logger.Error("Failed getting queue size in bytes, starting with zero",
+			zap.String(zapQueueNameKey, pcs.queueName),
+			zap.Error(err))
+	}
+	atomic.StoreInt64(&pcs.itemsBytes, int64(itemsBytes))
+	return nil
 }
 
 func (pcs *persistentContiguousStorage) enqueueNotDispatchedReqs(reqs []PersistentRequest) {
@@ -216,6 +242,11 @@ func (pcs *persistentContiguousStorage) size() uint64 {
 	return atomic.LoadUint64(&pcs.itemsCount)
 }
 
+// sizeBytes returns the size in bytes of currently available items, which were not picked by consumers yet
+func (pcs *persistentContiguousStorage) sizeBytes() int64 {
+	return atomic.LoadInt64(&pcs.itemsBytes)
+}
+
 func (pcs *persistentContiguousStorage) stop() {
 	pcs.logger.Debug("Stopping persistentContiguousStorage", zap.String(zapQueueNameKey, pcs.queueName))
 	pcs.stopOnce.Do(func() {
@@ -233,17 +264,37 @@ func (pcs *persistentContiguousStorage) put(req PersistentRequest) error {
 	pcs.mu.Lock()
 	defer pcs.mu.Unlock()
 
+	reqBytes, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if pcs.capacityBytes > 0 && int64(len(reqBytes)) > pcs.capacityBytes {
+		return errItemTooLarge
+	}
+
 	if pcs.size() >= pcs.capacity {
 		pcs.logger.Warn("Maximum queue capacity reached", zap.String(zapQueueNameKey, pcs.queueName))
 		return errMaxCapacityReached
 	}
 
+	itemsBytes := pcs.sizeBytes() + int64(len(reqBytes))
+	if pcs.capacityBytes > 0 && itemsBytes > pcs.capacityBytes {
+		pcs.logger.Warn("Maximum queue capacity in bytes reached", zap.String(zapQueueNameKey, pcs.queueName))
+		return errMaxCapacityBytesReached
+	}
+
 	itemKey := pcs.itemKey(pcs.writeIndex)
 	pcs.writeIndex++
 	atomic.StoreUint64(&pcs.itemsCount, uint64(pcs.writeIndex-pcs.readIndex))
+	atomic.StoreInt64(&pcs.itemsBytes, itemsBytes)
 
 	ctx := context.Background()
-	_, err := newBatch(pcs).setItemIndex(writeIndexKey, pcs.writeIndex).setRequest(itemKey, req).execute(ctx)
+	_, err = newBatch(pcs).
+		setItemIndex(writeIndexKey, pcs.writeIndex).
+		setItemIndex(queueSizeBytesKey, itemIndex(itemsBytes)).
+		setBytes(itemKey, reqBytes).
+		execute(ctx)
 
 	// Inform the loop that there's some data to process
 	pcs.putChan <- struct{}{}
@@ -262,15 +313,16 @@ func (pcs *persistentContiguousStorage) getNextItem(ctx context.Context) (Persis
 		pcs.readIndex++
 		atomic.StoreUint64(&pcs.itemsCount, uint64(pcs.writeIndex-pcs.readIndex))
 
-		pcs.updateReadIndex(ctx)
-		pcs.itemDispatchingStart(ctx, index)
-
 		var req PersistentRequest
 		batch, err := newBatch(pcs).get(pcs.itemKey(index)).execute(ctx)
 		if err == nil {
+			atomic.AddInt64(&pcs.itemsBytes, -int64(batch.getValueSize(pcs.itemKey(index))))
 			req, err = batch.getRequestResult(pcs.itemKey(index))
 		}
 
+		pcs.updateReadIndex(ctx)
+		pcs.itemDispatchingStart(ctx, index)
+
 		if err != nil || req == nil {
 			// We need to make sure that currently dispatched items list is cleaned
 			pcs.itemDispatchingFinish(ctx, index)
@@ -290,6 +342,45 @@ func (pcs *persistentContiguousStorage) getNextItem(ctx context.Context) (Persis
 	return nil, false
 }
 
+// dropOldest deletes the oldest item waiting for processing, returning false if there is none. The returned request
+// is nil if the item could not be unmarshaled.
+func (pcs *persistentContiguousStorage) dropOldest() (interface{}, bool) {
+	pcs.mu.Lock()
+	defer pcs.mu.Unlock()
+
+	if pcs.readIndex == pcs.writeIndex {
+		return nil, false
+	}
+	ctx := context.Background()
+	index := pcs.readIndex
+	pcs.readIndex++
+	atomic.StoreUint64(&pcs.itemsCount, uint64(pcs.writeIndex-pcs.readIndex))
+
+	var req PersistentRequest
+	batch, err := newBatch(pcs).get(pcs.itemKey(index)).execute(ctx)
+	if err == nil {
+		atomic.AddInt64(&pcs.itemsBytes, -int64(batch.getValueSize(pcs.itemKey(index))))
+		req, _ = batch.getRequestResult(pcs.itemKey(index))
+	}
+	pcs.updateReadIndex(ctx)
+	if _, err = newBatch(pcs).delete(pcs.itemKey(index)).execute(ctx); err != nil {
+		pcs.logger.Debug("Failed deleting dropped item",
+			zap.String(zapQueueNameKey, pcs.queueName), zap.Error(err))
+	}
+
+	// Take the notification of an item, so the loop does not look for the dropped item. If there is none,
+	// the loop already took it and finds no item.
+	select {
+	case <-pcs.putChan:
+	default:
+	}
+
+	if req == nil {
+		return nil, true
+	}
+	return req, true
+}
+
 // retrieveNotDispatchedReqs gets the items for which sending was not finished, cleans the storage
 // and moves the items back to the queue. The function returns an array which might contain nils
 // if unmarshalling of the value at a given index was not possible.
@@ -396,6 +487,7 @@ func (pcs *persistentContiguousStorage) itemDispatchingFinish(ctx context.Contex
 func (pcs *persistentContiguousStorage) updateReadIndex(ctx context.Context) {
 	_, err := newBatch(pcs).
 		setItemIndex(readIndexKey, pcs.readIndex).
+		setItemIndex(queueSizeBytesKey, itemIndex(pcs.sizeBytes())).
 		execute(ctx)
 
 	if err != nil {
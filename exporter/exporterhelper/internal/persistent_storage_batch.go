@@ -12,9 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build enable_unstable
-// +build enable_unstable
-
 package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 
 import (
@@ -105,6 +102,17 @@ func (bof *batchStruct) getResult(key string, unmarshal func([]byte) (interface{
 	return unmarshal(op.Value)
 }
 
+// getValueSize returns the size in bytes of the value retrieved by a Get operation for a given key.
+// It should be called after execute. It returns zero if the value is not present
+func (bof *batchStruct) getValueSize(key string) int {
+	op := bof.getOperations[key]
+	if op == nil {
+		return 0
+	}
+
+	return len(op.Value)
+}
+
 // getRequestResult returns the result of a Get operation as a request
 // If the value cannot be retrieved, it returns an error
 func (bof *batchStruct) getRequestResult(key string) (PersistentRequest, error) {
@@ -154,6 +162,12 @@ func (bof *batchStruct) setRequest(key string, value PersistentRequest) *batchSt
 	return bof.set(key, value, requestToBytes)
 }
 
+// setBytes adds Set operation over an already serialized value to the batch
+func (bof *batchStruct) setBytes(key string, value []byte) *batchStruct {
+	bof.operations = append(bof.operations, storage.SetOperation(key, value))
+	return bof
+}
+
 // setItemIndex adds Set operation over a given itemIndex to the batch
 func (bof *batchStruct) setItemIndex(key string, value itemIndex) *batchStruct {
 	return bof.set(key, value, itemIndexToBytes)
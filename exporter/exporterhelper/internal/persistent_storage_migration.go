@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+const (
+	formatVersionKey = "fv"
+
+	// currentFormatVersion is the version of the format of the data written in the storage. It must be increased,
+	// adding a migration to formatMigrations, every time the format changes in a way older versions cannot read.
+	currentFormatVersion = 1
+)
+
+// migrationChunkSize is the maximum number of items read from the storage at once by the migrations.
+var migrationChunkSize itemIndex = 100
+
+var errUnsupportedFormatVersion = errors.New("unsupported persistent queue format version")
+
+// formatMigrations holds at index v the migration of the data in the storage from the format version v to v+1.
+// The format version 0 is the format written before the version was stored.
+var formatMigrations = []func(ctx context.Context, pcs *persistentContiguousStorage) error{
+	migrateToFormatVersion1,
+}
+
+// migrateFormat upgrades the data in the storage to the current format version, one version at a time. The version
+// is stored after every migration, so an interrupted upgrade continues from the last migrated version. It fails for
+// data written in a format newer than the current one, which could be corrupted by this version.
+func migrateFormat(ctx context.Context, pcs *persistentContiguousStorage) error {
+	version, stored, err := getFormatVersion(ctx, pcs)
+	if err != nil {
+		return err
+	}
+	if version > currentFormatVersion {
+		return fmt.Errorf("%w %d, the latest supported version is %d", errUnsupportedFormatVersion, version, currentFormatVersion)
+	}
+	if !stored && version == currentFormatVersion {
+		return setFormatVersion(ctx, pcs, version)
+	}
+
+	for ; version < currentFormatVersion; version++ {
+		if err = formatMigrations[version](ctx, pcs); err != nil {
+			return fmt.Errorf("failed migrating persistent queue format from version %d: %w", version, err)
+		}
+		if err = setFormatVersion(ctx, pcs, version+1); err != nil {
+			return err
+		}
+		pcs.logger.Info("Migrated persistent queue format",
+			zap.String(zapQueueNameKey, pcs.queueName), zap.Uint64("version", uint64(version+1)))
+	}
+	return nil
+}
+
+// getFormatVersion returns the format version of the data in the storage, and whether it is stored. The version
+// is not stored for a new queue, which uses the current format, nor for a queue written in the format version 0.
+func getFormatVersion(ctx context.Context, pcs *persistentContiguousStorage) (itemIndex, bool, error) {
+	batch, err := newBatch(pcs).get(formatVersionKey, writeIndexKey).execute(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	version, err := batch.getItemIndexResult(formatVersionKey)
+	if err != errValueNotSet {
+		return version, err == nil, err
+	}
+	if _, err = batch.getItemIndexResult(writeIndexKey); err == errValueNotSet {
+		return currentFormatVersion, false, nil
+	}
+	return 0, false, nil
+}
+
+func setFormatVersion(ctx context.Context, pcs *persistentContiguousStorage, version itemIndex) error {
+	if _, err := newBatch(pcs).setItemIndex(formatVersionKey, version).execute(ctx); err != nil {
+		return fmt.Errorf("failed storing persistent queue format version %d: %w", version, err)
+	}
+	return nil
+}
+
+// migrateToFormatVersion1 stores the size in bytes of the items waiting for processing, not tracked in the version 0.
+// The items are read migrationChunkSize at a time, so a large backlog is not loaded in memory at once, and the size
+// is only stored once all of them were read.
+func migrateToFormatVersion1(ctx context.Context, pcs *persistentContiguousStorage) error {
+	var itemsBytes int64
+	for start := pcs.readIndex; start < pcs.writeIndex; start += migrationChunkSize {
+		end := start + migrationChunkSize
+		if end > pcs.writeIndex {
+			end = pcs.writeIndex
+		}
+		keys := make([]string, 0, end-start)
+		for index := start; index < end; index++ {
+			keys = append(keys, pcs.itemKey(index))
+		}
+
+		batch, err := newBatch(pcs).get(keys...).execute(ctx)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			itemsBytes += int64(batch.getValueSize(key))
+		}
+	}
+
+	_, err := newBatch(pcs).setItemIndex(queueSizeBytesKey, itemIndex(itemsBytes)).execute(ctx)
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+func getStoredFormatVersion(t *testing.T, pcs *persistentContiguousStorage) itemIndex {
+	batch, err := newBatch(pcs).get(formatVersionKey).execute(context.Background())
+	require.NoError(t, err)
+	version, err := batch.getItemIndexResult(formatVersionKey)
+	require.NoError(t, err)
+	return version
+}
+
+func TestPersistentStorage_NewQueueFormatVersion(t *testing.T) {
+	ext := createStorageExtension("")
+	client := createTestClient(ext)
+
+	ps := createTestPersistentStorage(client)
+	require.Equal(t, itemIndex(currentFormatVersion), getStoredFormatVersion(t, ps))
+	ps.stop()
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestPersistentStorage_MigrateFormatVersion0(t *testing.T) {
+	ext := createStorageExtension("")
+	client := createTestClient(ext)
+	ctx := context.Background()
+
+	// Write a queue with two items in the format version 0, without the format version nor the size in bytes.
+	req := newFakeTracesRequest(newTraces(5, 10))
+	reqBytes, err := req.Marshal()
+	require.NoError(t, err)
+	old := &persistentContiguousStorage{logger: zap.NewNop(), client: client, queueName: "foo"}
+	_, err = newBatch(old).
+		setItemIndex(readIndexKey, 1).
+		setItemIndex(writeIndexKey, 3).
+		setRequest(old.itemKey(1), req).
+		setRequest(old.itemKey(2), req).
+		execute(ctx)
+	require.NoError(t, err)
+
+	ps := createTestPersistentStorage(client)
+	require.Equal(t, itemIndex(currentFormatVersion), getStoredFormatVersion(t, ps))
+	require.Equal(t, uint64(2), ps.size())
+	require.Equal(t, int64(2*len(reqBytes)), ps.sizeBytes())
+	ps.stop()
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestPersistentStorage_UnsupportedFormatVersion(t *testing.T) {
+	ext := createStorageExtension("")
+	client := createTestClient(ext)
+	ctx := context.Background()
+
+	newer := &persistentContiguousStorage{logger: zap.NewNop(), client: client, queueName: "foo"}
+	_, err := newBatch(newer).
+		setItemIndex(formatVersionKey, currentFormatVersion+1).
+		setItemIndex(writeIndexKey, 0).
+		execute(ctx)
+	require.NoError(t, err)
+
+	_, err = newPersistentContiguousStorage(ctx, "foo", 1000, 0, zap.NewNop(), client, newFakeTracesRequestUnmarshalerFunc())
+	require.ErrorIs(t, err, errUnsupportedFormatVersion)
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+// chunkRecordingClient records the largest number of gets of the batches, and fails the batches getting failKey.
+type chunkRecordingClient struct {
+	storage.Client
+	failKey string
+	maxGets int
+}
+
+func (c *chunkRecordingClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	gets := 0
+	for _, op := range ops {
+		if op.Type != storage.Get {
+			continue
+		}
+		gets++
+		if op.Key == c.failKey {
+			return errors.New("storage unavailable")
+		}
+	}
+	if gets > c.maxGets {
+		c.maxGets = gets
+	}
+	return c.Client.Batch(ctx, ops...)
+}
+
+func TestPersistentStorage_MigrateFormatVersion0InChunks(t *testing.T) {
+	defer func(size itemIndex) { migrationChunkSize = size }(migrationChunkSize)
+	migrationChunkSize = 2
+
+	ext := createStorageExtension("")
+	client := createTestClient(ext)
+	ctx := context.Background()
+
+	req := newFakeTracesRequest(newTraces(1, 1))
+	reqBytes, err := req.Marshal()
+	require.NoError(t, err)
+	old := &persistentContiguousStorage{logger: zap.NewNop(), client: client, queueName: "foo"}
+	batch := newBatch(old).setItemIndex(readIndexKey, 0).setItemIndex(writeIndexKey, 5)
+	for i := itemIndex(0); i < 5; i++ {
+		batch.setRequest(old.itemKey(i), req)
+	}
+	_, err = batch.execute(ctx)
+	require.NoError(t, err)
+
+	// The format version is not stored when reading a chunk fails, so the migration is done again on the next start.
+	failing := &chunkRecordingClient{Client: client, failKey: old.itemKey(4)}
+	_, err = newPersistentContiguousStorage(ctx, "foo", 1000, 0, zap.NewNop(), failing, newFakeTracesRequestUnmarshalerFunc())
+	require.Error(t, err)
+	versionBatch, err := newBatch(old).get(formatVersionKey).execute(ctx)
+	require.NoError(t, err)
+	_, err = versionBatch.getItemIndexResult(formatVersionKey)
+	require.Equal(t, errValueNotSet, err)
+
+	recording := &chunkRecordingClient{Client: client}
+	ps := createTestPersistentStorage(recording)
+	require.Equal(t, itemIndex(currentFormatVersion), getStoredFormatVersion(t, ps))
+	require.Equal(t, int64(5*len(reqBytes)), ps.sizeBytes())
+	require.Equal(t, 2, recording.maxGets)
+	ps.stop()
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
@@ -12,9 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build enable_unstable
-// +build enable_unstable
-
 package internal
 
 import (
@@ -52,7 +49,11 @@ func createTestClient(extension storage.Extension) storage.Client {
 }
 
 func createTestPersistentStorageWithLoggingAndCapacity(client storage.Client, logger *zap.Logger, capacity uint64) *persistentContiguousStorage {
-	return newPersistentContiguousStorage(context.Background(), "foo", capacity, logger, client, newFakeTracesRequestUnmarshalerFunc())
+	pcs, err := newPersistentContiguousStorage(context.Background(), "foo", capacity, 0, logger, client, newFakeTracesRequestUnmarshalerFunc())
+	if err != nil {
+		panic(err)
+	}
+	return pcs
 }
 
 func createTestPersistentStorage(client storage.Client) *persistentContiguousStorage {
@@ -336,6 +337,41 @@ func TestPersistentStorage_RepeatPutCloseReadClose(t *testing.T) {
 	require.NoError(t, ext.Shutdown(context.Background()))
 }
 
+func TestPersistentStorage_CapacityBytes(t *testing.T) {
+	path := createTemporaryDirectory()
+	defer os.RemoveAll(path)
+
+	ext := createStorageExtension(path)
+	client := createTestClient(ext)
+
+	req := newFakeTracesRequest(newTraces(5, 10))
+	reqBytes, err := req.Marshal()
+	require.NoError(t, err)
+	reqSize := int64(len(reqBytes))
+
+	ps, err := newPersistentContiguousStorage(context.Background(), "foo", 1000, 2*reqSize, zap.NewNop(), client, newFakeTracesRequestUnmarshalerFunc())
+	require.NoError(t, err)
+
+	require.NoError(t, ps.put(req))
+	// Let's make sure the loop picks the first element into the channel
+	require.Eventually(t, func() bool {
+		return ps.sizeBytes() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ps.put(req))
+	require.NoError(t, ps.put(req))
+	require.ErrorIs(t, ps.put(req), errMaxCapacityBytesReached)
+	require.Equal(t, 2*reqSize, ps.sizeBytes())
+	ps.stop()
+
+	// The size in bytes is restored from the storage
+	restored := &persistentContiguousStorage{logger: zap.NewNop(), client: client, queueName: "foo"}
+	require.NoError(t, initPersistentContiguousStorage(context.Background(), restored))
+	require.Equal(t, 2*reqSize, restored.sizeBytes())
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
 func TestPersistentStorage_EmptyRequest(t *testing.T) {
 	path := createTemporaryDirectory()
 	defer os.RemoveAll(path)
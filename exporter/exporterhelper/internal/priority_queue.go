@@ -0,0 +1,254 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"context"
+	"sync"
+
+	uatomic "go.uber.org/atomic"
+)
+
+// PriorityLane describes a lane of the priority queue.
+type PriorityLane struct {
+	// Weight is the share of the consumed items taken from this lane when all the lanes have items.
+	Weight int
+	// Capacity is the maximum number of items in this lane.
+	Capacity int
+}
+
+// PriorityQueue is a ProducerConsumerQueue holding the items in several lanes, each one bounded separately.
+// The items are consumed with weighted fair dequeueing: while several lanes have items, every lane gets
+// a share of the consumed items proportional to its weight.
+type PriorityQueue interface {
+	ProducerConsumerQueue
+	// LaneSize returns the current size of the given lane.
+	LaneSize(lane int) int
+}
+
+type priorityLane struct {
+	weight int
+	// current is the state of the smooth weighted round-robin, guarded by the queue mutex.
+	current int
+	items   chan queueItem
+	size    *uatomic.Uint32
+}
+
+type priorityQueue struct {
+	lanes         []*priorityLane
+	classifier    func(item interface{}) int
+	onDroppedItem func(item interface{}, lane int)
+	capacity      int
+	sizeBytes     *uatomic.Int64
+	capacityBytes int64
+	sizer         ByteSizer
+	// available holds one token for every item in the lanes, so consumers block until there is an item.
+	available chan struct{}
+	mu        sync.Mutex
+	stopped   *uatomic.Bool
+	stopCh    chan struct{}
+	stopWG    sync.WaitGroup
+	overflow  *overflowHandler
+}
+
+// NewPriorityQueue constructs a new queue with the given lanes. The classifier returns the index of the lane of an item;
+// items classified outside of the lanes go to the last lane. When capacityBytes is positive, the queue is additionally
+// bounded by the total size in bytes of the items it holds across all lanes, as reported by the sizer. The overflow
+// settings apply to every lane, the oldest items dropped by the OverflowDropOldest policy are taken from the lane of
+// the produced item.
+func NewPriorityQueue(lanes []PriorityLane, capacityBytes int64, sizer ByteSizer, overflow OverflowSettings, classifier func(item interface{}) int, onDroppedItem func(item interface{}, lane int)) PriorityQueue {
+	stopCh := make(chan struct{})
+	pq := &priorityQueue{
+		classifier:    classifier,
+		onDroppedItem: onDroppedItem,
+		sizeBytes:     uatomic.NewInt64(0),
+		capacityBytes: capacityBytes,
+		sizer:         sizer,
+		stopped:       uatomic.NewBool(false),
+		stopCh:        stopCh,
+		overflow:      newOverflowHandler(overflow, stopCh),
+	}
+	for _, lane := range lanes {
+		pq.lanes = append(pq.lanes, &priorityLane{
+			weight: lane.Weight,
+			items:  make(chan queueItem, lane.Capacity),
+			size:   uatomic.NewUint32(0),
+		})
+		pq.capacity += lane.Capacity
+	}
+	pq.available = make(chan struct{}, pq.capacity)
+	return pq
+}
+
+// StartConsumers starts a given number of goroutines consuming items from the queue
+// and passing them into the consumer callback.
+func (pq *priorityQueue) StartConsumers(num int, callback func(item interface{})) {
+	var startWG sync.WaitGroup
+	for i := 0; i < num; i++ {
+		pq.stopWG.Add(1)
+		startWG.Add(1)
+		go func() {
+			startWG.Done()
+			defer pq.stopWG.Done()
+			for {
+				select {
+				case <-pq.available:
+					callback(pq.next().item)
+				case <-pq.stopCh:
+					return
+				}
+			}
+		}()
+	}
+	startWG.Wait()
+}
+
+// next takes the next item using smooth weighted round-robin between the lanes having items.
+// It must be called only after taking a token from available, which guarantees that a lane has an item.
+func (pq *priorityQueue) next() queueItem {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	var selected *priorityLane
+	total := 0
+	for _, lane := range pq.lanes {
+		if len(lane.items) == 0 {
+			continue
+		}
+		lane.current += lane.weight
+		total += lane.weight
+		if selected == nil || lane.current > selected.current {
+			selected = lane
+		}
+	}
+	selected.current -= total
+	qi := <-selected.items
+	selected.size.Sub(1)
+	pq.sizeBytes.Sub(qi.sizeBytes)
+	pq.overflow.notifySpace()
+	return qi
+}
+
+// Produce is used by the producer to submit new item to the queue. Returns false in case of overflow of the item lane.
+func (pq *priorityQueue) Produce(ctx context.Context, item interface{}) bool {
+	laneIdx := pq.classifier(item)
+	if laneIdx < 0 || laneIdx >= len(pq.lanes) {
+		laneIdx = len(pq.lanes) - 1
+	}
+	lane := pq.lanes[laneIdx]
+
+	var sizeBytes int64
+	if pq.capacityBytes > 0 {
+		sizeBytes = pq.sizer.size(item)
+	}
+
+	produced := pq.overflow.produce(ctx,
+		func() error { return pq.tryProduce(lane, item, sizeBytes) },
+		func() (interface{}, bool) { return pq.dropOldest(lane) })
+	if !produced {
+		pq.onDroppedItem(item, laneIdx)
+	}
+	return produced
+}
+
+func (pq *priorityQueue) tryProduce(lane *priorityLane, item interface{}, sizeBytes int64) error {
+	if pq.stopped.Load() {
+		return errQueueStopped
+	}
+
+	// items which never fit are rejected before checking the room left, so no room is made for them
+	if pq.capacityBytes > 0 && sizeBytes > pq.capacityBytes {
+		return errItemTooLarge
+	}
+
+	if int(lane.size.Load()) >= cap(lane.items) {
+		return errQueueFull
+	}
+
+	if pq.capacityBytes > 0 {
+		if pq.sizeBytes.Add(sizeBytes) > pq.capacityBytes {
+			pq.sizeBytes.Sub(sizeBytes)
+			return errQueueFull
+		}
+	}
+
+	lane.size.Add(1)
+	select {
+	case lane.items <- queueItem{item: item, sizeBytes: sizeBytes}:
+		// The token is added after the item, so a consumer taking it always finds an item.
+		pq.available <- struct{}{}
+		return nil
+	default:
+		// concurrent producers filled the lane after the size check
+		lane.size.Sub(1)
+		pq.sizeBytes.Sub(sizeBytes)
+		return errQueueFull
+	}
+}
+
+// dropOldest removes the oldest item of the lane, returning false if there is none.
+func (pq *priorityQueue) dropOldest(lane *priorityLane) (interface{}, bool) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	if len(lane.items) == 0 {
+		return nil, false
+	}
+	// Take the token of an item, so no consumer looks for the dropped item. If there is no token,
+	// all the items are being taken by consumers waiting for the lock.
+	select {
+	case <-pq.available:
+	default:
+		return nil, false
+	}
+	qi := <-lane.items
+	lane.size.Sub(1)
+	pq.sizeBytes.Sub(qi.sizeBytes)
+	return qi.item, true
+}
+
+// Stop stops all consumers. It blocks until all consumers have stopped.
+func (pq *priorityQueue) Stop() {
+	pq.stopped.Store(true)
+	close(pq.stopCh)
+	pq.stopWG.Wait()
+}
+
+// Size returns the current size of the queue, across all lanes
+func (pq *priorityQueue) Size() int {
+	size := 0
+	for _, lane := range pq.lanes {
+		size += int(lane.size.Load())
+	}
+	return size
+}
+
+// Capacity returns capacity of the queue, the sum of the capacities of the lanes
+func (pq *priorityQueue) Capacity() int {
+	return pq.capacity
+}
+
+// SizeBytes returns the current size of the queue in bytes
+func (pq *priorityQueue) SizeBytes() int64 {
+	return pq.sizeBytes.Load()
+}
+
+// CapacityBytes returns capacity of the queue in bytes
+func (pq *priorityQueue) CapacityBytes() int64 {
+	return pq.capacityBytes
+}
+
+// LaneSize returns the current size of the given lane
+func (pq *priorityQueue) LaneSize(lane int) int {
+	return int(pq.lanes[lane].size.Load())
+}
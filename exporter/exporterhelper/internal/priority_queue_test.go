@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// laneOf classifies items like "high-1" by their prefix.
+func laneOf(item interface{}) int {
+	switch {
+	case strings.HasPrefix(item.(string), "high"):
+		return 0
+	case strings.HasPrefix(item.(string), "low"):
+		return 1
+	}
+	return -1
+}
+
+func TestPriorityQueue_WeightedFairDequeue(t *testing.T) {
+	pq := NewPriorityQueue([]PriorityLane{{Weight: 3, Capacity: 10}, {Weight: 1, Capacity: 10}}, 0, nil, OverflowSettings{}, laneOf, func(interface{}, int) {})
+	assert.Equal(t, 20, pq.Capacity())
+
+	for i := 0; i < 8; i++ {
+		require.True(t, pq.Produce(context.Background(), "low"))
+	}
+	for i := 0; i < 6; i++ {
+		require.True(t, pq.Produce(context.Background(), "high"))
+	}
+	assert.Equal(t, 6, pq.LaneSize(0))
+	assert.Equal(t, 8, pq.LaneSize(1))
+	assert.Equal(t, 14, pq.Size())
+
+	var mu sync.Mutex
+	var consumed []string
+	pq.StartConsumers(1, func(item interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed = append(consumed, item.(string))
+	})
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(consumed) == 14
+	}, time.Second, time.Millisecond)
+	pq.Stop()
+
+	// While both lanes have items, 3 of every 4 items are taken from the high priority lane.
+	assert.Equal(t, []string{"high", "high", "low", "high", "high", "high", "low", "high"}, consumed[:8])
+	assert.Equal(t, []string{"low", "low", "low", "low", "low", "low"}, consumed[8:])
+	assert.Zero(t, pq.Size())
+}
+
+func TestPriorityQueue_Overflow(t *testing.T) {
+	dropped := map[int]int{}
+	pq := NewPriorityQueue([]PriorityLane{{Weight: 1, Capacity: 1}, {Weight: 1, Capacity: 2}}, 0, nil, OverflowSettings{}, laneOf, func(_ interface{}, lane int) {
+		dropped[lane]++
+	})
+
+	assert.True(t, pq.Produce(context.Background(), "high"))
+	assert.False(t, pq.Produce(context.Background(), "high"))
+	// Items of an unknown lane go to the last lane.
+	assert.True(t, pq.Produce(context.Background(), "low"))
+	assert.True(t, pq.Produce(context.Background(), "other"))
+	assert.False(t, pq.Produce(context.Background(), "other"))
+	assert.Equal(t, map[int]int{0: 1, 1: 1}, dropped)
+
+	pq.StartConsumers(1, func(interface{}) {})
+	pq.Stop()
+	assert.False(t, pq.Produce(context.Background(), "high"))
+	assert.Equal(t, map[int]int{0: 2, 1: 1}, dropped)
+}
+
+func TestPriorityQueue_CapacityBytes(t *testing.T) {
+	pq := NewPriorityQueue([]PriorityLane{{Weight: 1, Capacity: 10}, {Weight: 1, Capacity: 10}}, 10, testSizer, OverflowSettings{}, func(interface{}) int { return 0 }, func(interface{}, int) {})
+	assert.True(t, pq.Produce(context.Background(), sizedItem(6)))
+	assert.False(t, pq.Produce(context.Background(), sizedItem(6)))
+	assert.True(t, pq.Produce(context.Background(), sizedItem(4)))
+	assert.Equal(t, int64(10), pq.SizeBytes())
+	assert.Equal(t, int64(10), pq.CapacityBytes())
+}
@@ -16,6 +16,8 @@
 
 package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 
+import "context"
+
 // consumer consumes data from a bounded queue
 type consumer interface {
 	consume(item interface{})
@@ -28,13 +30,28 @@ type ProducerConsumerQueue interface {
 	// and passing them into the consumer callback.
 	StartConsumers(num int, callback func(item interface{}))
 	// Produce is used by the producer to submit new item to the queue. Returns false if the item wasn't added
-	// to the queue due to queue overflow.
-	Produce(item interface{}) bool
+	// to the queue due to queue overflow. The context only cancels the wait for room with the OverflowBlock policy.
+	Produce(ctx context.Context, item interface{}) bool
 	// Size returns the current Size of the queue
 	Size() int
 	// Capacity returns capacity of the queue
 	Capacity() int
+	// SizeBytes returns the current size of the queue in bytes, as reported by the ByteSizer of the queue
+	SizeBytes() int64
+	// CapacityBytes returns capacity of the queue in bytes; zero means that the queue is not bounded by size in bytes
+	CapacityBytes() int64
 	// Stop stops all consumers, as well as the length reporter if started,
 	// and releases the items channel. It blocks until all consumers have stopped.
 	Stop()
 }
+
+// ByteSizer returns the size of the serialized item in bytes.
+type ByteSizer func(item interface{}) int64
+
+// size returns the size in bytes of the given item, or zero if there is no ByteSizer
+func (s ByteSizer) size(item interface{}) int64 {
+	if s == nil {
+		return 0
+	}
+	return s(item)
+}
@@ -23,12 +23,14 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/internal/batchsplit"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 var logsMarshaler = otlp.NewProtobufLogsMarshaler()
 var logsUnmarshaler = otlp.NewProtobufLogsUnmarshaler()
+var logsSizer = otlp.NewProtobufLogsMarshaler().(pdata.LogsSizer)
 
 type logsRequest struct {
 	baseRequest
@@ -74,6 +76,19 @@ func (req *logsRequest) count() int {
 	return req.ld.LogRecordCount()
 }
 
+// byteSize provides the serialized size of the request required by the byte-bounded sending queue
+func (req *logsRequest) byteSize() int {
+	return logsSizer.LogsSize(req.ld)
+}
+
+func (req *logsRequest) merge(other request) {
+	other.(*logsRequest).ld.ResourceLogs().MoveAndAppendTo(req.ld.ResourceLogs())
+}
+
+func (req *logsRequest) split(size int) request {
+	return newLogsRequest(req.ctx, batchsplit.Logs(size, req.ld), req.pusher)
+}
+
 type logsExporter struct {
 	*baseExporter
 	consumer.Logs
@@ -99,6 +114,9 @@ func NewLogsExporter(
 	}
 
 	bs := fromOptions(options...)
+	if err := bs.validate(); err != nil {
+		return nil, err
+	}
 	be := newBaseExporter(cfg, set, bs, config.LogsDataType, newLogsRequestUnmarshalerFunc(pusher))
 	be.wrapConsumerSender(func(nextSender requestSender) requestSender {
 		return &logsExporterWithObservability{
@@ -108,12 +126,7 @@ func NewLogsExporter(
 	})
 
 	lc, err := consumer.NewLogs(func(ctx context.Context, ld pdata.Logs) error {
-		req := newLogsRequest(ctx, ld, pusher)
-		err := be.sender.send(req)
-		if errors.Is(err, errSendingQueueIsFull) {
-			be.obsrep.recordLogsEnqueueFailure(req.context(), int64(req.count()))
-		}
-		return err
+		return be.sender.send(newLogsRequest(ctx, ld, pusher))
 	}, bs.consumerOptions...)
 
 	return &logsExporter{
@@ -76,6 +76,12 @@ func TestLogsExporter_NilPushLogsData(t *testing.T) {
 	require.Equal(t, errNilPushLogsData, err)
 }
 
+func TestLogsExporter_InvalidOptions(t *testing.T) {
+	le, err := NewLogsExporter(&fakeLogsExporterConfig, componenttest.NewNopExporterCreateSettings(), newPushLogsData(nil), WithHedging(HedgingSettings{Enabled: true}))
+	require.Nil(t, le)
+	require.EqualError(t, err, "hedging has invalid configuration: quantile must be in the range (0, 1]")
+}
+
 func TestLogsExporter_Default(t *testing.T) {
 	ld := pdata.NewLogs()
 	le, err := NewLogsExporter(&fakeLogsExporterConfig, componenttest.NewNopExporterCreateSettings(), newPushLogsData(nil))
@@ -136,6 +142,7 @@ func TestLogsExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	te, err := NewLogsExporter(&fakeLogsExporterConfig, tt.ToExporterCreateSettings(), newPushLogsData(wantErr), WithRetry(rCfg), WithQueue(qCfg))
 	require.NoError(t, err)
 	require.NotNil(t, te)
+	enqueueFailed := valueForGlobalManager(tagsForExporterView(fakeLogsExporterName), "exporter/enqueue_failed_log_records")
 
 	md := testdata.GenerateLogsTwoLogRecordsSameResourceOneDifferent()
 	const numBatches = 7
@@ -145,7 +152,7 @@ func TestLogsExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	}
 
 	// 2 batched must be in queue, and 5 batches (15 log records) rejected due to queue overflow
-	checkExporterEnqueueFailedLogsStats(t, globalInstruments, fakeLogsExporterName, int64(15))
+	checkExporterEnqueueFailedLogsStats(t, globalInstruments, fakeLogsExporterName, enqueueFailed+15)
 }
 
 func TestLogsExporter_WithSpan(t *testing.T) {
@@ -23,12 +23,14 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/internal/batchsplit"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 var metricsMarshaler = otlp.NewProtobufMetricsMarshaler()
 var metricsUnmarshaler = otlp.NewProtobufMetricsUnmarshaler()
+var metricsSizer = otlp.NewProtobufMetricsMarshaler().(pdata.MetricsSizer)
 
 type metricsRequest struct {
 	baseRequest
@@ -75,6 +77,19 @@ func (req *metricsRequest) count() int {
 	return req.md.DataPointCount()
 }
 
+// byteSize provides the serialized size of the request required by the byte-bounded sending queue
+func (req *metricsRequest) byteSize() int {
+	return metricsSizer.MetricsSize(req.md)
+}
+
+func (req *metricsRequest) merge(other request) {
+	other.(*metricsRequest).md.ResourceMetrics().MoveAndAppendTo(req.md.ResourceMetrics())
+}
+
+func (req *metricsRequest) split(size int) request {
+	return newMetricsRequest(req.ctx, batchsplit.Metrics(size, req.md), req.pusher)
+}
+
 type metricsExporter struct {
 	*baseExporter
 	consumer.Metrics
@@ -100,6 +115,9 @@ func NewMetricsExporter(
 	}
 
 	bs := fromOptions(options...)
+	if err := bs.validate(); err != nil {
+		return nil, err
+	}
 	be := newBaseExporter(cfg, set, bs, config.MetricsDataType, newMetricsRequestUnmarshalerFunc(pusher))
 	be.wrapConsumerSender(func(nextSender requestSender) requestSender {
 		return &metricsSenderWithObservability{
@@ -109,12 +127,7 @@ func NewMetricsExporter(
 	})
 
 	mc, err := consumer.NewMetrics(func(ctx context.Context, md pdata.Metrics) error {
-		req := newMetricsRequest(ctx, md, pusher)
-		err := be.sender.send(req)
-		if errors.Is(err, errSendingQueueIsFull) {
-			be.obsrep.recordMetricsEnqueueFailure(req.context(), int64(req.count()))
-		}
-		return err
+		return be.sender.send(newMetricsRequest(ctx, md, pusher))
 	}, bs.consumerOptions...)
 
 	return &metricsExporter{
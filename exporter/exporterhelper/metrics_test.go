@@ -75,6 +75,12 @@ func TestMetricsExporter_NilPushMetricsData(t *testing.T) {
 	require.Equal(t, errNilPushMetricsData, err)
 }
 
+func TestMetricsExporter_InvalidOptions(t *testing.T) {
+	me, err := NewMetricsExporter(&fakeMetricsExporterConfig, componenttest.NewNopExporterCreateSettings(), newPushMetricsData(nil), WithHedging(HedgingSettings{Enabled: true}))
+	require.Nil(t, me)
+	require.EqualError(t, err, "hedging has invalid configuration: quantile must be in the range (0, 1]")
+}
+
 func TestMetricsExporter_Default(t *testing.T) {
 	md := pdata.NewMetrics()
 	me, err := NewMetricsExporter(&fakeMetricsExporterConfig, componenttest.NewNopExporterCreateSettings(), newPushMetricsData(nil))
@@ -135,6 +141,7 @@ func TestMetricsExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	te, err := NewMetricsExporter(&fakeMetricsExporterConfig, tt.ToExporterCreateSettings(), newPushMetricsData(wantErr), WithRetry(rCfg), WithQueue(qCfg))
 	require.NoError(t, err)
 	require.NotNil(t, te)
+	enqueueFailed := valueForGlobalManager(tagsForExporterView(fakeMetricsExporterName), "exporter/enqueue_failed_metric_points")
 
 	md := testdata.GenerateMetricsOneMetric()
 	const numBatches = 7
@@ -144,7 +151,7 @@ func TestMetricsExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	}
 
 	// 2 batched must be in queue, and 10 metric points rejected due to queue overflow
-	checkExporterEnqueueFailedMetricsStats(t, globalInstruments, fakeMetricsExporterName, int64(10))
+	checkExporterEnqueueFailedMetricsStats(t, globalInstruments, fakeMetricsExporterName, enqueueFailed+10)
 }
 
 func TestMetricsExporter_WithSpan(t *testing.T) {
@@ -16,11 +16,17 @@ package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporte
 
 import (
 	"context"
+	"time"
 
 	"go.opencensus.io/metric"
 	"go.opencensus.io/metric/metricdata"
 	"go.opencensus.io/metric/metricproducer"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
 
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/internal/obsreportconfig"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 	"go.opentelemetry.io/collector/obsreport"
 )
@@ -40,6 +46,13 @@ func init() {
 type instruments struct {
 	registry                    *metric.Registry
 	queueSize                   *metric.Int64DerivedGauge
+	queueCapacity               *metric.Int64DerivedGauge
+	queueConsumers              *metric.Int64DerivedGauge
+	queueLaneSize               *metric.Int64DerivedGauge
+	queueLaneDropped            *metric.Int64Cumulative
+	queueEvictedItems           *metric.Int64Cumulative
+	queueDrainedItems           *metric.Int64Cumulative
+	circuitBreakerTransitions   *metric.Int64Cumulative
 	failedToEnqueueTraceSpans   *metric.Int64Cumulative
 	failedToEnqueueMetricPoints *metric.Int64Cumulative
 	failedToEnqueueLogRecords   *metric.Int64Cumulative
@@ -52,7 +65,49 @@ func newInstruments(registry *metric.Registry) *instruments {
 	insts.queueSize, _ = registry.AddInt64DerivedGauge(
 		obsmetrics.ExporterKey+"/queue_size",
 		metric.WithDescription("Current size of the retry queue (in batches)"),
-		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.queueCapacity, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/queue_capacity",
+		metric.WithDescription("Fixed capacity of the retry queue (in batches)"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.queueConsumers, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/queue_consumers",
+		metric.WithDescription("Current number of consumers allowed to send batches from the retry queue concurrently"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.queueLaneSize, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/queue_lane_size",
+		metric.WithDescription("Current size of the priority lane of the retry queue (in batches)"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey, "lane"),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.queueLaneDropped, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/queue_lane_dropped",
+		metric.WithDescription("Number of batches dropped because the priority lane of the retry queue was full."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey, "lane"),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.queueEvictedItems, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/queue_evicted_items",
+		metric.WithDescription("Number of spans, metric points or log records dropped from the retry queue to make room for newer batches."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.queueDrainedItems, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/queue_drained_items",
+		metric.WithDescription("Number of spans, metric points or log records flushed or abandoned while draining the retry queue on shutdown."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey, "outcome"),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.circuitBreakerTransitions, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/circuit_breaker_transitions",
+		metric.WithDescription("Number of times the circuit breaker changed to the given state."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, obsmetrics.SignalKey, "state"),
 		metric.WithUnit(metricdata.UnitDimensionless))
 
 	insts.failedToEnqueueTraceSpans, _ = registry.AddInt64Cumulative(
@@ -79,26 +134,72 @@ func newInstruments(registry *metric.Registry) *instruments {
 // obsExporter is a helper to add observability to a component.Exporter.
 type obsExporter struct {
 	*obsreport.Exporter
+	mutators                         []tag.Mutator
 	failedToEnqueueTraceSpansEntry   *metric.Int64CumulativeEntry
 	failedToEnqueueMetricPointsEntry *metric.Int64CumulativeEntry
 	failedToEnqueueLogRecordsEntry   *metric.Int64CumulativeEntry
 }
 
 // newObsExporter creates a new observability exporter.
-func newObsExporter(cfg obsreport.ExporterSettings, insts *instruments) *obsExporter {
+func newObsExporter(cfg obsreport.ExporterSettings, signal config.DataType, insts *instruments) *obsExporter {
 	labelValue := metricdata.NewLabelValue(cfg.ExporterID.String())
 	failedToEnqueueTraceSpansEntry, _ := insts.failedToEnqueueTraceSpans.GetEntry(labelValue)
 	failedToEnqueueMetricPointsEntry, _ := insts.failedToEnqueueMetricPoints.GetEntry(labelValue)
 	failedToEnqueueLogRecordsEntry, _ := insts.failedToEnqueueLogRecords.GetEntry(labelValue)
 
 	return &obsExporter{
-		Exporter:                         obsreport.NewExporter(cfg),
+		Exporter: obsreport.NewExporter(cfg),
+		mutators: []tag.Mutator{
+			tag.Upsert(obsmetrics.TagKeyExporter, cfg.ExporterID.String(), tag.WithTTL(tag.TTLNoPropagation)),
+			tag.Upsert(obsmetrics.TagKeySignal, string(signal), tag.WithTTL(tag.TTLNoPropagation)),
+		},
 		failedToEnqueueTraceSpansEntry:   failedToEnqueueTraceSpansEntry,
 		failedToEnqueueMetricPointsEntry: failedToEnqueueMetricPointsEntry,
 		failedToEnqueueLogRecordsEntry:   failedToEnqueueLogRecordsEntry,
 	}
 }
 
+// recordQueueLatency records the time spent by a batch in the sending queue.
+func (eor *obsExporter) recordQueueLatency(ctx context.Context, latency time.Duration) {
+	eor.record(ctx, obsmetrics.ExporterQueueLatency.M(durationToMillis(latency)))
+}
+
+// recordSendLatency records the duration of an attempt to send a batch.
+func (eor *obsExporter) recordSendLatency(ctx context.Context, latency time.Duration) {
+	eor.record(ctx, obsmetrics.ExporterSendLatency.M(durationToMillis(latency)))
+}
+
+// recordRetry records a retry of a batch which failed to be sent.
+func (eor *obsExporter) recordRetry(ctx context.Context) {
+	eor.record(ctx, obsmetrics.ExporterRetries.M(1))
+}
+
+func (eor *obsExporter) record(ctx context.Context, measurement stats.Measurement) {
+	if obsreportconfig.Level() == configtelemetry.LevelNone {
+		return
+	}
+	// Ignore the error for now. This should not happen.
+	_ = stats.RecordWithTags(ctx, eor.mutators, measurement)
+}
+
+func durationToMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// enqueueFailureRecorder returns the function recording the items of the signal which failed to be added to
+// the sending queue, or nil for an unknown signal.
+func (eor *obsExporter) enqueueFailureRecorder(signal config.DataType) func(ctx context.Context, numItems int64) {
+	switch signal {
+	case config.TracesDataType:
+		return eor.recordTracesEnqueueFailure
+	case config.MetricsDataType:
+		return eor.recordMetricsEnqueueFailure
+	case config.LogsDataType:
+		return eor.recordLogsEnqueueFailure
+	}
+	return nil
+}
+
 // recordTracesEnqueueFailure records number of spans that failed to be added to the sending queue.
 func (eor *obsExporter) recordTracesEnqueueFailure(_ context.Context, numSpans int64) {
 	eor.failedToEnqueueTraceSpansEntry.Inc(numSpans)
@@ -16,10 +16,14 @@ package exporterhelper
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/metric"
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 
 	"go.opentelemetry.io/collector/config"
@@ -40,7 +44,7 @@ func TestExportEnqueueFailure(t *testing.T) {
 		Level:                  configtelemetry.LevelNormal,
 		ExporterID:             exporter,
 		ExporterCreateSettings: tt.ToExporterCreateSettings(),
-	}, insts)
+	}, config.LogsDataType, insts)
 
 	logRecords := int64(7)
 	obsrep.recordLogsEnqueueFailure(context.Background(), logRecords)
@@ -55,6 +59,67 @@ func TestExportEnqueueFailure(t *testing.T) {
 	checkExporterEnqueueFailedMetricsStats(t, insts, exporter, metricPoints)
 }
 
+func TestExportQueueAndRetryMetrics(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	exporter := config.NewComponentID("fakeExporter")
+	obsrep := newObsExporter(obsreport.ExporterSettings{
+		Level:                  configtelemetry.LevelNormal,
+		ExporterID:             exporter,
+		ExporterCreateSettings: tt.ToExporterCreateSettings(),
+	}, config.TracesDataType, newInstruments(metric.NewRegistry()))
+
+	obsrep.recordRetry(context.Background())
+	obsrep.recordRetry(context.Background())
+	obsrep.recordQueueLatency(context.Background(), 3*time.Millisecond)
+	obsrep.recordSendLatency(context.Background(), 20*time.Millisecond)
+	obsrep.recordSendLatency(context.Background(), 2*time.Second)
+
+	tags := tagsForSignalView(exporter, config.TracesDataType)
+	checkSumForView(t, tags, 2, "exporter/retries")
+	checkDistributionForView(t, tags, 1, 3, "exporter/queue_latency")
+	checkDistributionForView(t, tags, 2, 2020, "exporter/send_latency")
+}
+
+// tagsForSignalView returns the tags that are needed for the exporter views reporting the signal.
+func tagsForSignalView(exporter config.ComponentID, signal config.DataType) []tag.Tag {
+	return []tag.Tag{
+		{Key: exporterTag, Value: exporter.String()},
+		{Key: signalTag, Value: string(signal)},
+	}
+}
+
+// checkSumForView checks the sum reported by the view with the given name for the given tags.
+func checkSumForView(t *testing.T, wantTags []tag.Tag, value float64, vName string) {
+	data := retrieveViewData(t, wantTags, vName)
+	require.IsType(t, &view.SumData{}, data)
+	assert.Equal(t, value, data.(*view.SumData).Value)
+}
+
+// checkDistributionForView checks the count and the sum of the values of the distribution reported by the view
+// with the given name for the given tags.
+func checkDistributionForView(t *testing.T, wantTags []tag.Tag, count int64, sum float64, vName string) {
+	data := retrieveViewData(t, wantTags, vName)
+	require.IsType(t, &view.DistributionData{}, data)
+	distribution := data.(*view.DistributionData)
+	assert.Equal(t, count, distribution.Count)
+	assert.InDelta(t, sum, distribution.Sum(), 0.001)
+}
+
+func retrieveViewData(t *testing.T, wantTags []tag.Tag, vName string) view.AggregationData {
+	rows, err := view.RetrieveData(vName)
+	require.NoError(t, err)
+	for _, row := range rows {
+		if reflect.DeepEqual(wantTags, row.Tags) {
+			return row.Data
+		}
+	}
+	require.Failf(t, "view data not found", "could not find tags %v in the rows of %s: %v", wantTags, vName, rows)
+	return nil
+}
+
 // checkExporterEnqueueFailedTracesStats checks that reported number of spans failed to enqueue match given values.
 // When this function is called it is required to also call SetupTelemetry as first thing.
 func checkExporterEnqueueFailedTracesStats(t *testing.T, insts *instruments, exporter config.ComponentID, spans int64) {
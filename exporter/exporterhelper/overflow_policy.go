@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opencensus.io/metric/metricdata"
+
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+)
+
+// OverflowPolicy defines what the sending queue does with a batch sent while it is full.
+type OverflowPolicy string
+
+const (
+	// OverflowRejectNew rejects the sent batch, this is the default.
+	OverflowRejectNew OverflowPolicy = "reject_new"
+	// OverflowDropOldest drops the oldest batches of the queue to make room for the sent batch.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock blocks the sender until there is room in the queue, up to the block timeout.
+	OverflowBlock OverflowPolicy = "block"
+)
+
+func validateOverflowPolicy(qCfg *QueueSettings) error {
+	switch qCfg.OverflowPolicy {
+	case "", OverflowRejectNew, OverflowDropOldest:
+	case OverflowBlock:
+		if qCfg.BlockTimeout <= 0 {
+			return errors.New("block timeout must be positive with the block overflow policy")
+		}
+	default:
+		return fmt.Errorf("unknown overflow policy %q", qCfg.OverflowPolicy)
+	}
+	return nil
+}
+
+// newOverflowSettings returns the settings of the queue for the overflow policy, reporting the items of the batches
+// dropped to make room with the given exporter and signal label values.
+func newOverflowSettings(qCfg QueueSettings, labelValues []metricdata.LabelValue) internal.OverflowSettings {
+	overflow := internal.OverflowSettings{BlockTimeout: qCfg.BlockTimeout}
+	switch qCfg.OverflowPolicy {
+	case OverflowDropOldest:
+		overflow.Policy = internal.OverflowDropOldest
+	case OverflowBlock:
+		overflow.Policy = internal.OverflowBlock
+	default:
+		overflow.Policy = internal.OverflowRejectNew
+	}
+	evictedEntry, _ := globalInstruments.queueEvictedItems.GetEntry(labelValues...)
+	overflow.OnEvicted = func(item interface{}) {
+		if evictedEntry != nil {
+			evictedEntry.Inc(int64(item.(request).count()))
+		}
+	}
+	return overflow
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestQueueSettings_ValidateOverflowPolicy(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	assert.Equal(t, OverflowRejectNew, qCfg.OverflowPolicy)
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.OverflowPolicy = ""
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.OverflowPolicy = OverflowDropOldest
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.OverflowPolicy = OverflowBlock
+	assert.NoError(t, qCfg.Validate())
+	qCfg.BlockTimeout = 0
+	assert.EqualError(t, qCfg.Validate(), "block timeout must be positive with the block overflow policy")
+
+	qCfg.OverflowPolicy = "drop_newest"
+	assert.EqualError(t, qCfg.Validate(), `unknown overflow policy "drop_newest"`)
+}
+
+func TestLogsExporter_OverflowDropOldest(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var received []int
+	pusher := func(_ context.Context, ld pdata.Logs) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, ld.LogRecordCount())
+		return nil
+	}
+
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.QueueSize = 2
+	qCfg.OverflowPolicy = OverflowDropOldest
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "drop_oldest"))
+	be := newBaseExporter(&cfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	evictedTags := []tag.Tag{{Key: exporterTag, Value: "test/drop_oldest"}, {Key: signalTag, Value: ""}}
+	evicted := valueForGlobalManager(evictedTags, "exporter/queue_evicted_items")
+	send := func(numLogs int) error {
+		return be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(numLogs), pusher))
+	}
+
+	// The first logs are taken by the consumer, which is blocked.
+	require.NoError(t, send(1))
+	assert.Eventually(t, func() bool {
+		return be.qrSender.queue.Size() == 0
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, send(2))
+	require.NoError(t, send(3))
+	require.NoError(t, send(4))
+	require.NoError(t, send(5))
+	checkValueForGlobalManager(t, evictedTags, evicted+5, "exporter/queue_evicted_items")
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []int{1, 4, 5}, received)
+	require.NoError(t, be.Shutdown(context.Background()))
+}
+
+func TestLogsExporter_OverflowBlock(t *testing.T) {
+	release := make(chan struct{})
+	pusher := func(context.Context, pdata.Logs) error {
+		<-release
+		return nil
+	}
+
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.QueueSize = 1
+	qCfg.OverflowPolicy = OverflowBlock
+	qCfg.BlockTimeout = 100 * time.Millisecond
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "block"))
+	be := newBaseExporter(&cfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	send := func() error {
+		return be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher))
+	}
+
+	require.NoError(t, send())
+	assert.Eventually(t, func() bool {
+		return be.qrSender.queue.Size() == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, send())
+	// The queue is full until the consumer is released
+	assert.Equal(t, errSendingQueueIsFull, send())
+
+	// The wait for room is canceled with the context of the caller.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Equal(t, errSendingQueueIsFull, be.sender.send(newLogsRequest(ctx, testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.Less(t, time.Since(start), qCfg.BlockTimeout)
+
+	close(release)
+	require.NoError(t, send())
+	require.NoError(t, be.Shutdown(context.Background()))
+}
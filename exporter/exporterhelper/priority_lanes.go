@@ -0,0 +1,204 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opencensus.io/metric/metricdata"
+
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// PriorityLaneSettings defines a lane of the sending queue. Batches are put in a lane by the PriorityClassifier
+// of the exporter, and consumed with weighted fair dequeueing: while several lanes have batches, every lane gets
+// a share of the consumers proportional to its weight.
+type PriorityLaneSettings struct {
+	// Name is the name of the lane, returned by the PriorityClassifier.
+	Name string `mapstructure:"name"`
+	// Weight is the share of the batches sent from this lane when all the lanes have batches.
+	Weight int `mapstructure:"weight"`
+	// QueueSize is the maximum number of batches allowed in this lane at a given time.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+func validatePriorityLanes(lanes []PriorityLaneSettings) error {
+	names := map[string]bool{}
+	for _, lane := range lanes {
+		if lane.Name == "" {
+			return errors.New("priority lane name must not be empty")
+		}
+		if names[lane.Name] {
+			return fmt.Errorf("duplicate priority lane %q", lane.Name)
+		}
+		names[lane.Name] = true
+
+		if lane.Weight <= 0 {
+			return fmt.Errorf("priority lane %q weight must be positive", lane.Name)
+		}
+		if lane.QueueSize <= 0 {
+			return fmt.Errorf("priority lane %q queue size must be positive", lane.Name)
+		}
+	}
+	return nil
+}
+
+// PriorityClassifier returns the name of the priority lane of the data sent by an exporter.
+// Functions of the signals not classified can be nil. Data which is not classified, or classified
+// in a lane which is not configured, goes to the last lane.
+type PriorityClassifier struct {
+	Traces  func(pdata.Traces) string
+	Metrics func(pdata.Metrics) string
+	Logs    func(pdata.Logs) string
+}
+
+func (pc PriorityClassifier) classify(req request) string {
+	switch r := req.(type) {
+	case *tracesRequest:
+		if pc.Traces != nil {
+			return pc.Traces(r.td)
+		}
+	case *metricsRequest:
+		if pc.Metrics != nil {
+			return pc.Metrics(r.md)
+		}
+	case *logsRequest:
+		if pc.Logs != nil {
+			return pc.Logs(r.ld)
+		}
+	}
+	return ""
+}
+
+// NewResourceAttributeClassifier returns a PriorityClassifier using as lane name the value of the given attribute
+// of the first resource having it.
+func NewResourceAttributeClassifier(key string) PriorityClassifier {
+	laneOf := func(res pdata.Resource) (string, bool) {
+		if v, ok := res.Attributes().Get(key); ok {
+			return v.AsString(), true
+		}
+		return "", false
+	}
+	return PriorityClassifier{
+		Traces: func(td pdata.Traces) string {
+			for i := 0; i < td.ResourceSpans().Len(); i++ {
+				if lane, ok := laneOf(td.ResourceSpans().At(i).Resource()); ok {
+					return lane
+				}
+			}
+			return ""
+		},
+		Metrics: func(md pdata.Metrics) string {
+			for i := 0; i < md.ResourceMetrics().Len(); i++ {
+				if lane, ok := laneOf(md.ResourceMetrics().At(i).Resource()); ok {
+					return lane
+				}
+			}
+			return ""
+		},
+		Logs: func(ld pdata.Logs) string {
+			for i := 0; i < ld.ResourceLogs().Len(); i++ {
+				if lane, ok := laneOf(ld.ResourceLogs().At(i).Resource()); ok {
+					return lane
+				}
+			}
+			return ""
+		},
+	}
+}
+
+// NewSeverityClassifier returns a PriorityClassifier putting in the given lane the traces containing a span with
+// an error status, and the logs containing a log record with at least the given severity. Metrics are not classified.
+func NewSeverityClassifier(lane string, minSeverity pdata.SeverityNumber) PriorityClassifier {
+	return PriorityClassifier{
+		Traces: func(td pdata.Traces) string {
+			for i := 0; i < td.ResourceSpans().Len(); i++ {
+				sss := td.ResourceSpans().At(i).ScopeSpans()
+				for j := 0; j < sss.Len(); j++ {
+					spans := sss.At(j).Spans()
+					for k := 0; k < spans.Len(); k++ {
+						if spans.At(k).Status().Code() == pdata.StatusCodeError {
+							return lane
+						}
+					}
+				}
+			}
+			return ""
+		},
+		Logs: func(ld pdata.Logs) string {
+			for i := 0; i < ld.ResourceLogs().Len(); i++ {
+				sls := ld.ResourceLogs().At(i).ScopeLogs()
+				for j := 0; j < sls.Len(); j++ {
+					lrs := sls.At(j).LogRecords()
+					for k := 0; k < lrs.Len(); k++ {
+						if lrs.At(k).SeverityNumber() >= minSeverity {
+							return lane
+						}
+					}
+				}
+			}
+			return ""
+		},
+	}
+}
+
+// newPriorityQueue creates the queue with the configured lanes, reporting the batches dropped in every lane with the
+// given exporter and signal label values.
+func newPriorityQueue(qCfg QueueSettings, classifier PriorityClassifier, labelValues []metricdata.LabelValue) internal.PriorityQueue {
+	lanes := make([]internal.PriorityLane, 0, len(qCfg.PriorityLanes))
+	laneIdx := map[string]int{}
+	for i, lane := range qCfg.PriorityLanes {
+		lanes = append(lanes, internal.PriorityLane{Weight: lane.Weight, Capacity: lane.QueueSize})
+		laneIdx[lane.Name] = i
+	}
+	return internal.NewPriorityQueue(lanes, qCfg.QueueSizeBytes, requestByteSize, newOverflowSettings(qCfg, labelValues),
+		func(item interface{}) int {
+			if idx, ok := laneIdx[classifier.classify(item.(request))]; ok {
+				return idx
+			}
+			return -1
+		},
+		func(item interface{}, lane int) {
+			entry, err := globalInstruments.queueLaneDropped.GetEntry(
+				append(append([]metricdata.LabelValue{}, labelValues...), metricdata.NewLabelValue(qCfg.PriorityLanes[lane].Name))...)
+			if err == nil {
+				entry.Inc(1)
+			}
+		})
+}
+
+// reportPriorityLaneSizes starts or stops (if stop is true) reporting the size of every lane of the queue.
+func reportPriorityLaneSizes(qCfg QueueSettings, queue internal.ProducerConsumerQueue, labelValues []metricdata.LabelValue, stop bool) error {
+	pq, ok := queue.(internal.PriorityQueue)
+	if !ok {
+		return nil
+	}
+	for i, lane := range qCfg.PriorityLanes {
+		i := i
+		laneLabelValues := append(append([]metricdata.LabelValue{}, labelValues...), metricdata.NewLabelValue(lane.Name))
+		err := globalInstruments.queueLaneSize.UpsertEntry(func() int64 {
+			if stop {
+				return 0
+			}
+			return int64(pq.LaneSize(i))
+		}, laneLabelValues...)
+		if err != nil {
+			return fmt.Errorf("failed to create queue lane size metric: %v", err)
+		}
+	}
+	return nil
+}
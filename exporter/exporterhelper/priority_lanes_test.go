@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestQueueSettings_ValidatePriorityLanes(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.QueueSize = 0
+	qCfg.PriorityLanes = []PriorityLaneSettings{{Name: "high", Weight: 3, QueueSize: 100}, {Name: "low", Weight: 1, QueueSize: 1000}}
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.PriorityLanes[1].Name = ""
+	assert.EqualError(t, qCfg.Validate(), "priority lane name must not be empty")
+
+	qCfg.PriorityLanes[1].Name = "high"
+	assert.EqualError(t, qCfg.Validate(), `duplicate priority lane "high"`)
+
+	qCfg.PriorityLanes[1].Name = "low"
+	qCfg.PriorityLanes[1].Weight = 0
+	assert.EqualError(t, qCfg.Validate(), `priority lane "low" weight must be positive`)
+
+	qCfg.PriorityLanes[1].Weight = 1
+	qCfg.PriorityLanes[1].QueueSize = 0
+	assert.EqualError(t, qCfg.Validate(), `priority lane "low" queue size must be positive`)
+}
+
+func TestResourceAttributeClassifier(t *testing.T) {
+	classifier := NewResourceAttributeClassifier("resource-attr")
+	assert.Equal(t, "resource-attr-val-1", classifier.classify(newTracesRequest(context.Background(), testdata.GenerateTracesOneSpan(), nil)))
+	assert.Equal(t, "resource-attr-val-1", classifier.classify(newMetricsRequest(context.Background(), testdata.GenerateMetricsOneMetric(), nil)))
+	assert.Equal(t, "resource-attr-val-1", classifier.classify(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), nil)))
+
+	classifier = NewResourceAttributeClassifier("missing")
+	assert.Equal(t, "", classifier.classify(newTracesRequest(context.Background(), testdata.GenerateTracesOneSpan(), nil)))
+	assert.Equal(t, "", classifier.classify(newMockRequest(context.Background(), 1, nil)))
+}
+
+func TestSeverityClassifier(t *testing.T) {
+	classifier := NewSeverityClassifier("high", pdata.SeverityNumberERROR)
+
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	spans := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	for i := 0; i < spans.Len(); i++ {
+		spans.At(i).Status().SetCode(pdata.StatusCodeOk)
+	}
+	assert.Equal(t, "", classifier.classify(newTracesRequest(context.Background(), td, nil)))
+	spans.At(1).Status().SetCode(pdata.StatusCodeError)
+	assert.Equal(t, "high", classifier.classify(newTracesRequest(context.Background(), td, nil)))
+
+	ld := testdata.GenerateLogsTwoLogRecordsSameResource()
+	assert.Equal(t, "", classifier.classify(newLogsRequest(context.Background(), ld, nil)))
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(1).SetSeverityNumber(pdata.SeverityNumberFATAL)
+	assert.Equal(t, "high", classifier.classify(newLogsRequest(context.Background(), ld, nil)))
+
+	assert.Equal(t, "", classifier.classify(newMetricsRequest(context.Background(), testdata.GenerateMetricsOneMetric(), nil)))
+}
+
+func TestLogsExporter_PriorityLanes(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var received []pdata.SeverityNumber
+	pusher := func(_ context.Context, ld pdata.Logs) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SeverityNumber())
+		return nil
+	}
+	newLogs := func(severity pdata.SeverityNumber) pdata.Logs {
+		ld := testdata.GenerateLogsOneLogRecord()
+		ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SetSeverityNumber(severity)
+		return ld
+	}
+
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.PriorityLanes = []PriorityLaneSettings{{Name: "high", Weight: 2, QueueSize: 1}, {Name: "low", Weight: 1, QueueSize: 2}}
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "priority_lanes"))
+	be := newBaseExporter(&cfg, componenttest.NewNopExporterCreateSettings(),
+		fromOptions(WithQueue(qCfg), WithPriorityClassifier(NewSeverityClassifier("high", pdata.SeverityNumberERROR))), config.LogsDataType, nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	send := func(severity pdata.SeverityNumber) error {
+		return be.sender.send(newLogsRequest(context.Background(), newLogs(severity), pusher))
+	}
+
+	laneTag, _ := tag.NewKey("lane")
+	laneTags := func(lane string) []tag.Tag {
+		return []tag.Tag{{Key: exporterTag, Value: "test/priority_lanes"}, {Key: signalTag, Value: "logs"}, {Key: laneTag, Value: lane}}
+	}
+	dropped := valueForGlobalManager(laneTags("high"), "exporter/queue_lane_dropped")
+
+	// The first logs are taken by the consumer, which is blocked.
+	require.NoError(t, send(pdata.SeverityNumberINFO))
+	assert.Eventually(t, func() bool {
+		return be.qrSender.queue.Size() == 0
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, send(pdata.SeverityNumberDEBUG))
+	require.NoError(t, send(pdata.SeverityNumberDEBUG))
+	require.NoError(t, send(pdata.SeverityNumberERROR))
+	assert.Equal(t, errSendingQueueIsFull, send(pdata.SeverityNumberFATAL))
+
+	checkValueForGlobalManager(t, laneTags("high"), int64(1), "exporter/queue_lane_size")
+	checkValueForGlobalManager(t, laneTags("low"), int64(2), "exporter/queue_lane_size")
+	checkValueForGlobalManager(t, laneTags("high"), dropped+1, "exporter/queue_lane_dropped")
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 4
+	}, time.Second, time.Millisecond)
+	// The high priority logs are sent before the low priority logs queued before them.
+	assert.Equal(t, []pdata.SeverityNumber{pdata.SeverityNumberINFO, pdata.SeverityNumberERROR, pdata.SeverityNumberDEBUG, pdata.SeverityNumberDEBUG}, received)
+
+	require.NoError(t, be.Shutdown(context.Background()))
+	checkValueForGlobalManager(t, laneTags("low"), int64(0), "exporter/queue_lane_size")
+}
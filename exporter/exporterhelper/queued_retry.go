@@ -18,21 +18,344 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"go.opencensus.io/metric/metricdata"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 )
 
 var (
 	errSendingQueueIsFull = errors.New("sending_queue is full")
 )
 
+// QueueSettings defines configuration for queueing batches before sending to the consumerSender.
+type QueueSettings struct {
+	// Enabled indicates whether to not enqueue batches before sending to the consumerSender.
+	Enabled bool `mapstructure:"enabled"`
+	// NumConsumers is the number of consumers from the queue.
+	NumConsumers int `mapstructure:"num_consumers"`
+	// QueueSize is the maximum number of batches allowed in queue at a given time.
+	QueueSize int `mapstructure:"queue_size"`
+	// QueueSizeBytes is the maximum total size in bytes of the serialized batches allowed in queue at a given time.
+	// Zero means that the queue is bounded only by QueueSize.
+	QueueSizeBytes int64 `mapstructure:"queue_size_bytes"`
+	// PriorityLanes defines the lanes of the queue, the batches which are not classified in a lane go to the last one.
+	// When set, every lane is bounded by its own queue size and QueueSize is ignored.
+	PriorityLanes []PriorityLaneSettings `mapstructure:"priority_lanes"`
+	// OverflowPolicy defines what to do with the batches sent while the queue is full.
+	OverflowPolicy OverflowPolicy `mapstructure:"overflow_policy"`
+	// BlockTimeout is the maximum time to wait for room in the queue with the block overflow policy.
+	BlockTimeout time.Duration `mapstructure:"block_timeout"`
+	// DrainTimeout is the maximum time to keep sending the queued batches on shutdown, each one tried once.
+	// Zero means that the queued batches are not sent on shutdown.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+	// AdaptiveConcurrency defines how to adapt the number of active consumers, up to NumConsumers, to the backend load.
+	AdaptiveConcurrency AdaptiveConcurrencySettings `mapstructure:"adaptive_concurrency"`
+	// StorageID if not nil, enables the persistence of the queue in the storage extension with this ID.
+	StorageID *config.ComponentID `mapstructure:"storage"`
+}
+
+// NewDefaultQueueSettings returns the default settings for QueueSettings.
+func NewDefaultQueueSettings() QueueSettings {
+	return QueueSettings{
+		Enabled:      true,
+		NumConsumers: 10,
+		// For 5000 queue elements at 100 requests/sec gives about 50 sec of survival of destination outage.
+		// This is a pretty decent value for production.
+		// User should calculate this from the perspective of how many seconds to buffer in case of a backend outage,
+		// multiply that by the number of requests per seconds.
+		QueueSize:           5000,
+		OverflowPolicy:      OverflowRejectNew,
+		BlockTimeout:        5 * time.Second,
+		AdaptiveConcurrency: NewDefaultAdaptiveConcurrencySettings(),
+	}
+}
+
+// Validate checks if the QueueSettings configuration is valid
+func (qCfg *QueueSettings) Validate() error {
+	if !qCfg.Enabled {
+		return nil
+	}
+
+	if qCfg.QueueSize <= 0 && len(qCfg.PriorityLanes) == 0 {
+		return fmt.Errorf("queue size must be positive")
+	}
+
+	if err := validatePriorityLanes(qCfg.PriorityLanes); err != nil {
+		return err
+	}
+
+	if qCfg.StorageID != nil && len(qCfg.PriorityLanes) > 0 {
+		return fmt.Errorf("priority lanes are not supported with persistent storage")
+	}
+
+	if qCfg.QueueSizeBytes < 0 {
+		return fmt.Errorf("queue size in bytes must not be negative")
+	}
+
+	if err := validateOverflowPolicy(qCfg); err != nil {
+		return err
+	}
+
+	if qCfg.DrainTimeout < 0 {
+		return fmt.Errorf("drain timeout must not be negative")
+	}
+
+	if err := qCfg.AdaptiveConcurrency.Validate(qCfg.NumConsumers); err != nil {
+		return fmt.Errorf("adaptive concurrency has invalid configuration: %w", err)
+	}
+
+	return nil
+}
+
+var (
+	errNoStorageClient    = errors.New("no storage client extension found")
+	errWrongExtensionType = errors.New("requested extension is not a storage extension")
+)
+
+type queuedRetrySender struct {
+	id                 config.ComponentID
+	signal             config.DataType
+	fullName           string
+	cfg                QueueSettings
+	consumerSender     requestSender
+	queue              internal.ProducerConsumerQueue
+	retryStopCh        chan struct{}
+	sendStopCh         chan struct{}
+	stopSendOnce       sync.Once
+	traceAttributes    []attribute.KeyValue
+	logger             *zap.Logger
+	requeuingEnabled   bool
+	requestUnmarshaler internal.RequestUnmarshaler
+	deadLetterCfg      DeadLetterSettings
+	deadLetter         deadLetterSink
+	replayCancel       context.CancelFunc
+	replayWG           sync.WaitGroup
+	limiter            *concurrencyLimiter
+	batcher            *batchSender
+	drainer            *queueDrainer
+	obsrep             *obsExporter
+}
+
+// persistentQueueName returns the name of the persistent queue, unique for every signal of an exporter.
+func (qrs *queuedRetrySender) persistentQueueName() string {
+	if qrs.signal == "" {
+		return qrs.id.String()
+	}
+	return fmt.Sprintf("%s-%s", qrs.id.String(), qrs.signal)
+}
+
+// requestByteSize is the internal.ByteSizer of the requests, used to bound the sending queue by size.
+func requestByteSize(item interface{}) int64 {
+	return int64(item.(request).byteSize())
+}
+
+func newQueuedRetrySender(id config.ComponentID, signal config.DataType, bs *baseSettings, reqUnmarshaler internal.RequestUnmarshaler, nextSender requestSender, obsrep *obsExporter, logger *zap.Logger) *queuedRetrySender {
+	qCfg := bs.QueueSettings
+	retryStopCh := make(chan struct{})
+	sampledLogger := createSampledLogger(logger)
+	traceAttr := attribute.String(obsmetrics.ExporterKey, id.String())
+
+	qrs := &queuedRetrySender{
+		id:                 id,
+		signal:             signal,
+		fullName:           id.String(),
+		cfg:                qCfg,
+		retryStopCh:        retryStopCh,
+		sendStopCh:         make(chan struct{}),
+		traceAttributes:    []attribute.KeyValue{traceAttr},
+		logger:             sampledLogger,
+		requestUnmarshaler: reqUnmarshaler,
+		deadLetterCfg:      bs.RetrySettings.DeadLetter,
+		obsrep:             obsrep,
+	}
+	qrs.drainer = newQueueDrainer(qCfg.DrainTimeout, qrs.labelValues(), sampledLogger, qrs.onAbandoned)
+
+	qrs.initConsumerSender(bs, traceAttr, nextSender)
+
+	if len(qCfg.PriorityLanes) > 0 {
+		qrs.queue = newPriorityQueue(qCfg, bs.PriorityClassifier, qrs.labelValues())
+	} else if !qCfg.Enabled || qCfg.StorageID == nil {
+		qrs.queue = internal.NewBoundedMemoryQueue(qrs.cfg.QueueSize, qrs.cfg.QueueSizeBytes, requestByteSize, newOverflowSettings(qCfg, qrs.labelValues()), func(item interface{}) {})
+	}
+	// The Persistent Queue is initialized separately as it needs extra information about the component
+
+	return qrs
+}
+
+func getStorageClient(ctx context.Context, host component.Host, storageID config.ComponentID, id config.ComponentID, signal config.DataType) (storage.Client, error) {
+	ext, found := host.GetExtensions()[storageID]
+	if !found {
+		return nil, fmt.Errorf("failed to resolve storage %q: %w", storageID, errNoStorageClient)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, errWrongExtensionType
+	}
+
+	return storageExt.GetClient(ctx, component.KindExporter, id, string(signal))
+}
+
+// initializePersistentQueue uses extra information for initialization available from component.Host
+func (qrs *queuedRetrySender) initializePersistentQueue(ctx context.Context, host component.Host) error {
+	if qrs.cfg.Enabled && qrs.cfg.StorageID != nil {
+		storageClient, err := getStorageClient(ctx, host, *qrs.cfg.StorageID, qrs.id, qrs.signal)
+		if err != nil {
+			return err
+		}
+
+		qrs.queue, err = internal.NewPersistentQueue(ctx, qrs.persistentQueueName(), qrs.cfg.QueueSize, qrs.cfg.QueueSizeBytes, newOverflowSettings(qrs.cfg, qrs.labelValues()), qrs.logger, storageClient, qrs.requestUnmarshaler)
+		if err != nil {
+			return err
+		}
+
+		// TODO: this can be further exposed as a config param rather than relying on a type of queue
+		qrs.requeuingEnabled = true
+	}
+
+	return nil
+}
+
+func (qrs *queuedRetrySender) onTemporaryFailure(logger *zap.Logger, req request, err error) error {
+	if !qrs.requeuingEnabled || qrs.queue == nil {
+		if qrs.putDeadLetter(logger, req, err) {
+			return err
+		}
+		logger.Error(
+			"Exporting failed. No more retries left. Dropping data.",
+			zap.Error(err),
+			zap.Int("dropped_items", req.count()),
+		)
+		return err
+	}
+
+	if qrs.requeue(req) {
+		logger.Error(
+			"Exporting failed. Putting back to the end of the queue.",
+			zap.Error(err),
+		)
+	} else if !qrs.putDeadLetter(logger, req, err) {
+		logger.Error(
+			"Exporting failed. Queue did not accept requeuing request. Dropping data.",
+			zap.Error(err),
+			zap.Int("dropped_items", req.count()),
+		)
+	}
+	return err
+}
+
+// onShutdownFailure handles the request which could not be sent because the exporter is shutting down.
+func (qrs *queuedRetrySender) onShutdownFailure(logger *zap.Logger, req request, err error) error {
+	qrs.putDeadLetter(logger, req, err)
+	return err
+}
+
+// onAbandoned handles the request taken from the queue after the drain was abandoned, without being sent.
+func (qrs *queuedRetrySender) onAbandoned(req request) {
+	qrs.putDeadLetter(qrs.logger, req, errDrainAbandoned)
+}
+
+// requeue puts the request back to the queue, merging it with the data being batched if the batcher is enabled.
+func (qrs *queuedRetrySender) requeue(req request) bool {
+	if qrs.batcher != nil {
+		return qrs.batcher.send(req) == nil
+	}
+	req.setEnqueuedTime(time.Now())
+	return qrs.queue.Produce(req.context(), req)
+}
+
+// start is invoked during service startup.
+func (qrs *queuedRetrySender) start(ctx context.Context, host component.Host) error {
+	err := qrs.initializePersistentQueue(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	qrs.queue.StartConsumers(qrs.cfg.NumConsumers, qrs.consume)
+
+	// Start reporting queue length metric
+	if qrs.cfg.Enabled {
+		err := globalInstruments.queueSize.UpsertEntry(func() int64 {
+			return int64(qrs.queue.Size())
+		}, qrs.labelValues()...)
+		if err != nil {
+			return fmt.Errorf("failed to create retry queue size metric: %v", err)
+		}
+		err = globalInstruments.queueCapacity.UpsertEntry(func() int64 {
+			return int64(queueCapacity(qrs.cfg))
+		}, qrs.labelValues()...)
+		if err != nil {
+			return fmt.Errorf("failed to create retry queue capacity metric: %v", err)
+		}
+		err = globalInstruments.queueConsumers.UpsertEntry(func() int64 {
+			return int64(qrs.activeConsumers())
+		}, qrs.labelValues()...)
+		if err != nil {
+			return fmt.Errorf("failed to create queue consumers metric: %v", err)
+		}
+		if err = reportPriorityLaneSizes(qrs.cfg, qrs.queue, qrs.labelValues(), false); err != nil {
+			return err
+		}
+	}
+
+	return qrs.startDeadLetter(ctx, host)
+}
+
+// shutdown is invoked during service shutdown, draining the queue stops when the context is done.
+func (qrs *queuedRetrySender) shutdown(ctx context.Context) {
+	// Stop replaying the dead letter first, the replayed requests would not be sent anymore.
+	qrs.stopDeadLetterReplay()
+
+	// Cleanup queue metrics reporting
+	if qrs.cfg.Enabled {
+		_ = globalInstruments.queueSize.UpsertEntry(func() int64 {
+			return int64(0)
+		}, qrs.labelValues()...)
+		_ = globalInstruments.queueCapacity.UpsertEntry(func() int64 {
+			return int64(0)
+		}, qrs.labelValues()...)
+		_ = globalInstruments.queueConsumers.UpsertEntry(func() int64 {
+			return int64(0)
+		}, qrs.labelValues()...)
+		_ = reportPriorityLaneSizes(qrs.cfg, qrs.queue, qrs.labelValues(), true)
+	}
+
+	// Keep sending the queued requests, which are tried once after stopping the retries, until the queue is empty
+	// or the drain timeout expires. The persistent queue keeps them in the storage instead.
+	draining := qrs.cfg.Enabled && qrs.cfg.StorageID == nil && qrs.drainer.begin()
+
+	// First Stop the retry goroutines, so that unblocks the queue numWorkers.
+	close(qrs.retryStopCh)
+
+	if draining {
+		qrs.drainer.drain(ctx, qrs.queue, qrs.stopLimiter)
+	}
+
+	// Unblock the consumers waiting for the concurrency limit or the rate limits, so they can finish.
+	qrs.stopLimiter()
+
+	// Stop the queued sender, this will drain the queue and will call the retry (which is stopped) that will only
+	// try once every request.
+	if qrs.queue != nil {
+		qrs.queue.Stop()
+	}
+
+	qrs.shutdownDeadLetter()
+}
+
 // RetrySettings defines configuration for retrying batches in case of export failure.
 // The current supported strategy is exponential backoff.
 type RetrySettings struct {
@@ -46,16 +369,44 @@ type RetrySettings struct {
 	// MaxElapsedTime is the maximum amount of time (including retries) spent trying to send a request/batch.
 	// Once this value is reached, the data is discarded.
 	MaxElapsedTime time.Duration `mapstructure:"max_elapsed_time"`
+	// MaxThrottleDelay is the upper bound on the delay requested by the backend before retrying, e.g. with
+	// a Retry-After header or a gRPC RetryInfo. Zero means that the requested delay is not bounded.
+	MaxThrottleDelay time.Duration `mapstructure:"max_throttle_delay"`
+	// Policies overrides the backoff used for the errors of some classes, e.g. to wait longer when throttled.
+	Policies map[RetryErrorClass]RetryPolicySettings `mapstructure:"policies"`
+	// Budget limits the number of retries relative to the number of requests sent.
+	Budget RetryBudgetSettings `mapstructure:"budget"`
+	// DeadLetter defines where to store the data discarded after exhausting retries, so it can be replayed later.
+	DeadLetter DeadLetterSettings `mapstructure:"dead_letter"`
 }
 
 // NewDefaultRetrySettings returns the default settings for RetrySettings.
 func NewDefaultRetrySettings() RetrySettings {
 	return RetrySettings{
-		Enabled:         true,
-		InitialInterval: 5 * time.Second,
-		MaxInterval:     30 * time.Second,
-		MaxElapsedTime:  5 * time.Minute,
+		Enabled:          true,
+		InitialInterval:  5 * time.Second,
+		MaxInterval:      30 * time.Second,
+		MaxElapsedTime:   5 * time.Minute,
+		MaxThrottleDelay: 5 * time.Minute,
+		Budget:           NewDefaultRetryBudgetSettings(),
+	}
+}
+
+// Validate checks if the RetrySettings configuration is valid
+func (rCfg *RetrySettings) Validate() error {
+	if rCfg.Enabled {
+		if rCfg.MaxThrottleDelay < 0 {
+			return errors.New("max throttle delay must not be negative")
+		}
+		if err := validateRetryPolicies(rCfg.Policies); err != nil {
+			return err
+		}
+		if err := rCfg.Budget.Validate(); err != nil {
+			return fmt.Errorf("retry budget has invalid configuration: %w", err)
+		}
 	}
+
+	return rCfg.DeadLetter.Validate()
 }
 
 func createSampledLogger(logger *zap.Logger) *zap.Logger {
@@ -92,12 +443,15 @@ func (qrs *queuedRetrySender) send(req request) error {
 
 	// Prevent cancellation and deadline to propagate to the context stored in the queue.
 	// The grpc/http based receivers will cancel the request context after this function returns.
-	req.setContext(noCancellationContext{Context: req.context()})
+	// The original context still cancels the wait for room in the queue with the block overflow policy.
+	ctx := req.context()
+	req.setContext(noCancellationContext{Context: ctx})
 
-	span := trace.SpanFromContext(req.context())
-	if !qrs.queue.Produce(req) {
+	span := trace.SpanFromContext(ctx)
+	req.setEnqueuedTime(time.Now())
+	if !qrs.queue.Produce(ctx, req) {
 		qrs.logger.Error(
-			"Dropping data because sending_queue is full. Try increasing queue_size.",
+			"Dropping data because sending_queue is full. Try increasing queue_size or queue_size_bytes.",
 			zap.Int("dropped_items", req.count()),
 		)
 		span.AddEvent("Dropped item, sending_queue is full.", trace.WithAttributes(qrs.traceAttributes...))
@@ -108,6 +462,120 @@ func (qrs *queuedRetrySender) send(req request) error {
 	return nil
 }
 
+// consume sends the request taken from the queue, within the concurrency limit when adaptive concurrency is enabled.
+func (qrs *queuedRetrySender) consume(item interface{}) {
+	req := item.(request)
+	// The time spent in the queue is unknown for the requests restored from the persistent storage.
+	if enqueued := req.enqueuedTime(); !enqueued.IsZero() {
+		qrs.obsrep.recordQueueLatency(req.context(), time.Since(enqueued))
+	}
+	qrs.drainer.consume(req, func(req request) error {
+		if qrs.limiter != nil {
+			qrs.limiter.acquire()
+			defer qrs.limiter.release()
+		}
+		return qrs.consumerSender.send(req)
+	})
+	req.OnProcessingFinished()
+}
+
+// stopLimiter unblocks the consumers waiting for the concurrency limit or the rate limits. The rate limits keep
+// applying to the requests sent while draining the queue, until stopLimiter is called.
+func (qrs *queuedRetrySender) stopLimiter() {
+	qrs.stopSendOnce.Do(func() {
+		close(qrs.sendStopCh)
+	})
+	if qrs.limiter != nil {
+		qrs.limiter.stop()
+	}
+}
+
+// labelValues returns the values of the exporter and signal labels of the queue metrics. The exporters of every signal
+// with the same ID have their own queue, reported with the signal label.
+func (qrs *queuedRetrySender) labelValues() []metricdata.LabelValue {
+	return []metricdata.LabelValue{metricdata.NewLabelValue(qrs.fullName), metricdata.NewLabelValue(string(qrs.signal))}
+}
+
+// queueCapacity returns the maximum number of batches in the queue, the sum of the capacities of the priority lanes.
+func queueCapacity(qCfg QueueSettings) int {
+	if len(qCfg.PriorityLanes) == 0 {
+		return qCfg.QueueSize
+	}
+	capacity := 0
+	for _, lane := range qCfg.PriorityLanes {
+		capacity += lane.QueueSize
+	}
+	return capacity
+}
+
+// activeConsumers returns the number of queue consumers currently allowed to send requests.
+func (qrs *queuedRetrySender) activeConsumers() int {
+	if qrs.limiter != nil {
+		return qrs.limiter.currentLimit()
+	}
+	return qrs.cfg.NumConsumers
+}
+
+// initConsumerSender builds the chain of senders used for every request, either taken from the queue or sent directly:
+// circuit breaker -> retry -> (for every attempt) circuit breaker -> rate limiter -> hedging -> (for every duplicate)
+// concurrency limiter -> latency -> nextSender.
+func (qrs *queuedRetrySender) initConsumerSender(bs *baseSettings, traceAttr attribute.KeyValue, nextSender requestSender) {
+	nextSender = &sendLatencySender{obsrep: qrs.obsrep, nextSender: nextSender}
+
+	if qrs.cfg.Enabled && qrs.cfg.AdaptiveConcurrency.Enabled {
+		qrs.limiter = newConcurrencyLimiter(qrs.cfg.AdaptiveConcurrency, qrs.cfg.NumConsumers, nextSender)
+		nextSender = qrs.limiter
+	}
+
+	var rl *rateLimiter
+	if bs.RateLimitSettings.Enabled {
+		rl = newRateLimiter(bs.RateLimitSettings, qrs.sendStopCh, nil)
+	}
+
+	if bs.HedgingSettings.Enabled {
+		var permits hedgePermits
+		if qrs.limiter != nil {
+			permits = qrs.limiter
+		}
+		var tokens hedgeTokens
+		if rl != nil {
+			tokens = rl
+		}
+		nextSender = newHedgingSender(bs.HedgingSettings, permits, tokens, nextSender)
+	}
+
+	if rl != nil {
+		// Time spent waiting for the rate limiter must neither be observed as backend latency by the concurrency
+		// limiter nor trigger a hedged request.
+		rl.nextSender = nextSender
+		nextSender = rl
+	}
+
+	var breaker *circuitBreaker
+	if bs.CircuitBreakerSettings.Enabled {
+		breaker = newCircuitBreaker(bs.CircuitBreakerSettings, qrs.labelValues(), qrs.retryStopCh, qrs.logger)
+		nextSender = &circuitBreakerAttemptSender{breaker: breaker, nextSender: nextSender}
+	}
+
+	qrs.consumerSender = &retrySender{
+		traceAttribute:     traceAttr,
+		cfg:                bs.RetrySettings,
+		requestTimeout:     bs.TimeoutSettings.RequestTimeout,
+		budget:             newRetryBudget(bs.RetrySettings.Budget),
+		nextSender:         nextSender,
+		stopCh:             qrs.retryStopCh,
+		logger:             qrs.logger,
+		obsrep:             qrs.obsrep,
+		onTemporaryFailure: qrs.onTemporaryFailure,
+		onShutdownFailure:  qrs.onShutdownFailure,
+	}
+
+	if breaker != nil {
+		// Queued requests wait while the circuit is open, the others are rejected.
+		qrs.consumerSender = &circuitBreakerSender{breaker: breaker, wait: qrs.cfg.Enabled, nextSender: qrs.consumerSender, onShutdownFailure: qrs.onShutdownFailure, logger: qrs.logger}
+	}
+}
+
 // TODO: Clean this by forcing all exporters to return an internal error type that always include the information about retries.
 type throttleRetry struct {
 	err   error
@@ -135,17 +603,49 @@ type onRequestHandlingFinishedFunc func(*zap.Logger, request, error) error
 type retrySender struct {
 	traceAttribute     attribute.KeyValue
 	cfg                RetrySettings
+	requestTimeout     time.Duration
+	budget             *retryBudget
 	nextSender         requestSender
 	stopCh             chan struct{}
 	logger             *zap.Logger
+	obsrep             *obsExporter
 	onTemporaryFailure onRequestHandlingFinishedFunc
+	onShutdownFailure  onRequestHandlingFinishedFunc
 }
 
 // send implements the requestSender interface
 func (rs *retrySender) send(req request) error {
+	if rs.requestTimeout <= 0 {
+		return rs.sendWithRetries(req, time.Time{}, rs.onTemporaryFailure)
+	}
+
+	// Bound all the attempts and the backoff between them. The request gets back its own context when it is done,
+	// or before being requeued, so the timeout starts again the next time it is sent.
+	parentCtx := req.context()
+	deadline := time.Now().Add(rs.requestTimeout)
+	ctx, cancel := context.WithDeadline(parentCtx, deadline)
+	defer cancel()
+	req.setContext(ctx)
+	var handedOver request
+	err := rs.sendWithRetries(req, deadline, func(logger *zap.Logger, failed request, err error) error {
+		failed.setContext(parentCtx)
+		handedOver = failed
+		return rs.onTemporaryFailure(logger, failed, err)
+	})
+	// The request handed over to onTemporaryFailure may already be requeued and used by another goroutine, e.g.
+	// as the pending batch of the batcher, so its context must not be changed anymore.
+	if handedOver != req {
+		req.setContext(parentCtx)
+	}
+	return err
+}
+
+// sendWithRetries sends the request until it succeeds, fails with a permanent error or the retries are exhausted,
+// before the deadline if it is not zero.
+func (rs *retrySender) sendWithRetries(req request, deadline time.Time, onTemporaryFailure onRequestHandlingFinishedFunc) error {
 	if !rs.cfg.Enabled {
 		err := rs.nextSender.send(req)
-		if err != nil {
+		if err != nil && !errors.Is(err, errCircuitBreakerOpen) {
 			rs.logger.Error(
 				"Exporting failed. Try enabling retry_on_failure config option.",
 				zap.Error(err),
@@ -154,18 +654,14 @@ func (rs *retrySender) send(req request) error {
 		return err
 	}
 
-	// Do not use NewExponentialBackOff since it calls Reset and the code here must
-	// call Reset after changing the InitialInterval (this saves an unnecessary call to Now).
-	expBackoff := backoff.ExponentialBackOff{
-		InitialInterval:     rs.cfg.InitialInterval,
-		RandomizationFactor: backoff.DefaultRandomizationFactor,
-		Multiplier:          backoff.DefaultMultiplier,
-		MaxInterval:         rs.cfg.MaxInterval,
-		MaxElapsedTime:      rs.cfg.MaxElapsedTime,
-		Stop:                backoff.Stop,
-		Clock:               backoff.SystemClock,
+	if rs.budget != nil {
+		rs.budget.onSend()
 	}
-	expBackoff.Reset()
+
+	// Every class of errors has its own backoff, created on the first failure of the class.
+	// The elapsed time is always measured from the first attempt to send the request.
+	backoffs := map[RetryErrorClass]*backoff.ExponentialBackOff{}
+	start := time.Now()
 	span := trace.SpanFromContext(req.context())
 	retryNum := int64(0)
 	for {
@@ -178,12 +674,23 @@ func (rs *retrySender) send(req request) error {
 			return nil
 		}
 
+		// The request was not sent because the circuit is open, it is not a failed attempt.
+		if errors.Is(err, errCircuitBreakerOpen) {
+			return err
+		}
+
 		// Immediately drop data on permanent errors.
 		if consumererror.IsPermanent(err) {
+			// Only the failed items are dropped when the backend accepted part of the request.
+			dropped := req.count()
+			var partial consumererror.Partial
+			if errors.As(err, &partial) {
+				dropped = partial.Failed()
+			}
 			rs.logger.Error(
 				"Exporting failed. The error is not retryable. Dropping data.",
 				zap.Error(err),
-				zap.Int("dropped_items", req.count()),
+				zap.Int("dropped_items", dropped),
 			)
 			return err
 		}
@@ -192,17 +699,36 @@ func (rs *retrySender) send(req request) error {
 		// failed to process.
 		req = req.onError(err)
 
+		class := classifyRetryError(err)
+		policy := rs.cfg.policy(class)
+		expBackoff, ok := backoffs[class]
+		if !ok {
+			expBackoff = newExponentialBackOff(policy)
+			backoffs[class] = expBackoff
+		}
 		backoffDelay := expBackoff.NextBackOff()
-		if backoffDelay == backoff.Stop {
+
+		if policy.MaxElapsedTime != 0 && time.Since(start)+backoffDelay > policy.MaxElapsedTime {
 			// throw away the batch
 			err = fmt.Errorf("max elapsed time expired %w", err)
-			return rs.onTemporaryFailure(rs.logger, req, err)
+			return onTemporaryFailure(rs.logger, req, err)
+		}
+
+		if rs.budget != nil && !rs.budget.tryRetry() {
+			err = fmt.Errorf("retry budget exhausted %w", err)
+			return onTemporaryFailure(rs.logger, req, err)
 		}
 
 		throttleErr := throttleRetry{}
 		isThrottle := errors.As(err, &throttleErr)
 		if isThrottle {
-			backoffDelay = max(backoffDelay, throttleErr.delay)
+			backoffDelay = max(backoffDelay, rs.throttleDelay(throttleErr.delay))
+		}
+
+		if !deadline.IsZero() && time.Until(deadline) < backoffDelay {
+			// throw away the batch, it cannot be retried before the request timeout
+			err = fmt.Errorf("request timeout expired %w", err)
+			return onTemporaryFailure(rs.logger, req, err)
 		}
 
 		backoffDelayStr := backoffDelay.String()
@@ -224,12 +750,57 @@ func (rs *retrySender) send(req request) error {
 		case <-req.context().Done():
 			return fmt.Errorf("request is cancelled or timed out %w", err)
 		case <-rs.stopCh:
-			return fmt.Errorf("interrupted due to shutdown %w", err)
+			return rs.onShutdownFailure(rs.logger, req, fmt.Errorf("interrupted due to shutdown %w", err))
 		case <-time.After(backoffDelay):
+			rs.obsrep.recordRetry(req.context())
 		}
 	}
 }
 
+// sendLatencySender records the duration of every attempt to send a request to the backend.
+type sendLatencySender struct {
+	obsrep     *obsExporter
+	nextSender requestSender
+}
+
+// send implements the requestSender interface
+func (ls *sendLatencySender) send(req request) error {
+	start := time.Now()
+	err := ls.nextSender.send(req)
+	ls.obsrep.recordSendLatency(req.context(), time.Since(start))
+	return err
+}
+
+// throttleDelay bounds the delay requested by the backend, so a misbehaving backend cannot stall the exporter.
+func (rs *retrySender) throttleDelay(delay time.Duration) time.Duration {
+	if rs.cfg.MaxThrottleDelay > 0 && delay > rs.cfg.MaxThrottleDelay {
+		rs.logger.Warn(
+			"Delay requested by the backend is too long, retrying after max_throttle_delay.",
+			zap.String("requested_delay", delay.String()),
+			zap.String("max_throttle_delay", rs.cfg.MaxThrottleDelay.String()),
+		)
+		return rs.cfg.MaxThrottleDelay
+	}
+	return delay
+}
+
+// newExponentialBackOff returns the backoff for the policy, which never stops by itself.
+func newExponentialBackOff(policy RetryPolicySettings) *backoff.ExponentialBackOff {
+	// Do not use NewExponentialBackOff since it calls Reset and the code here must
+	// call Reset after changing the InitialInterval (this saves an unnecessary call to Now).
+	expBackoff := &backoff.ExponentialBackOff{
+		InitialInterval:     policy.InitialInterval,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         policy.MaxInterval,
+		MaxElapsedTime:      0,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+	expBackoff.Reset()
+	return expBackoff
+}
+
 // max returns the larger of x or y.
 func max(x, y time.Duration) time.Duration {
 	if x < y {
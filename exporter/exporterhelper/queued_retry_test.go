@@ -27,9 +27,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/metric/metricdata"
 	"go.opencensus.io/metric/metricproducer"
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 	"go.opentelemetry.io/collector/internal/testdata"
@@ -256,6 +259,39 @@ func TestQueuedRetry_ThrottleError(t *testing.T) {
 	require.Zero(t, be.qrSender.queue.Size())
 }
 
+func TestQueuedRetry_ThrottleErrorMaxDelay(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = 10 * time.Millisecond
+	rCfg.MaxThrottleDelay = 50 * time.Millisecond
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	retry := NewThrottleRetry(errors.New("throttle error"), time.Hour)
+	mockR := newMockRequest(context.Background(), 2, retry)
+	start := time.Now()
+	ocs.run(func() {
+		// This is asynchronous so it should just enqueue, no errors expected.
+		require.NoError(t, be.sender.send(mockR))
+	})
+	ocs.awaitAsyncProcessing()
+
+	// The backend asks to wait 1h, but the delay is bounded to 50ms.
+	waitingTime := time.Since(start)
+	assert.Less(t, 50*time.Millisecond, waitingTime)
+	assert.Less(t, waitingTime, time.Minute)
+
+	mockR.checkNumRequests(t, 2)
+	ocs.checkSendItemsCount(t, 2)
+	ocs.checkDroppedItemsCount(t, 0)
+}
+
 func TestQueuedRetry_RetryOnError(t *testing.T) {
 	qCfg := NewDefaultQueueSettings()
 	qCfg.NumConsumers = 1
@@ -299,6 +335,24 @@ func TestQueuedRetry_DropOnFull(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestQueuedRetry_DropOnFullBytes(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 0 // to make every request stay in the queue
+	qCfg.QueueSizeBytes = 10
+	rCfg := NewDefaultRetrySettings()
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	require.NoError(t, be.sender.send(newMockRequest(context.Background(), 6, nil)))
+	assert.ErrorIs(t, be.sender.send(newMockRequest(context.Background(), 6, nil)), errSendingQueueIsFull)
+	require.NoError(t, be.sender.send(newMockRequest(context.Background(), 4, nil)))
+	assert.EqualValues(t, 10, be.qrSender.queue.SizeBytes())
+	assert.EqualValues(t, 2, be.qrSender.queue.Size())
+}
+
 func TestQueuedRetryHappyPath(t *testing.T) {
 	tt, err := obsreporttest.SetupTelemetry()
 	require.NoError(t, err)
@@ -340,16 +394,56 @@ func TestQueuedRetry_QueueMetricsReported(t *testing.T) {
 	qCfg := NewDefaultQueueSettings()
 	qCfg.NumConsumers = 0 // to make every request go straight to the queue
 	rCfg := NewDefaultRetrySettings()
-	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
-	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	// The exporters of different signals with the same ID report the size of their own queue.
+	traces := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), config.TracesDataType, nopRequestUnmarshaler())
+	require.NoError(t, traces.Start(context.Background(), componenttest.NewNopHost()))
+	logs := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), config.LogsDataType, nopRequestUnmarshaler())
+	require.NoError(t, logs.Start(context.Background(), componenttest.NewNopHost()))
 
 	for i := 0; i < 7; i++ {
-		require.NoError(t, be.sender.send(newErrorRequest(context.Background())))
+		require.NoError(t, traces.sender.send(newErrorRequest(context.Background())))
+	}
+	require.NoError(t, logs.sender.send(newErrorRequest(context.Background())))
+	checkValueForGlobalManager(t, queueGaugeTags(config.TracesDataType), int64(7), "exporter/queue_size")
+	checkValueForGlobalManager(t, queueGaugeTags(config.TracesDataType), int64(qCfg.QueueSize), "exporter/queue_capacity")
+	checkValueForGlobalManager(t, queueGaugeTags(config.LogsDataType), int64(1), "exporter/queue_size")
+
+	assert.NoError(t, traces.Shutdown(context.Background()))
+	checkValueForGlobalManager(t, queueGaugeTags(config.TracesDataType), int64(0), "exporter/queue_size")
+	checkValueForGlobalManager(t, queueGaugeTags(config.TracesDataType), int64(0), "exporter/queue_capacity")
+	checkValueForGlobalManager(t, queueGaugeTags(config.LogsDataType), int64(1), "exporter/queue_size")
+	assert.NoError(t, logs.Shutdown(context.Background()))
+}
+
+func TestQueuedRetry_LatencyAndRetryMetricsReported(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	attempts := int64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		if atomic.AddInt64(&attempts, 1) <= 2 {
+			return errors.New("transient error")
+		}
+		return nil
 	}
-	checkValueForGlobalManager(t, defaultExporterTags, int64(7), "exporter/queue_size")
 
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "latency"))
+	be := newBaseExporter(&cfg, tt.ToExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(NewDefaultQueueSettings())), config.LogsDataType, nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&attempts) == 3
+	}, time.Second, time.Millisecond)
 	assert.NoError(t, be.Shutdown(context.Background()))
-	checkValueForGlobalManager(t, defaultExporterTags, int64(0), "exporter/queue_size")
+
+	// The request waits in the queue once, and is sent 3 times.
+	tags := tagsForSignalView(cfg.ID(), config.LogsDataType)
+	checkSumForView(t, tags, 2, "exporter/retries")
+	assert.EqualValues(t, 1, retrieveViewData(t, tags, "exporter/queue_latency").(*view.DistributionData).Count)
+	assert.EqualValues(t, 3, retrieveViewData(t, tags, "exporter/send_latency").(*view.DistributionData).Count)
 }
 
 func TestNoCancellationContext(t *testing.T) {
@@ -375,11 +469,81 @@ func TestQueueSettings_Validate(t *testing.T) {
 	qCfg.QueueSize = 0
 	assert.EqualError(t, qCfg.Validate(), "queue size must be positive")
 
+	qCfg = NewDefaultQueueSettings()
+	qCfg.QueueSizeBytes = -1
+	assert.EqualError(t, qCfg.Validate(), "queue size in bytes must not be negative")
+
 	// Confirm Validate doesn't return error with invalid config when feature is disabled
 	qCfg.Enabled = false
 	assert.NoError(t, qCfg.Validate())
 }
 
+func TestQueueSettings_ValidatePersistentStorage(t *testing.T) {
+	storageID := config.NewComponentID("storage")
+	qCfg := NewDefaultQueueSettings()
+	qCfg.StorageID = &storageID
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.PriorityLanes = []PriorityLaneSettings{{Name: "high", Weight: 1, QueueSize: 10}}
+	assert.EqualError(t, qCfg.Validate(), "priority lanes are not supported with persistent storage")
+}
+
+func TestQueuedRetry_PersistentStorage(t *testing.T) {
+	storageID := config.NewComponentIDWithName("storage", "queue")
+	client := newMockStorageClient()
+	host := &storageHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		config.NewComponentID("storage"): &mockStorageExtension{client: newMockStorageClient()},
+		storageID:                        &mockStorageExtension{client: client},
+	}}
+
+	var received []pdata.Traces
+	var mu sync.Mutex
+	pusher := func(_ context.Context, td pdata.Traces) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, td)
+		return nil
+	}
+
+	qCfg := NewDefaultQueueSettings()
+	qCfg.StorageID = &storageID
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	require.NoError(t, te.ConsumeTraces(context.Background(), td))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, td, received[0])
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	// The queue is kept in the selected storage extension.
+	wi, err := client.Get(context.Background(), "wi")
+	require.NoError(t, err)
+	assert.NotNil(t, wi)
+}
+
+func TestQueuedRetry_PersistentStorageNotFound(t *testing.T) {
+	storageID := config.NewComponentID("storage")
+	qCfg := NewDefaultQueueSettings()
+	qCfg.StorageID = &storageID
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), componenttest.NewNopHost()), errNoStorageClient)
+
+	host := &storageHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		storageID: struct {
+			component.StartFunc
+			component.ShutdownFunc
+		}{},
+	}}
+	be = newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), host), errWrongExtensionType)
+}
+
 type mockErrorRequest struct {
 	baseRequest
 }
@@ -400,6 +564,10 @@ func (mer *mockErrorRequest) count() int {
 	return 7
 }
 
+func (mer *mockErrorRequest) byteSize() int {
+	return 7
+}
+
 func newErrorRequest(ctx context.Context) request {
 	return &mockErrorRequest{
 		baseRequest: baseRequest{ctx: ctx},
@@ -450,6 +618,10 @@ func (m *mockRequest) count() int {
 	return m.cnt
 }
 
+func (m *mockRequest) byteSize() int {
+	return m.cnt
+}
+
 func newMockRequest(ctx context.Context, cnt int, consumeError error) *mockRequest {
 	return &mockRequest{
 		baseRequest:  baseRequest{ctx: ctx},
@@ -513,10 +685,12 @@ func checkValueForGlobalManager(t *testing.T, wantTags []tag.Tag, value int64, v
 // checkValueForProducer checks that the given metrics with wantTags is reported by the metric producer
 func checkValueForProducer(t *testing.T, producer metricproducer.Producer, wantTags []tag.Tag, value int64, vName string) bool {
 	for _, metric := range producer.Read() {
-		if metric.Descriptor.Name == vName && len(metric.TimeSeries) > 0 {
-			lastValue := metric.TimeSeries[len(metric.TimeSeries)-1]
-			if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, lastValue.LabelValues) {
-				require.Equal(t, value, lastValue.Points[len(lastValue.Points)-1].Value.(int64))
+		if metric.Descriptor.Name != vName {
+			continue
+		}
+		for _, ts := range metric.TimeSeries {
+			if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, ts.LabelValues) {
+				require.Equal(t, value, ts.Points[len(ts.Points)-1].Value.(int64))
 				return true
 			}
 		}
@@ -524,6 +698,25 @@ func checkValueForProducer(t *testing.T, producer metricproducer.Producer, wantT
 	return false
 }
 
+// valueForGlobalManager returns the value of the given metrics with wantTags reported by one of the metric producers
+// of the global manager, or zero if it is not reported yet. The cumulative metrics are global, so the tests check the
+// values they add to the values read before.
+func valueForGlobalManager(wantTags []tag.Tag, vName string) int64 {
+	for _, producer := range metricproducer.GlobalManager().GetAll() {
+		for _, metric := range producer.Read() {
+			if metric.Descriptor.Name != vName {
+				continue
+			}
+			for _, ts := range metric.TimeSeries {
+				if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, ts.LabelValues) {
+					return ts.Points[len(ts.Points)-1].Value.(int64)
+				}
+			}
+		}
+	}
+	return 0
+}
+
 // tagsMatchLabelKeys returns true if provided tags match keys and values
 func tagsMatchLabelKeys(tags []tag.Tag, keys []metricdata.LabelKey, labels []metricdata.LabelValue) bool {
 	if len(tags) != len(keys) {
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/internal/ratelimit"
+)
+
+// RateLimitSettings defines configuration for limiting the rate of data sent to the backend, e.g. to stay under
+// an ingest quota. Limits are enforced with token buckets, and apply to every attempt to send data, including retries.
+type RateLimitSettings struct {
+	// Enabled indicates whether to limit the rate of data sent to the backend.
+	Enabled bool `mapstructure:"enabled"`
+	// ItemsPerSecond is the maximum rate of spans, metric points or log records sent. Zero means no limit.
+	ItemsPerSecond float64 `mapstructure:"items_per_second"`
+	// ItemsBurst is the maximum number of items sent at once above ItemsPerSecond.
+	// Zero means as many items as allowed in one second.
+	ItemsBurst int `mapstructure:"items_burst"`
+	// RequestsPerSecond is the maximum rate of requests sent. Zero means no limit.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// RequestsBurst is the maximum number of requests sent at once above RequestsPerSecond.
+	// Zero means as many requests as allowed in one second.
+	RequestsBurst int `mapstructure:"requests_burst"`
+}
+
+// Validate checks if the RateLimitSettings configuration is valid
+func (rlCfg *RateLimitSettings) Validate() error {
+	if !rlCfg.Enabled {
+		return nil
+	}
+
+	if rlCfg.ItemsPerSecond < 0 || rlCfg.RequestsPerSecond < 0 {
+		return errors.New("rate limits must not be negative")
+	}
+
+	if rlCfg.ItemsPerSecond == 0 && rlCfg.RequestsPerSecond == 0 {
+		return errors.New("at least one of items per second or requests per second must be set")
+	}
+
+	if rlCfg.ItemsBurst < 0 || rlCfg.RequestsBurst < 0 {
+		return errors.New("bursts must not be negative")
+	}
+
+	return nil
+}
+
+var errRateLimiterStopped = errors.New("rate limiter stopped before the request was allowed")
+
+// rateLimiter is a requestSender delaying every attempt to send a request until it is allowed by the configured limits.
+// The requests still waiting when the exporter stops sending, after the sending queue is drained, fail.
+type rateLimiter struct {
+	items      *ratelimit.TokenBucket
+	requests   *ratelimit.TokenBucket
+	stopCh     chan struct{}
+	nextSender requestSender
+}
+
+func newRateLimiter(cfg RateLimitSettings, stopCh chan struct{}, nextSender requestSender) *rateLimiter {
+	rl := &rateLimiter{
+		stopCh:     stopCh,
+		nextSender: nextSender,
+	}
+	if cfg.ItemsPerSecond > 0 {
+		rl.items = ratelimit.NewTokenBucket(cfg.ItemsPerSecond, cfg.ItemsBurst)
+	}
+	if cfg.RequestsPerSecond > 0 {
+		rl.requests = ratelimit.NewTokenBucket(cfg.RequestsPerSecond, cfg.RequestsBurst)
+	}
+	return rl
+}
+
+// send implements the requestSender interface
+func (rl *rateLimiter) send(req request) error {
+	if err := rl.wait(req, rl.requests, 1); err != nil {
+		return err
+	}
+	if err := rl.wait(req, rl.items, req.count()); err != nil {
+		// The request token is given back, as the request is not sent.
+		if rl.requests != nil {
+			rl.requests.Cancel(1)
+		}
+		return err
+	}
+	return rl.nextSender.send(req)
+}
+
+// tryTake takes the tokens needed to send the request if they are available right now, and reports whether they
+// were taken. The request token is given back if the item tokens are not available.
+func (rl *rateLimiter) tryTake(req request) bool {
+	if rl.requests != nil && !rl.requests.Allow(1) {
+		return false
+	}
+	if rl.items != nil && !rl.items.Allow(req.count()) {
+		if rl.requests != nil {
+			rl.requests.Cancel(1)
+		}
+		return false
+	}
+	return true
+}
+
+// wait waits until n tokens of the bucket are available. The tokens are given back if the request context is done
+// or the rate limiter is stopped.
+func (rl *rateLimiter) wait(req request, tb *ratelimit.TokenBucket, n int) error {
+	if tb == nil {
+		return nil
+	}
+	delay := tb.Reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.context().Done():
+		tb.Cancel(n)
+		return req.context().Err()
+	case <-rl.stopCh:
+		tb.Cancel(n)
+		return errRateLimiterStopped
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestRateLimitSettings_Validate(t *testing.T) {
+	rlCfg := RateLimitSettings{}
+	assert.NoError(t, rlCfg.Validate())
+
+	rlCfg.Enabled = true
+	assert.EqualError(t, rlCfg.Validate(), "at least one of items per second or requests per second must be set")
+
+	rlCfg.ItemsPerSecond = 100
+	assert.NoError(t, rlCfg.Validate())
+
+	rlCfg.RequestsPerSecond = -1
+	assert.EqualError(t, rlCfg.Validate(), "rate limits must not be negative")
+
+	rlCfg.RequestsPerSecond = 10
+	rlCfg.ItemsBurst = -1
+	assert.EqualError(t, rlCfg.Validate(), "bursts must not be negative")
+}
+
+func TestRateLimiter_Items(t *testing.T) {
+	rl := newRateLimiter(RateLimitSettings{Enabled: true, ItemsPerSecond: 100, ItemsBurst: 4}, make(chan struct{}), &timeoutSender{})
+
+	// The burst is sent without waiting.
+	start := time.Now()
+	mockR := newMockRequest(context.Background(), 4, nil)
+	require.NoError(t, rl.send(mockR))
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+
+	// The bucket is empty, the next request waits for 5 items to be refilled.
+	mockR = newMockRequest(context.Background(), 5, nil)
+	require.NoError(t, rl.send(mockR))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	mockR.checkNumRequests(t, 1)
+}
+
+func TestRateLimiter_Requests(t *testing.T) {
+	rl := newRateLimiter(RateLimitSettings{Enabled: true, RequestsPerSecond: 50, RequestsBurst: 1}, make(chan struct{}), &timeoutSender{})
+
+	start := time.Now()
+	mockR := newMockRequest(context.Background(), 1000, nil)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, rl.send(mockR))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	mockR.checkNumRequests(t, 3)
+}
+
+func TestRateLimiter_ContextCanceled(t *testing.T) {
+	rl := newRateLimiter(RateLimitSettings{Enabled: true, ItemsPerSecond: 1, ItemsBurst: 1}, make(chan struct{}), &timeoutSender{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	mockR := newMockRequest(ctx, 10, nil)
+	assert.ErrorIs(t, rl.send(mockR), context.DeadlineExceeded)
+	mockR.checkNumRequests(t, 0)
+
+	// The tokens of the canceled request were given back.
+	mockR = newMockRequest(context.Background(), 1, nil)
+	require.NoError(t, rl.send(mockR))
+	mockR.checkNumRequests(t, 1)
+}
+
+func TestRateLimiter_StopUnblocksWaiting(t *testing.T) {
+	stopCh := make(chan struct{})
+	rl := newRateLimiter(RateLimitSettings{Enabled: true, RequestsPerSecond: 0.001, RequestsBurst: 1}, stopCh, &timeoutSender{})
+	require.NoError(t, rl.send(newMockRequest(context.Background(), 1, nil)))
+
+	mockR := newMockRequest(context.Background(), 1, nil)
+	done := make(chan struct{})
+	go func() {
+		assert.ErrorIs(t, rl.send(mockR), errRateLimiterStopped)
+		close(done)
+	}()
+	close(stopCh)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "send was not unblocked by stop")
+	}
+	mockR.checkNumRequests(t, 0)
+}
+
+func TestRateLimiter_ItemsFailureReleasesRequestToken(t *testing.T) {
+	rl := newRateLimiter(RateLimitSettings{Enabled: true, ItemsPerSecond: 1, ItemsBurst: 1, RequestsPerSecond: 0.001, RequestsBurst: 1}, make(chan struct{}), &timeoutSender{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	mockR := newMockRequest(ctx, 10, nil)
+	assert.ErrorIs(t, rl.send(mockR), context.DeadlineExceeded)
+
+	// The request token was given back with the items, the next request is sent without waiting.
+	start := time.Now()
+	mockR = newMockRequest(context.Background(), 1, nil)
+	require.NoError(t, rl.send(mockR))
+	assert.Less(t, time.Since(start), time.Second)
+	mockR.checkNumRequests(t, 1)
+}
+
+func TestRateLimiter_TryTake(t *testing.T) {
+	rl := newRateLimiter(RateLimitSettings{Enabled: true, ItemsPerSecond: 0.001, ItemsBurst: 1, RequestsPerSecond: 0.001, RequestsBurst: 2}, make(chan struct{}), &timeoutSender{})
+	req := newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), nil)
+	assert.True(t, rl.tryTake(req))
+	// The request token is given back when the item tokens are not available.
+	assert.False(t, rl.tryTake(req))
+	assert.True(t, rl.requests.Allow(1))
+}
+
+func TestQueuedRetry_RateLimit(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 4
+	rCfg := NewDefaultRetrySettings()
+	rlCfg := RateLimitSettings{Enabled: true, ItemsPerSecond: 200, ItemsBurst: 10}
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg), WithRateLimit(rlCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		ocs.run(func() {
+			require.NoError(t, be.sender.send(newMockRequest(context.Background(), 5, nil)))
+		})
+	}
+	ocs.awaitAsyncProcessing()
+	ocs.checkSendItemsCount(t, 30)
+	// 10 items are sent in the initial burst, the other 20 at 200 items per second, regardless of the consumers.
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestQueuedRetry_RateLimitWhileDraining(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var sent []time.Time
+	pusher := func(context.Context, pdata.Logs) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, time.Now())
+		return nil
+	}
+
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.DrainTimeout = 5 * time.Second
+	rlCfg := RateLimitSettings{Enabled: true, RequestsPerSecond: 10, RequestsBurst: 1}
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "drain_rate_limit"))
+	be := newBaseExporter(&cfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg), WithRateLimit(rlCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(1), pusher)))
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+		close(shutdownDone)
+	}()
+	assert.Eventually(t, func() bool {
+		return be.qrSender.drainer.draining.Load()
+	}, time.Second, time.Millisecond)
+	close(release)
+	<-shutdownDone
+
+	// The token used by the first request is refilled while it is blocked, the last two wait for the rate limit.
+	require.Len(t, sent, 4)
+	assert.GreaterOrEqual(t, sent[3].Sub(sent[1]), 150*time.Millisecond)
+}
@@ -0,0 +1,281 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryErrorClass is a class of retryable errors, which can be retried with its own policy.
+type RetryErrorClass string
+
+const (
+	// RetryClassConnectionRefused is the class of the errors caused by the backend refusing the connection.
+	RetryClassConnectionRefused RetryErrorClass = "connection_refused"
+	// RetryClassUnavailable is the class of the errors reporting the backend as unavailable,
+	// like the HTTP status code 503 or the gRPC code Unavailable.
+	RetryClassUnavailable RetryErrorClass = "unavailable"
+	// RetryClassThrottled is the class of the errors reporting the client as throttled,
+	// like the HTTP status code 429, the gRPC code ResourceExhausted or the errors created with NewThrottleRetry.
+	RetryClassThrottled RetryErrorClass = "throttled"
+	// RetryClassDeadlineExceeded is the class of the errors caused by a request timing out.
+	RetryClassDeadlineExceeded RetryErrorClass = "deadline_exceeded"
+)
+
+var retryErrorClasses = []RetryErrorClass{
+	RetryClassConnectionRefused,
+	RetryClassUnavailable,
+	RetryClassThrottled,
+	RetryClassDeadlineExceeded,
+}
+
+// RetryPolicySettings defines the backoff used for the retryable errors of a class,
+// the fields which are not set take the value defined in RetrySettings.
+type RetryPolicySettings struct {
+	// InitialInterval the time to wait after the first failure before retrying.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	// MaxInterval is the upper bound on backoff interval.
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+	// MaxElapsedTime is the maximum amount of time (including retries) spent trying to send a request/batch
+	// failing with errors of the class.
+	MaxElapsedTime time.Duration `mapstructure:"max_elapsed_time"`
+}
+
+// RetryBudgetSettings defines configuration for limiting the retries to a ratio of the requests sent, so a failing
+// backend does not multiply the traffic sent to it. When the budget is exhausted, the failed requests are not retried.
+type RetryBudgetSettings struct {
+	// Enabled indicates whether to limit the number of retries.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxRetryRatio is the maximum number of retries relative to the number of requests sent during Window.
+	MaxRetryRatio float64 `mapstructure:"max_retry_ratio"`
+	// MinRetries is the number of retries always allowed during Window, so requests are retried at low traffic.
+	MinRetries int `mapstructure:"min_retries"`
+	// Window is the period of time over which retries and requests sent are counted.
+	Window time.Duration `mapstructure:"window"`
+}
+
+// NewDefaultRetryBudgetSettings returns the default settings for RetryBudgetSettings.
+func NewDefaultRetryBudgetSettings() RetryBudgetSettings {
+	return RetryBudgetSettings{
+		Enabled:       false,
+		MaxRetryRatio: 0.2,
+		MinRetries:    10,
+		Window:        10 * time.Second,
+	}
+}
+
+// Validate checks if the RetryBudgetSettings configuration is valid
+func (rbCfg *RetryBudgetSettings) Validate() error {
+	if !rbCfg.Enabled {
+		return nil
+	}
+
+	if rbCfg.MaxRetryRatio <= 0 {
+		return errors.New("max retry ratio must be positive")
+	}
+
+	if rbCfg.MinRetries < 0 {
+		return errors.New("min retries must not be negative")
+	}
+
+	if rbCfg.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+
+	return nil
+}
+
+func validateRetryPolicies(policies map[RetryErrorClass]RetryPolicySettings) error {
+	for class, policy := range policies {
+		if !isRetryErrorClass(class) {
+			return fmt.Errorf("unknown retry error class %q", class)
+		}
+		if policy.InitialInterval < 0 || policy.MaxInterval < 0 || policy.MaxElapsedTime < 0 {
+			return fmt.Errorf("retry policy of %q must not have negative intervals", class)
+		}
+	}
+	return nil
+}
+
+func isRetryErrorClass(class RetryErrorClass) bool {
+	for _, c := range retryErrorClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// policy returns the backoff settings for the errors of the class, the default ones for an empty class.
+func (rCfg *RetrySettings) policy(class RetryErrorClass) RetryPolicySettings {
+	policy := RetryPolicySettings{
+		InitialInterval: rCfg.InitialInterval,
+		MaxInterval:     rCfg.MaxInterval,
+		MaxElapsedTime:  rCfg.MaxElapsedTime,
+	}
+	override, ok := rCfg.Policies[class]
+	if !ok {
+		return policy
+	}
+	if override.InitialInterval > 0 {
+		policy.InitialInterval = override.InitialInterval
+	}
+	if override.MaxInterval > 0 {
+		policy.MaxInterval = override.MaxInterval
+	}
+	if override.MaxElapsedTime > 0 {
+		policy.MaxElapsedTime = override.MaxElapsedTime
+	}
+	return policy
+}
+
+// classifiedError is a retryable error explicitly classified by the exporter.
+type classifiedError struct {
+	err   error
+	class RetryErrorClass
+}
+
+func (c classifiedError) Error() string {
+	return c.err.Error()
+}
+
+func (c classifiedError) Unwrap() error {
+	return c.err
+}
+
+// NewClassifiedRetry creates a new retryable error of the given class, retried with the policy of the class.
+// Errors which are not classified by the exporter are classified from their type or their gRPC status.
+func NewClassifiedRetry(err error, class RetryErrorClass) error {
+	return classifiedError{
+		err:   err,
+		class: class,
+	}
+}
+
+// classifyRetryError returns the class of a retryable error, or an empty class if it does not belong to any class.
+func classifyRetryError(err error) RetryErrorClass {
+	var classified classifiedError
+	if errors.As(err, &classified) {
+		return classified.class
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return RetryClassConnectionRefused
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return RetryClassDeadlineExceeded
+	}
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Unavailable:
+			return RetryClassUnavailable
+		case codes.ResourceExhausted:
+			return RetryClassThrottled
+		case codes.DeadlineExceeded:
+			return RetryClassDeadlineExceeded
+		}
+	}
+
+	if errors.As(err, &throttleRetry{}) {
+		return RetryClassThrottled
+	}
+	return ""
+}
+
+// retryBudget counts the requests sent and the retries over a sliding window, split in a fixed number of buckets,
+// allowing a retry only while the retries stay under the ratio of the requests sent.
+type retryBudget struct {
+	cfg         RetryBudgetSettings
+	bucketWidth time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	buckets []retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	// epoch is the number of bucket widths since the Unix epoch at the start of the bucket.
+	epoch   int64
+	sends   int64
+	retries int64
+}
+
+const retryBudgetBuckets = 10
+
+// newRetryBudget returns the budget for the settings, or nil if the budget is disabled.
+func newRetryBudget(cfg RetryBudgetSettings) *retryBudget {
+	if !cfg.Enabled {
+		return nil
+	}
+	bucketWidth := cfg.Window / retryBudgetBuckets
+	if bucketWidth <= 0 {
+		bucketWidth = 1
+	}
+	return &retryBudget{
+		cfg:         cfg,
+		bucketWidth: bucketWidth,
+		now:         time.Now,
+		buckets:     make([]retryBudgetBucket, retryBudgetBuckets),
+	}
+}
+
+// current returns the bucket of the current time, reset if it was last used in a previous window.
+// It must be called while holding the lock.
+func (rb *retryBudget) current() (*retryBudgetBucket, int64) {
+	epoch := rb.now().UnixNano() / int64(rb.bucketWidth)
+	bucket := &rb.buckets[epoch%retryBudgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = retryBudgetBucket{epoch: epoch}
+	}
+	return bucket, epoch
+}
+
+// onSend records a request sent for the first time.
+func (rb *retryBudget) onSend() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	bucket, _ := rb.current()
+	bucket.sends++
+}
+
+// tryRetry records a retry and returns true if it is allowed by the budget.
+func (rb *retryBudget) tryRetry() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	bucket, epoch := rb.current()
+
+	var sends, retries int64
+	for _, b := range rb.buckets {
+		if epoch-b.epoch < retryBudgetBuckets {
+			sends += b.sends
+			retries += b.retries
+		}
+	}
+	if float64(retries+1) > float64(rb.cfg.MinRetries)+rb.cfg.MaxRetryRatio*float64(sends) {
+		return false
+	}
+	bucket.retries++
+	return true
+}
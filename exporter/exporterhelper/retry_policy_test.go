@@ -0,0 +1,249 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	uatomic "go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestRetrySettings_Validate(t *testing.T) {
+	rCfg := NewDefaultRetrySettings()
+	assert.NoError(t, rCfg.Validate())
+
+	rCfg.Policies = map[RetryErrorClass]RetryPolicySettings{RetryClassThrottled: {InitialInterval: time.Second}}
+	assert.NoError(t, rCfg.Validate())
+
+	rCfg.Policies = map[RetryErrorClass]RetryPolicySettings{"unknown": {}}
+	assert.EqualError(t, rCfg.Validate(), `unknown retry error class "unknown"`)
+
+	rCfg.Policies = map[RetryErrorClass]RetryPolicySettings{RetryClassUnavailable: {MaxInterval: -time.Second}}
+	assert.EqualError(t, rCfg.Validate(), `retry policy of "unavailable" must not have negative intervals`)
+
+	rCfg = NewDefaultRetrySettings()
+	rCfg.MaxThrottleDelay = -time.Second
+	assert.EqualError(t, rCfg.Validate(), "max throttle delay must not be negative")
+
+	rCfg = NewDefaultRetrySettings()
+	rCfg.Budget.Enabled = true
+	assert.NoError(t, rCfg.Validate())
+
+	rCfg.Budget.MaxRetryRatio = 0
+	assert.EqualError(t, rCfg.Validate(), "retry budget has invalid configuration: max retry ratio must be positive")
+
+	rCfg.Budget = NewDefaultRetryBudgetSettings()
+	rCfg.Budget.Enabled = true
+	rCfg.Budget.MinRetries = -1
+	assert.EqualError(t, rCfg.Validate(), "retry budget has invalid configuration: min retries must not be negative")
+
+	rCfg.Budget = NewDefaultRetryBudgetSettings()
+	rCfg.Budget.Enabled = true
+	rCfg.Budget.Window = 0
+	assert.EqualError(t, rCfg.Validate(), "retry budget has invalid configuration: window must be positive")
+
+	// Confirm Validate doesn't return error with invalid config when retries are disabled
+	rCfg.Enabled = false
+	assert.NoError(t, rCfg.Validate())
+
+	rCfg.DeadLetter.Enabled = true
+	assert.Equal(t, errDeadLetterNoDestination, rCfg.Validate())
+}
+
+func TestRetrySettings_Policy(t *testing.T) {
+	rCfg := NewDefaultRetrySettings()
+	rCfg.Policies = map[RetryErrorClass]RetryPolicySettings{
+		RetryClassThrottled: {InitialInterval: time.Minute, MaxElapsedTime: time.Hour},
+	}
+
+	assert.Equal(t, RetryPolicySettings{InitialInterval: 5 * time.Second, MaxInterval: 30 * time.Second, MaxElapsedTime: 5 * time.Minute}, rCfg.policy(""))
+	assert.Equal(t, RetryPolicySettings{InitialInterval: 5 * time.Second, MaxInterval: 30 * time.Second, MaxElapsedTime: 5 * time.Minute}, rCfg.policy(RetryClassUnavailable))
+	assert.Equal(t, RetryPolicySettings{InitialInterval: time.Minute, MaxInterval: 30 * time.Second, MaxElapsedTime: time.Hour}, rCfg.policy(RetryClassThrottled))
+}
+
+func TestClassifyRetryError(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		class RetryErrorClass
+	}{
+		{
+			name:  "classified",
+			err:   NewThrottleRetry(NewClassifiedRetry(errors.New("503"), RetryClassUnavailable), time.Second),
+			class: RetryClassUnavailable,
+		},
+		{
+			name:  "connection_refused",
+			err:   fmt.Errorf("failed to make an HTTP request: %w", &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}),
+			class: RetryClassConnectionRefused,
+		},
+		{
+			name:  "context_deadline",
+			err:   fmt.Errorf("failed to make an HTTP request: %w", context.DeadlineExceeded),
+			class: RetryClassDeadlineExceeded,
+		},
+		{
+			name:  "grpc_unavailable",
+			err:   status.Error(codes.Unavailable, "unavailable"),
+			class: RetryClassUnavailable,
+		},
+		{
+			name:  "grpc_resource_exhausted",
+			err:   status.Error(codes.ResourceExhausted, "quota exceeded"),
+			class: RetryClassThrottled,
+		},
+		{
+			name:  "grpc_deadline",
+			err:   NewThrottleRetry(status.Error(codes.DeadlineExceeded, "deadline"), time.Second),
+			class: RetryClassDeadlineExceeded,
+		},
+		{
+			name:  "throttle",
+			err:   NewThrottleRetry(errors.New("throttled"), time.Second),
+			class: RetryClassThrottled,
+		},
+		{
+			name: "other",
+			err:  errors.New("transient error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.class, classifyRetryError(tt.err))
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rb := newRetryBudget(RetryBudgetSettings{Enabled: true, MaxRetryRatio: 0.5, MinRetries: 1, Window: 10 * time.Second})
+	rb.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		rb.onSend()
+	}
+	// 1 retry is always allowed, plus 1 retry every 2 requests sent.
+	for i := 0; i < 3; i++ {
+		assert.True(t, rb.tryRetry())
+	}
+	assert.False(t, rb.tryRetry())
+
+	// Requests sent later in the window make room for more retries.
+	now = now.Add(5 * time.Second)
+	rb.onSend()
+	rb.onSend()
+	assert.True(t, rb.tryRetry())
+	assert.False(t, rb.tryRetry())
+
+	// The first requests and retries are out of the window, 1 retry of the last ones is still in it.
+	now = now.Add(5 * time.Second)
+	assert.True(t, rb.tryRetry())
+	assert.False(t, rb.tryRetry())
+
+	assert.Nil(t, newRetryBudget(NewDefaultRetryBudgetSettings()))
+}
+
+func newRetryTestExporter(t *testing.T, rCfg RetrySettings) *baseExporter {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.Enabled = false
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+	return be
+}
+
+func TestRetrySender_PolicyPerClass(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		if attempts.Inc() <= 3 {
+			return NewClassifiedRetry(errors.New("unavailable"), RetryClassUnavailable)
+		}
+		return nil
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Hour
+	rCfg.MaxInterval = time.Hour
+	rCfg.Policies = map[RetryErrorClass]RetryPolicySettings{
+		RetryClassUnavailable: {InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+	}
+	be := newRetryTestExporter(t, rCfg)
+
+	// The unavailable errors are retried after 1ms instead of 1h.
+	assert.NoError(t, be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher)))
+	assert.EqualValues(t, 4, attempts.Load())
+}
+
+func TestRetrySender_PolicyMaxElapsedTime(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		return status.Error(codes.ResourceExhausted, "quota exceeded")
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	rCfg.Policies = map[RetryErrorClass]RetryPolicySettings{
+		RetryClassThrottled: {MaxElapsedTime: 50 * time.Millisecond},
+	}
+	be := newRetryTestExporter(t, rCfg)
+
+	start := time.Now()
+	err := be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "max elapsed time expired")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Greater(t, attempts.Load(), int64(1))
+}
+
+func TestRetrySender_BudgetExhausted(t *testing.T) {
+	attempts := uatomic.NewInt64(0)
+	pusher := func(context.Context, pdata.Logs) error {
+		attempts.Inc()
+		return errors.New("transient error")
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	rCfg.Budget = RetryBudgetSettings{Enabled: true, MaxRetryRatio: 0.5, MinRetries: 1, Window: time.Minute}
+	be := newRetryTestExporter(t, rCfg)
+
+	// The first request gets the retry always allowed, the second one gets the retry allowed by both requests sent.
+	err := be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "retry budget exhausted")
+	assert.EqualValues(t, 2, attempts.Load())
+
+	err = be.sender.send(newLogsRequest(context.Background(), testdata.GenerateLogsOneLogRecord(), pusher))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "retry budget exhausted")
+	assert.EqualValues(t, 4, attempts.Load())
+}
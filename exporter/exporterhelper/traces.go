@@ -23,12 +23,14 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/internal/batchsplit"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 var tracesMarshaler = otlp.NewProtobufTracesMarshaler()
 var tracesUnmarshaler = otlp.NewProtobufTracesUnmarshaler()
+var tracesSizer = otlp.NewProtobufTracesMarshaler().(pdata.TracesSizer)
 
 type tracesRequest struct {
 	baseRequest
@@ -75,6 +77,19 @@ func (req *tracesRequest) count() int {
 	return req.td.SpanCount()
 }
 
+// byteSize provides the serialized size of the request required by the byte-bounded sending queue
+func (req *tracesRequest) byteSize() int {
+	return tracesSizer.TracesSize(req.td)
+}
+
+func (req *tracesRequest) merge(other request) {
+	other.(*tracesRequest).td.ResourceSpans().MoveAndAppendTo(req.td.ResourceSpans())
+}
+
+func (req *tracesRequest) split(size int) request {
+	return newTracesRequest(req.ctx, batchsplit.Traces(size, req.td), req.pusher)
+}
+
 type traceExporter struct {
 	*baseExporter
 	consumer.Traces
@@ -101,6 +116,9 @@ func NewTracesExporter(
 	}
 
 	bs := fromOptions(options...)
+	if err := bs.validate(); err != nil {
+		return nil, err
+	}
 	be := newBaseExporter(cfg, set, bs, config.TracesDataType, newTraceRequestUnmarshalerFunc(pusher))
 	be.wrapConsumerSender(func(nextSender requestSender) requestSender {
 		return &tracesExporterWithObservability{
@@ -110,12 +128,7 @@ func NewTracesExporter(
 	})
 
 	tc, err := consumer.NewTraces(func(ctx context.Context, td pdata.Traces) error {
-		req := newTracesRequest(ctx, td, pusher)
-		err := be.sender.send(req)
-		if errors.Is(err, errSendingQueueIsFull) {
-			be.obsrep.recordTracesEnqueueFailure(req.context(), int64(req.count()))
-		}
-		return err
+		return be.sender.send(newTracesRequest(ctx, td, pusher))
 	}, bs.consumerOptions...)
 
 	return &traceExporter{
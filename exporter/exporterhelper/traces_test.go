@@ -71,6 +71,12 @@ func TestTracesExporter_NilPushTraceData(t *testing.T) {
 	require.Equal(t, errNilPushTraceData, err)
 }
 
+func TestTracesExporter_InvalidOptions(t *testing.T) {
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil), WithHedging(HedgingSettings{Enabled: true}))
+	require.Nil(t, te)
+	require.EqualError(t, err, "hedging has invalid configuration: quantile must be in the range (0, 1]")
+}
+
 func TestTracesExporter_Default(t *testing.T) {
 	td := pdata.NewTraces()
 	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil))
@@ -133,6 +139,7 @@ func TestTracesExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	te, err := NewTracesExporter(&fakeTracesExporterConfig, tt.ToExporterCreateSettings(), newTraceDataPusher(wantErr), WithRetry(rCfg), WithQueue(qCfg))
 	require.NoError(t, err)
 	require.NotNil(t, te)
+	enqueueFailed := valueForGlobalManager(tagsForExporterView(fakeTracesExporterName), "exporter/enqueue_failed_spans")
 
 	td := testdata.GenerateTracesTwoSpansSameResource()
 	const numBatches = 7
@@ -142,7 +149,7 @@ func TestTracesExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	}
 
 	// 2 batched must be in queue, and 5 batches (10 spans) rejected due to queue overflow
-	checkExporterEnqueueFailedTracesStats(t, globalInstruments, fakeTracesExporterName, int64(10))
+	checkExporterEnqueueFailedTracesStats(t, globalInstruments, fakeTracesExporterName, enqueueFailed+10)
 }
 
 func TestTracesExporter_WithSpan(t *testing.T) {
@@ -15,6 +15,7 @@
 package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexporter"
 
 import (
+	"errors"
 	"fmt"
 
 	"go.opentelemetry.io/collector/config"
@@ -30,6 +31,9 @@ type Config struct {
 	exporterhelper.RetrySettings   `mapstructure:"retry_on_failure"`
 
 	configgrpc.GRPCClientSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	// LoadBalancing configures the endpoints the requests are balanced between, replacing the Endpoint.
+	LoadBalancing LoadBalancingSettings `mapstructure:"load_balancing"`
 }
 
 var _ config.Exporter = (*Config)(nil)
@@ -39,6 +43,18 @@ func (cfg *Config) Validate() error {
 	if err := cfg.QueueSettings.Validate(); err != nil {
 		return fmt.Errorf("queue settings has invalid configuration: %w", err)
 	}
+	if err := cfg.RetrySettings.Validate(); err != nil {
+		return fmt.Errorf("retry settings has invalid configuration: %w", err)
+	}
+	if err := cfg.TLSSetting.Validate(); err != nil {
+		return fmt.Errorf("tls settings has invalid configuration: %w", err)
+	}
+	if err := cfg.LoadBalancing.Validate(); err != nil {
+		return fmt.Errorf("load balancing settings has invalid configuration: %w", err)
+	}
+	if cfg.LoadBalancing.Enabled() && (cfg.Endpoint != "" || cfg.BalancerName != "") {
+		return errors.New("endpoint and balancer_name cannot be set with load_balancing")
+	}
 
 	return nil
 }
@@ -54,15 +54,20 @@ func TestLoadConfig(t *testing.T) {
 				Timeout: 10 * time.Second,
 			},
 			RetrySettings: exporterhelper.RetrySettings{
-				Enabled:         true,
-				InitialInterval: 10 * time.Second,
-				MaxInterval:     1 * time.Minute,
-				MaxElapsedTime:  10 * time.Minute,
+				Enabled:          true,
+				InitialInterval:  10 * time.Second,
+				MaxInterval:      1 * time.Minute,
+				MaxElapsedTime:   10 * time.Minute,
+				MaxThrottleDelay: 5 * time.Minute,
+				Budget:           exporterhelper.NewDefaultRetryBudgetSettings(),
 			},
 			QueueSettings: exporterhelper.QueueSettings{
-				Enabled:      true,
-				NumConsumers: 2,
-				QueueSize:    10,
+				Enabled:             true,
+				NumConsumers:        2,
+				QueueSize:           10,
+				OverflowPolicy:      exporterhelper.OverflowRejectNew,
+				BlockTimeout:        5 * time.Second,
+				AdaptiveConcurrency: exporterhelper.NewDefaultAdaptiveConcurrencySettings(),
 			},
 			GRPCClientSettings: configgrpc.GRPCClientSettings{
 				Headers: map[string]string{
@@ -87,5 +92,15 @@ func TestLoadConfig(t *testing.T) {
 				BalancerName:    "round_robin",
 				Auth:            &configauth.Authentication{AuthenticatorID: config.NewComponentID("nop")},
 			},
+			LoadBalancing: NewDefaultLoadBalancingSettings(),
 		})
+
+	e2 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "lb")].(*Config)
+	assert.Equal(t,
+		LoadBalancingSettings{
+			DNSSRV:          "_otlp._tcp.gateway.example.com",
+			RefreshInterval: 10 * time.Second,
+			HealthCheck:     true,
+		},
+		e2.LoadBalancing)
 }
@@ -16,6 +16,7 @@ package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexpor
 
 import (
 	"context"
+	"errors"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
@@ -53,6 +54,7 @@ func createDefaultConfig() config.Exporter {
 			// We almost read 0 bytes, so no need to tune ReadBufferSize.
 			WriteBufferSize: 512 * 1024,
 		},
+		LoadBalancing: NewDefaultLoadBalancingSettings(),
 	}
 }
 
@@ -83,6 +85,9 @@ func createMetricsExporter(
 	set component.ExporterCreateSettings,
 	cfg config.Exporter,
 ) (component.MetricsExporter, error) {
+	if cfg.(*Config).LoadBalancing.RoutingKey == RoutingKeyTraceID {
+		return nil, errors.New("metrics cannot be routed by trace_id")
+	}
 	oce, err := newExporter(cfg, set.TelemetrySettings, set.BuildInfo)
 	if err != nil {
 		return nil, err
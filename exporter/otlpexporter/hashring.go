@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexporter"
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// hashRingVirtualNodes is the number of points every endpoint has on the ring, spreading the keys evenly.
+const hashRingVirtualNodes = 100
+
+// hashRing is a consistent hash ring: when an endpoint is added or removed, only the keys of this endpoint move.
+type hashRing struct {
+	points []hashRingPoint
+}
+
+type hashRingPoint struct {
+	hash     uint32
+	endpoint string
+}
+
+func newHashRing(endpoints []string) *hashRing {
+	points := make([]hashRingPoint, 0, len(endpoints)*hashRingVirtualNodes)
+	for _, endpoint := range endpoints {
+		for i := 0; i < hashRingVirtualNodes; i++ {
+			points = append(points, hashRingPoint{
+				hash:     hashKey([]byte(endpoint + "-" + strconv.Itoa(i))),
+				endpoint: endpoint,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash == points[j].hash {
+			return points[i].endpoint < points[j].endpoint
+		}
+		return points[i].hash < points[j].hash
+	})
+	return &hashRing{points: points}
+}
+
+// endpoint returns the endpoint owning the key: the one of the first point after the hash of the key on the ring.
+func (r *hashRing) endpoint(key []byte) string {
+	hash := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].endpoint
+}
+
+// hashKey returns the position of the key on the ring. The FNV hash is mixed with the finalizer of MurmurHash3 so the
+// similar keys, like the names of the points of an endpoint, are spread over the ring.
+func hashKey(key []byte) uint32 {
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	k := h.Sum64()
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return uint32(k >> 32)
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRing(t *testing.T) {
+	endpoints := []string{"gw1:4317", "gw2:4317", "gw3:4317"}
+	ring := newHashRing(endpoints)
+
+	keys := make([][]byte, 10000)
+	owners := make([]string, len(keys))
+	counts := map[string]int{}
+	for i := range keys {
+		keys[i] = make([]byte, 16)
+		binary.BigEndian.PutUint64(keys[i], uint64(i)*0x9E3779B97F4A7C15)
+		owners[i] = ring.endpoint(keys[i])
+		counts[owners[i]]++
+	}
+
+	// The keys are spread over all the endpoints.
+	for _, endpoint := range endpoints {
+		assert.Greater(t, counts[endpoint], len(keys)/6, endpoint)
+	}
+
+	// The ring does not depend on the order of the endpoints.
+	reordered := newHashRing([]string{"gw3:4317", "gw1:4317", "gw2:4317"})
+	for i, key := range keys {
+		assert.Equal(t, owners[i], reordered.endpoint(key))
+	}
+
+	// Only the keys of the removed endpoint move.
+	shrunk := newHashRing([]string{"gw1:4317", "gw3:4317"})
+	for i, key := range keys {
+		if owners[i] != "gw2:4317" {
+			assert.Equal(t, owners[i], shrunk.endpoint(key))
+		}
+	}
+
+	// Only the keys now owned by the added endpoint move.
+	grown := newHashRing(append(endpoints, "gw4:4317"))
+	for i, key := range keys {
+		if endpoint := grown.endpoint(key); endpoint != "gw4:4317" {
+			assert.Equal(t, owners[i], endpoint)
+		}
+	}
+}
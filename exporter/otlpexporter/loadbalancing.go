@@ -0,0 +1,396 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexporter"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // Registers the client side health checking used by the service config.
+	"google.golang.org/grpc/resolver"
+)
+
+// loadBalancingScheme is the scheme of the target dialed when the requests are balanced between several endpoints.
+const loadBalancingScheme = "otlp-lb"
+
+// minResolveNowInterval is the minimum interval between the resolutions requested by gRPC, e.g. on connection
+// failures, so they do not flood the DNS server or the file system.
+const minResolveNowInterval = 5 * time.Second
+
+// LoadBalancingSettings defines the endpoints the requests are balanced between, instead of the single endpoint.
+// Exactly one of Endpoints, DNSSRV and File must be set to enable the load balancing.
+type LoadBalancingSettings struct {
+	// Endpoints is a static list of host:port endpoints.
+	Endpoints []string `mapstructure:"endpoints"`
+	// DNSSRV is the name of a DNS SRV record resolved to the endpoints, like "_otlp._tcp.gateway.example.com".
+	DNSSRV string `mapstructure:"dns_srv"`
+	// File is the path of a file listing one host:port endpoint per line. Empty lines and lines starting with # are ignored.
+	File string `mapstructure:"file"`
+	// RefreshInterval is the interval at which the DNS SRV record is resolved again or the file is read again.
+	// The file is also read again as soon as it changes.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	// HealthCheck enables the gRPC health checking of the endpoints, the ones not serving receive no requests.
+	// See https://github.com/grpc/grpc/blob/master/doc/health-checking.md.
+	HealthCheck bool `mapstructure:"health_check"`
+	// RoutingKey is the key the data is routed by to the endpoints, the requests are balanced in a round robin fashion
+	// if it is not set.
+	RoutingKey RoutingKey `mapstructure:"routing_key"`
+	// RoutingAttribute is the resource attribute the data is routed by when RoutingKey is "resource_attribute".
+	RoutingAttribute string `mapstructure:"routing_attribute"`
+}
+
+// NewDefaultLoadBalancingSettings returns the default settings for LoadBalancingSettings.
+func NewDefaultLoadBalancingSettings() LoadBalancingSettings {
+	return LoadBalancingSettings{
+		RefreshInterval: 30 * time.Second,
+	}
+}
+
+// Enabled returns true if the endpoints the requests are balanced between are configured.
+func (lbs *LoadBalancingSettings) Enabled() bool {
+	return len(lbs.Endpoints) > 0 || lbs.DNSSRV != "" || lbs.File != ""
+}
+
+// Validate checks if the LoadBalancingSettings configuration is valid
+func (lbs *LoadBalancingSettings) Validate() error {
+	if !lbs.Enabled() {
+		if lbs.RoutingKey != RoutingKeyNone {
+			return errors.New("routing key cannot be set without endpoints")
+		}
+		return nil
+	}
+
+	sources := 0
+	for _, set := range []bool{len(lbs.Endpoints) > 0, lbs.DNSSRV != "", lbs.File != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("only one of endpoints, dns_srv and file can be set")
+	}
+
+	for _, endpoint := range lbs.Endpoints {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+	}
+
+	if lbs.DNSSRV != "" || lbs.File != "" {
+		if lbs.RefreshInterval <= 0 {
+			return errors.New("refresh interval must be positive")
+		}
+	}
+
+	switch lbs.RoutingKey {
+	case RoutingKeyNone, RoutingKeyTraceID:
+		if lbs.RoutingAttribute != "" {
+			return fmt.Errorf("routing attribute can only be set with the %q routing key", RoutingKeyResourceAttribute)
+		}
+	case RoutingKeyResourceAttribute:
+		if lbs.RoutingAttribute == "" {
+			return fmt.Errorf("routing attribute must be set with the %q routing key", RoutingKeyResourceAttribute)
+		}
+	default:
+		return fmt.Errorf("unknown routing key %q", lbs.RoutingKey)
+	}
+
+	if lbs.RoutingKey != RoutingKeyNone && lbs.HealthCheck {
+		// The data of a key is always sent to the same endpoint, it cannot fail over to the healthy ones.
+		return errors.New("health check cannot be enabled with a routing key")
+	}
+
+	return nil
+}
+
+// toDialTarget returns the target to dial and the dial options balancing the requests between the endpoints.
+func (lbs *LoadBalancingSettings) toDialTarget(logger *zap.Logger) (string, []grpc.DialOption) {
+	builder := newEndpointsResolverBuilder(*lbs, logger)
+
+	serviceConfig := `{"loadBalancingPolicy":"round_robin"}`
+	if lbs.HealthCheck {
+		serviceConfig = `{"loadBalancingPolicy":"round_robin","healthCheckConfig":{"serviceName":""}}`
+	}
+
+	// The round robin policy only sends requests to the endpoints it is connected to, and with the health checking
+	// to the ones serving, so the requests fail over to the other endpoints when one is down.
+	return loadBalancingScheme + ":///" + builder.name(), []grpc.DialOption{
+		grpc.WithResolvers(builder),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	}
+}
+
+// endpointsResolverBuilder builds the resolvers providing the endpoints of the LoadBalancingSettings to gRPC.
+type endpointsResolverBuilder struct {
+	cfg                   LoadBalancingSettings
+	logger                *zap.Logger
+	lookupSRV             func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	minResolveNowInterval time.Duration
+}
+
+var _ resolver.Builder = (*endpointsResolverBuilder)(nil)
+
+func newEndpointsResolverBuilder(cfg LoadBalancingSettings, logger *zap.Logger) *endpointsResolverBuilder {
+	return &endpointsResolverBuilder{
+		cfg:                   cfg,
+		logger:                logger,
+		lookupSRV:             net.DefaultResolver.LookupSRV,
+		minResolveNowInterval: minResolveNowInterval,
+	}
+}
+
+// name returns the name of the source of the endpoints, used as the authority of the target.
+func (b *endpointsResolverBuilder) name() string {
+	switch {
+	case b.cfg.DNSSRV != "":
+		return b.cfg.DNSSRV
+	case b.cfg.File != "":
+		return "file"
+	default:
+		return "endpoints"
+	}
+}
+
+// Build implements the resolver.Builder interface
+func (b *endpointsResolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	update := func(endpoints []string) error {
+		addresses := make([]resolver.Address, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			host, _, _ := net.SplitHostPort(endpoint)
+			// The host of every endpoint is the name its TLS certificate is verified against.
+			addresses = append(addresses, resolver.Address{Addr: endpoint, ServerName: host})
+		}
+		return cc.UpdateState(resolver.State{Addresses: addresses})
+	}
+	return b.start(update, cc.ReportError), nil
+}
+
+// start returns a resolver calling update with the endpoints every time they change, and reportError every time they
+// cannot be resolved.
+func (b *endpointsResolverBuilder) start(update func(endpoints []string) error, reportError func(err error)) *endpointsResolver {
+	r := &endpointsResolver{
+		builder:     b,
+		update:      update,
+		reportError: reportError,
+		resolveNow:  make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	r.resolve()
+	// Static endpoints never change, the others are refreshed until the resolver is closed.
+	if b.cfg.DNSSRV != "" || b.cfg.File != "" {
+		r.wg.Add(1)
+		go r.refresh()
+	}
+	return r
+}
+
+// Scheme implements the resolver.Builder interface
+func (b *endpointsResolverBuilder) Scheme() string {
+	return loadBalancingScheme
+}
+
+// endpointsResolver refreshes the endpoints every refresh interval, when the file changes and when gRPC requests it.
+type endpointsResolver struct {
+	builder     *endpointsResolverBuilder
+	update      func(endpoints []string) error
+	reportError func(err error)
+	resolveNow  chan struct{}
+	done        chan struct{}
+	wg          sync.WaitGroup
+
+	// last is the list of the endpoints last updated.
+	last []string
+	// lastResolve is the time of the last resolution.
+	lastResolve time.Time
+}
+
+var _ resolver.Resolver = (*endpointsResolver)(nil)
+
+// ResolveNow implements the resolver.Resolver interface. The endpoints are resolved at most once per
+// minResolveNowInterval, the connection failures triggering this call must not flood the DNS server or the file system.
+func (r *endpointsResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+		// A resolution is already requested.
+	}
+}
+
+// Close implements the resolver.Resolver interface
+func (r *endpointsResolver) Close() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func (r *endpointsResolver) refresh() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.builder.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	var fileEvents <-chan fsnotify.Event
+	var fileErrors <-chan error
+	if r.builder.cfg.File != "" {
+		watcher, err := watchEndpointsFile(r.builder.cfg.File)
+		if err != nil {
+			r.builder.logger.Warn("Failed to watch the endpoints file, it is only read every refresh interval", zap.String("file", r.builder.cfg.File), zap.Error(err))
+		} else {
+			defer watcher.Close()
+			fileEvents, fileErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	// delayed fires when a resolution requested too early by gRPC is due.
+	var delayed <-chan time.Time
+	for {
+		select {
+		case <-ticker.C:
+			r.resolve()
+		case event := <-fileEvents:
+			// The file is often replaced instead of written, e.g. a Kubernetes ConfigMap, so every change of its
+			// directory is considered, the endpoints are only updated if they changed.
+			r.builder.logger.Debug("Endpoints file changed", zap.String("file", r.builder.cfg.File), zap.Stringer("event", event))
+			r.resolve()
+		case err := <-fileErrors:
+			r.builder.logger.Warn("Failed to watch the endpoints file", zap.String("file", r.builder.cfg.File), zap.Error(err))
+		case <-r.resolveNow:
+			if delayed != nil {
+				continue
+			}
+			wait := r.builder.minResolveNowInterval - time.Since(r.lastResolve)
+			if wait <= 0 {
+				r.resolve()
+				continue
+			}
+			delayed = time.After(wait)
+		case <-delayed:
+			delayed = nil
+			r.resolve()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// watchEndpointsFile returns a watcher of the directory of the file, which also reports the file being replaced.
+func watchEndpointsFile(path string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// resolve updates the endpoints if they changed. On failure, the last endpoints keep being used.
+func (r *endpointsResolver) resolve() {
+	r.lastResolve = time.Now()
+	endpoints, err := r.endpoints()
+	if err == nil && len(endpoints) == 0 {
+		err = errors.New("no endpoints found")
+	}
+	if err != nil {
+		r.builder.logger.Warn("Failed to resolve the endpoints", zap.String("source", r.builder.name()), zap.Error(err))
+		r.reportError(err)
+		return
+	}
+	if equalEndpoints(r.last, endpoints) {
+		return
+	}
+	r.last = endpoints
+
+	r.builder.logger.Debug("Endpoints updated", zap.String("source", r.builder.name()), zap.Strings("endpoints", endpoints))
+	if err = r.update(endpoints); err != nil {
+		r.builder.logger.Warn("Failed to update the endpoints", zap.Error(err))
+	}
+}
+
+func (r *endpointsResolver) endpoints() ([]string, error) {
+	cfg := r.builder.cfg
+	switch {
+	case cfg.DNSSRV != "":
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.RefreshInterval)
+		defer cancel()
+		return lookupSRVEndpoints(ctx, r.builder.lookupSRV, cfg.DNSSRV)
+	case cfg.File != "":
+		return readEndpointsFile(cfg.File)
+	default:
+		return cfg.Endpoints, nil
+	}
+}
+
+// lookupSRVEndpoints returns the host:port endpoints the DNS SRV record points to.
+func lookupSRVEndpoints(ctx context.Context, lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error), name string) ([]string, error) {
+	_, records, err := lookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		endpoints = append(endpoints, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+	}
+	// The records are shuffled by weight on every lookup, the endpoints only change when the records do.
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// readEndpointsFile returns the endpoints listed in the file, one per line.
+func readEndpointsFile(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, _, err = net.SplitHostPort(line); err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q in %s: %w", line, path, err)
+		}
+		endpoints = append(endpoints, line)
+	}
+	return endpoints, scanner.Err()
+}
+
+func equalEndpoints(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestLoadBalancingSettings_Validate(t *testing.T) {
+	lbs := NewDefaultLoadBalancingSettings()
+	assert.False(t, lbs.Enabled())
+	assert.NoError(t, lbs.Validate())
+
+	lbs.Endpoints = []string{"localhost:4317", "otelcol2:4317"}
+	assert.True(t, lbs.Enabled())
+	assert.NoError(t, lbs.Validate())
+
+	lbs.DNSSRV = "_otlp._tcp.gateway.example.com"
+	assert.EqualError(t, lbs.Validate(), "only one of endpoints, dns_srv and file can be set")
+
+	lbs = NewDefaultLoadBalancingSettings()
+	lbs.Endpoints = []string{"localhost"}
+	assert.EqualError(t, lbs.Validate(), `invalid endpoint "localhost": address localhost: missing port in address`)
+
+	lbs = NewDefaultLoadBalancingSettings()
+	lbs.File = "endpoints.txt"
+	assert.NoError(t, lbs.Validate())
+	lbs.RefreshInterval = 0
+	assert.EqualError(t, lbs.Validate(), "refresh interval must be positive")
+}
+
+func TestConfigValidateLoadBalancing(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LoadBalancing.Endpoints = []string{"localhost:4317"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Endpoint = "localhost:4317"
+	assert.EqualError(t, cfg.Validate(), "endpoint and balancer_name cannot be set with load_balancing")
+
+	cfg.Endpoint = ""
+	cfg.LoadBalancing.Endpoints = []string{"localhost"}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestReadEndpointsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("# gateways\nlocalhost:4317\n\n  otelcol2:4317  \n"), 0600))
+	endpoints, err := readEndpointsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"localhost:4317", "otelcol2:4317"}, endpoints)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("localhost\n"), 0600))
+	_, err = readEndpointsFile(path)
+	assert.Error(t, err)
+
+	_, err = readEndpointsFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestLookupSRVEndpoints(t *testing.T) {
+	lookupSRV := func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		assert.Empty(t, service)
+		assert.Empty(t, proto)
+		if name != "_otlp._tcp.gateway.example.com" {
+			return "", nil, errors.New("no such host")
+		}
+		return name, []*net.SRV{
+			{Target: "gw2.example.com.", Port: 4317},
+			{Target: "gw1.example.com.", Port: 4318},
+		}, nil
+	}
+
+	endpoints, err := lookupSRVEndpoints(context.Background(), lookupSRV, "_otlp._tcp.gateway.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gw1.example.com:4318", "gw2.example.com:4317"}, endpoints)
+
+	_, err = lookupSRVEndpoints(context.Background(), lookupSRV, "_otlp._tcp.unknown.example.com")
+	assert.Error(t, err)
+}
+
+func TestEndpointsResolverResolveNow(t *testing.T) {
+	var lookups int32
+	lbs := NewDefaultLoadBalancingSettings()
+	lbs.DNSSRV = "_otlp._tcp.gateway.example.com"
+	lbs.RefreshInterval = time.Hour
+	builder := newEndpointsResolverBuilder(lbs, zap.NewNop())
+	builder.lookupSRV = func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		atomic.AddInt32(&lookups, 1)
+		return "", []*net.SRV{{Target: "gw1.example.com.", Port: 4317}}, nil
+	}
+	builder.minResolveNowInterval = 100 * time.Millisecond
+	r := builder.start(func([]string) error { return nil }, func(error) {})
+	t.Cleanup(r.Close)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lookups))
+
+	// The resolutions requested too early are delayed and merged.
+	for i := 0; i < 10; i++ {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&lookups) == 2 }, time.Second, time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&lookups))
+
+	// A resolution requested after the minimum interval is immediate.
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&lookups) == 3 }, 50*time.Millisecond, time.Millisecond)
+}
+
+// loadBalancedReceiver is an OTLP traces receiver which also serves the gRPC health checking service.
+type loadBalancedReceiver struct {
+	*mockTracesReceiver
+	health *health.Server
+	addr   string
+}
+
+func startLoadBalancedReceiver(t *testing.T, servingStatus grpc_health_v1.HealthCheckResponse_ServingStatus) *loadBalancedReceiver {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	rcv := &loadBalancedReceiver{
+		mockTracesReceiver: &mockTracesReceiver{
+			mockReceiver: mockReceiver{srv: grpc.NewServer()},
+			response:     otlpgrpc.NewTracesResponse(),
+		},
+		health: health.NewServer(),
+		addr:   ln.Addr().String(),
+	}
+	rcv.health.SetServingStatus("", servingStatus)
+	otlpgrpc.RegisterTracesServer(rcv.srv, rcv.mockTracesReceiver)
+	grpc_health_v1.RegisterHealthServer(rcv.srv, rcv.health)
+	go func() {
+		_ = rcv.srv.Serve(ln)
+	}()
+	t.Cleanup(rcv.srv.Stop)
+	return rcv
+}
+
+func (r *loadBalancedReceiver) requests() int32 {
+	return atomic.LoadInt32(&r.requestCount)
+}
+
+func startLoadBalancedExporter(t *testing.T, lbs LoadBalancingSettings) component.TracesExporter {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPCClientSettings = configgrpc.GRPCClientSettings{
+		TLSSetting: configtls.TLSClientSetting{
+			Insecure: true,
+		},
+	}
+	cfg.LoadBalancing = lbs
+	cfg.QueueSettings.Enabled = false
+	// The requests sent to an endpoint going down are retried on the other ones.
+	cfg.RetrySettings.InitialInterval = 10 * time.Millisecond
+	require.NoError(t, cfg.Validate())
+
+	exp, err := factory.CreateTracesExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	})
+	return exp
+}
+
+func TestSendTracesLoadBalanced(t *testing.T) {
+	rcv1 := startLoadBalancedReceiver(t, grpc_health_v1.HealthCheckResponse_SERVING)
+	rcv2 := startLoadBalancedReceiver(t, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	lbs := NewDefaultLoadBalancingSettings()
+	lbs.Endpoints = []string{rcv1.addr, rcv2.addr}
+	exp := startLoadBalancedExporter(t, lbs)
+
+	// The requests are sent to both endpoints once the exporter is connected to them.
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, exp.ConsumeTraces(context.Background(), pdata.NewTraces()))
+		return rcv1.requests() > 0 && rcv2.requests() > 0
+	}, 10*time.Second, 5*time.Millisecond)
+
+	// An endpoint going down fails over to the other one.
+	rcv1.srv.Stop()
+	sent := rcv2.requests()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, exp.ConsumeTraces(context.Background(), pdata.NewTraces()))
+	}
+	assert.EqualValues(t, sent+5, rcv2.requests())
+}
+
+func TestSendTracesLoadBalancedHealthCheck(t *testing.T) {
+	rcv1 := startLoadBalancedReceiver(t, grpc_health_v1.HealthCheckResponse_SERVING)
+	rcv2 := startLoadBalancedReceiver(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	lbs := NewDefaultLoadBalancingSettings()
+	lbs.Endpoints = []string{rcv1.addr, rcv2.addr}
+	lbs.HealthCheck = true
+	exp := startLoadBalancedExporter(t, lbs)
+
+	// The endpoint not serving receives no requests.
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, exp.ConsumeTraces(context.Background(), pdata.NewTraces()))
+	}
+	assert.EqualValues(t, 5, rcv1.requests())
+	assert.EqualValues(t, 0, rcv2.requests())
+
+	rcv2.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, exp.ConsumeTraces(context.Background(), pdata.NewTraces()))
+		return rcv2.requests() > 0
+	}, 10*time.Second, 5*time.Millisecond)
+}
+
+func TestSendTracesLoadBalancedFile(t *testing.T) {
+	rcv1 := startLoadBalancedReceiver(t, grpc_health_v1.HealthCheckResponse_SERVING)
+	rcv2 := startLoadBalancedReceiver(t, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	path := filepath.Join(t.TempDir(), "endpoints.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte(rcv1.addr+"\n"), 0600))
+
+	lbs := NewDefaultLoadBalancingSettings()
+	lbs.File = path
+	// The file is read again as soon as it changes, not only every refresh interval.
+	lbs.RefreshInterval = time.Hour
+	exp := startLoadBalancedExporter(t, lbs)
+
+	assert.NoError(t, exp.ConsumeTraces(context.Background(), pdata.NewTraces()))
+	assert.EqualValues(t, 1, rcv1.requests())
+
+	// The requests are sent to the new endpoint once the file is replaced.
+	tmpPath := path + ".tmp"
+	require.NoError(t, ioutil.WriteFile(tmpPath, []byte(rcv2.addr+"\n"), 0600))
+	require.NoError(t, os.Rename(tmpPath, path))
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, exp.ConsumeTraces(context.Background(), pdata.NewTraces()))
+		return rcv2.requests() > 0
+	}, 10*time.Second, 5*time.Millisecond)
+}
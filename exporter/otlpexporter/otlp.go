@@ -31,6 +31,7 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/internal/partialsuccess"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/model/pdata"
 )
@@ -47,6 +48,9 @@ type exporter struct {
 	metadata       metadata.MD
 	callOptions    []grpc.CallOption
 
+	// router sends the data to the endpoints owning its routing keys instead of the clients, if a routing key is set.
+	router *router
+
 	settings component.TelemetrySettings
 
 	// Default user-agent header.
@@ -58,8 +62,8 @@ type exporter struct {
 func newExporter(cfg config.Exporter, settings component.TelemetrySettings, buildInfo component.BuildInfo) (*exporter, error) {
 	oCfg := cfg.(*Config)
 
-	if oCfg.Endpoint == "" {
-		return nil, errors.New("OTLP exporter config requires an Endpoint")
+	if oCfg.Endpoint == "" && !oCfg.LoadBalancing.Enabled() {
+		return nil, errors.New("OTLP exporter config requires an Endpoint or LoadBalancing endpoints")
 	}
 
 	userAgent := fmt.Sprintf("%s/%s (%s/%s)",
@@ -77,44 +81,93 @@ func (e *exporter) start(_ context.Context, host component.Host) (err error) {
 	}
 	dialOpts = append(dialOpts, grpc.WithUserAgent(e.userAgent))
 
-	if e.clientConn, err = grpc.Dial(e.config.GRPCClientSettings.SanitizedEndpoint(), dialOpts...); err != nil {
+	e.metadata = metadata.New(e.config.GRPCClientSettings.Headers)
+	e.callOptions = []grpc.CallOption{
+		grpc.WaitForReady(e.config.GRPCClientSettings.WaitForReady),
+	}
+
+	if e.config.LoadBalancing.RoutingKey != RoutingKeyNone {
+		e.router = newRouter(e.config.LoadBalancing, dialOpts, e.settings.Logger)
+		e.router.start()
+		return nil
+	}
+
+	target := e.config.GRPCClientSettings.SanitizedEndpoint()
+	if e.config.LoadBalancing.Enabled() {
+		var lbOpts []grpc.DialOption
+		target, lbOpts = e.config.LoadBalancing.toDialTarget(e.settings.Logger)
+		dialOpts = append(dialOpts, lbOpts...)
+	}
+
+	if e.clientConn, err = grpc.Dial(target, dialOpts...); err != nil {
 		return err
 	}
 
 	e.traceExporter = otlpgrpc.NewTracesClient(e.clientConn)
 	e.metricExporter = otlpgrpc.NewMetricsClient(e.clientConn)
 	e.logExporter = otlpgrpc.NewLogsClient(e.clientConn)
-	e.metadata = metadata.New(e.config.GRPCClientSettings.Headers)
-	e.callOptions = []grpc.CallOption{
-		grpc.WaitForReady(e.config.GRPCClientSettings.WaitForReady),
-	}
-
-	return
+	return nil
 }
 
 func (e *exporter) shutdown(context.Context) error {
+	if e.router != nil {
+		return e.router.shutdown()
+	}
 	return e.clientConn.Close()
 }
 
 func (e *exporter) pushTraces(ctx context.Context, td pdata.Traces) error {
+	if e.router != nil {
+		return e.router.push(ctx, routedTraces{td: td, exportFunc: e.exportTraces})
+	}
+	return e.exportTraces(ctx, e.traceExporter, td)
+}
+
+func (e *exporter) exportTraces(ctx context.Context, client otlpgrpc.TracesClient, td pdata.Traces) error {
 	req := otlpgrpc.NewTracesRequest()
 	req.SetTraces(td)
-	_, err := e.traceExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
-	return processError(err)
+	resp, err := client.Export(e.enhanceContext(ctx), req, e.callOptions...)
+	if err != nil {
+		return processError(err)
+	}
+	partialSuccess := resp.PartialSuccess()
+	return partialsuccess.ToError(e.settings.Logger, partialSuccess.RejectedSpans(), partialSuccess.ErrorMessage())
 }
 
 func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
+	if e.router != nil {
+		return e.router.push(ctx, routedMetrics{md: md, exportFunc: e.exportMetrics})
+	}
+	return e.exportMetrics(ctx, e.metricExporter, md)
+}
+
+func (e *exporter) exportMetrics(ctx context.Context, client otlpgrpc.MetricsClient, md pdata.Metrics) error {
 	req := otlpgrpc.NewMetricsRequest()
 	req.SetMetrics(md)
-	_, err := e.metricExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
-	return processError(err)
+	resp, err := client.Export(e.enhanceContext(ctx), req, e.callOptions...)
+	if err != nil {
+		return processError(err)
+	}
+	partialSuccess := resp.PartialSuccess()
+	return partialsuccess.ToError(e.settings.Logger, partialSuccess.RejectedDataPoints(), partialSuccess.ErrorMessage())
 }
 
 func (e *exporter) pushLogs(ctx context.Context, ld pdata.Logs) error {
+	if e.router != nil {
+		return e.router.push(ctx, routedLogs{ld: ld, exportFunc: e.exportLogs})
+	}
+	return e.exportLogs(ctx, e.logExporter, ld)
+}
+
+func (e *exporter) exportLogs(ctx context.Context, client otlpgrpc.LogsClient, ld pdata.Logs) error {
 	req := otlpgrpc.NewLogsRequest()
 	req.SetLogs(ld)
-	_, err := e.logExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
-	return processError(err)
+	resp, err := client.Export(e.enhanceContext(ctx), req, e.callOptions...)
+	if err != nil {
+		return processError(err)
+	}
+	partialSuccess := resp.PartialSuccess()
+	return partialsuccess.ToError(e.settings.Logger, partialSuccess.RejectedLogRecords(), partialSuccess.ErrorMessage())
 }
 
 func (e *exporter) enhanceContext(ctx context.Context) context.Context {
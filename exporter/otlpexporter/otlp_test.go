@@ -16,6 +16,7 @@ package otlpexporter
 
 import (
 	"context"
+	"errors"
 	"net"
 	"path/filepath"
 	"runtime"
@@ -26,14 +27,20 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/model/pdata"
@@ -56,6 +63,7 @@ func (r *mockReceiver) GetMetadata() metadata.MD {
 type mockTracesReceiver struct {
 	mockReceiver
 	lastRequest pdata.Traces
+	response    otlpgrpc.TracesResponse
 }
 
 func (r *mockTracesReceiver) Export(ctx context.Context, req otlpgrpc.TracesRequest) (otlpgrpc.TracesResponse, error) {
@@ -66,7 +74,14 @@ func (r *mockTracesReceiver) Export(ctx context.Context, req otlpgrpc.TracesRequ
 	defer r.mux.Unlock()
 	r.lastRequest = td
 	r.metadata, _ = metadata.FromIncomingContext(ctx)
-	return otlpgrpc.NewTracesResponse(), nil
+	return r.response, nil
+}
+
+func (r *mockTracesReceiver) setPartialSuccess(rejected int64, errorMessage string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.response.PartialSuccess().SetRejectedSpans(rejected)
+	r.response.PartialSuccess().SetErrorMessage(errorMessage)
 }
 
 func (r *mockTracesReceiver) GetLastRequest() pdata.Traces {
@@ -95,6 +110,7 @@ func otlpTracesReceiverOnGRPCServer(ln net.Listener, useTLS bool) (*mockTracesRe
 		mockReceiver: mockReceiver{
 			srv: grpc.NewServer(sopts...),
 		},
+		response: otlpgrpc.NewTracesResponse(),
 	}
 
 	// Now run it as a gRPC server
@@ -603,3 +619,71 @@ func TestSendLogData(t *testing.T) {
 	require.Equal(t, len(md.Get("User-Agent")), 1)
 	require.Contains(t, md.Get("User-Agent")[0], "Collector/1.2.3test")
 }
+
+func TestProcessError(t *testing.T) {
+	withRetryInfo := func(code codes.Code, delay time.Duration) error {
+		st, err := status.New(code, "throttled").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+		require.NoError(t, err)
+		return st.Err()
+	}
+
+	assert.NoError(t, processError(nil))
+	assert.NoError(t, processError(status.Error(codes.OK, "")))
+
+	err := processError(status.Error(codes.InvalidArgument, "bad request"))
+	assert.True(t, consumererror.IsPermanent(err))
+
+	grpcErr := status.Error(codes.Unavailable, "unavailable")
+	assert.Equal(t, grpcErr, processError(grpcErr))
+
+	grpcErr = withRetryInfo(codes.ResourceExhausted, 3*time.Second)
+	assert.Equal(t, exporterhelper.NewThrottleRetry(grpcErr, 3*time.Second), processError(grpcErr))
+
+	grpcErr = withRetryInfo(codes.Unavailable, 1500*time.Millisecond)
+	assert.Equal(t, exporterhelper.NewThrottleRetry(grpcErr, 1500*time.Millisecond), processError(grpcErr))
+
+	// A zero delay leaves the retry handler apply its backoff.
+	grpcErr = withRetryInfo(codes.Unavailable, 0)
+	assert.Equal(t, grpcErr, processError(grpcErr))
+}
+
+func TestSendTracesPartialSuccess(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err, "Failed to find an available address to run the gRPC server: %v", err)
+	rcv, _ := otlpTracesReceiverOnGRPCServer(ln, false)
+	defer rcv.srv.GracefulStop()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPCClientSettings = configgrpc.GRPCClientSettings{
+		Endpoint: ln.Addr().String(),
+		TLSSetting: configtls.TLSClientSetting{
+			Insecure: true,
+		},
+	}
+	cfg.QueueSettings.Enabled = false
+	set := componenttest.NewNopExporterCreateSettings()
+	exp, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, exp)
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	}()
+	assert.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+
+	// A partial success without rejected spans is a success.
+	rcv.setPartialSuccess(0, "spans were sampled")
+	assert.NoError(t, exp.ConsumeTraces(context.Background(), testdata.GenerateTracesTwoSpansSameResource()))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&rcv.requestCount))
+
+	// The rejected spans fail the request, which is not retried.
+	rcv.setPartialSuccess(1, "invalid span")
+	err = exp.ConsumeTraces(context.Background(), testdata.GenerateTracesTwoSpansSameResource())
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	var partial consumererror.Partial
+	require.True(t, errors.As(err, &partial))
+	assert.Equal(t, 1, partial.Failed())
+	assert.Contains(t, err.Error(), "invalid span")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&rcv.requestCount))
+}
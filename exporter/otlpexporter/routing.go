@@ -0,0 +1,447 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexporter"
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// RoutingKey is the key the data is routed by to the endpoints of the LoadBalancingSettings.
+type RoutingKey string
+
+const (
+	// RoutingKeyNone balances the requests between the endpoints in a round robin fashion.
+	RoutingKeyNone RoutingKey = ""
+	// RoutingKeyTraceID routes all the spans and log records of a trace to the same endpoint.
+	// It is not supported for metrics.
+	RoutingKeyTraceID RoutingKey = "trace_id"
+	// RoutingKeyResourceAttribute routes all the data of the resources having the same value of the routing attribute
+	// to the same endpoint.
+	RoutingKeyResourceAttribute RoutingKey = "resource_attribute"
+)
+
+var errNoEndpoints = errors.New("no endpoints to route the data to")
+
+// routedBackend is the connection and the clients to an endpoint the data is routed to.
+type routedBackend struct {
+	conn    *grpc.ClientConn
+	traces  otlpgrpc.TracesClient
+	metrics otlpgrpc.MetricsClient
+	logs    otlpgrpc.LogsClient
+}
+
+// router splits the data by routing key and sends every part to the endpoint owning its key on a consistent hash
+// ring, so the same keys keep being sent to the same endpoints when endpoints are added or removed.
+type router struct {
+	cfg      LoadBalancingSettings
+	dialOpts []grpc.DialOption
+	logger   *zap.Logger
+	resolver *endpointsResolver
+
+	mu       sync.RWMutex
+	ring     *hashRing
+	backends map[string]*routedBackend
+}
+
+func newRouter(cfg LoadBalancingSettings, dialOpts []grpc.DialOption, logger *zap.Logger) *router {
+	return &router{
+		cfg:      cfg,
+		dialOpts: dialOpts,
+		logger:   logger,
+		backends: map[string]*routedBackend{},
+	}
+}
+
+func (r *router) start() {
+	// The resolver logs the failures to resolve the endpoints, the last ones keep being used.
+	r.resolver = newEndpointsResolverBuilder(r.cfg, r.logger).start(r.updateEndpoints, func(error) {})
+}
+
+func (r *router) shutdown() error {
+	r.resolver.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs error
+	for _, backend := range r.backends {
+		errs = multierr.Append(errs, backend.conn.Close())
+	}
+	r.backends = nil
+	r.ring = nil
+	return errs
+}
+
+// updateEndpoints connects to the new endpoints, disconnects from the removed ones and rebuilds the ring.
+func (r *router) updateEndpoints(endpoints []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backends := make(map[string]*routedBackend, len(endpoints))
+	for _, endpoint := range endpoints {
+		if backend, ok := r.backends[endpoint]; ok {
+			backends[endpoint] = backend
+			continue
+		}
+		conn, err := grpc.Dial(endpoint, r.dialOpts...)
+		if err != nil {
+			// Keep routing to the previous endpoints.
+			for e, backend := range backends {
+				if _, ok := r.backends[e]; !ok {
+					_ = backend.conn.Close()
+				}
+			}
+			return err
+		}
+		backends[endpoint] = &routedBackend{
+			conn:    conn,
+			traces:  otlpgrpc.NewTracesClient(conn),
+			metrics: otlpgrpc.NewMetricsClient(conn),
+			logs:    otlpgrpc.NewLogsClient(conn),
+		}
+	}
+
+	// The requests in flight to the removed endpoints fail and are retried to the endpoints now owning their keys.
+	for endpoint, backend := range r.backends {
+		if _, ok := backends[endpoint]; !ok {
+			_ = backend.conn.Close()
+		}
+	}
+	r.backends = backends
+	r.ring = newHashRing(endpoints)
+	return nil
+}
+
+func (r *router) snapshot() (*hashRing, map[string]*routedBackend) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ring, r.backends
+}
+
+// resourceKey returns the value of the routing attribute of the resource, or an empty key if it is missing.
+func (r *router) resourceKey(resource pdata.Resource) []byte {
+	value, ok := resource.Attributes().Get(r.cfg.RoutingAttribute)
+	if !ok {
+		return nil
+	}
+	return []byte(value.AsString())
+}
+
+// push splits the data by routing key and exports every part to the endpoint owning its keys.
+func (r *router) push(ctx context.Context, data routedData) error {
+	ring, backends := r.snapshot()
+	if ring == nil {
+		return errNoEndpoints
+	}
+	parts := r.route(ring, data)
+	endpoints := make([]string, 0, len(parts))
+	for endpoint := range parts {
+		endpoints = append(endpoints, endpoint)
+	}
+
+	failedEndpoints, err := r.exportRouted(endpoints, func(endpoint string) error {
+		return parts[endpoint].export(ctx, backends[endpoint])
+	})
+	if len(failedEndpoints) == 0 {
+		return err
+	}
+	failedParts := make([]routedPart, 0, len(failedEndpoints))
+	for _, endpoint := range failedEndpoints {
+		failedParts = append(failedParts, parts[endpoint])
+	}
+	return data.failed(err, failedParts)
+}
+
+// exportRouted exports the data routed to every endpoint concurrently. If some exports failed with retryable errors,
+// it returns their endpoints and errors, the data rejected permanently by the other endpoints is dropped. Otherwise,
+// it returns the permanent errors.
+func (r *router) exportRouted(endpoints []string, export func(endpoint string) error) ([]string, error) {
+	sort.Strings(endpoints)
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = export(endpoints[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var failedEndpoints []string
+	var retryable, permanent error
+	for i, err := range errs {
+		switch {
+		case err == nil:
+		case consumererror.IsPermanent(err):
+			permanent = multierr.Append(permanent, err)
+		default:
+			failedEndpoints = append(failedEndpoints, endpoints[i])
+			retryable = multierr.Append(retryable, err)
+		}
+	}
+	if retryable == nil {
+		return nil, permanent
+	}
+	if permanent != nil {
+		r.logger.Error("Dropping data rejected permanently by some endpoints", zap.Error(permanent))
+	}
+	return failedEndpoints, retryable
+}
+
+// route splits the data by the endpoints owning their routing keys. When routed by trace ID, the records keep their
+// resource and scope.
+func (r *router) route(ring *hashRing, data routedData) map[string]routedPart {
+	parts := make(map[string]routedPart)
+	part := func(endpoint string) routedPart {
+		p, ok := parts[endpoint]
+		if !ok {
+			p = data.newPart()
+			parts[endpoint] = p
+		}
+		return p
+	}
+
+	records, ok := data.(traceRoutedData)
+	byTraceID := ok && r.cfg.RoutingKey != RoutingKeyResourceAttribute
+	for i := 0; i < data.resourceLen(); i++ {
+		if !byTraceID {
+			part(ring.endpoint(r.resourceKey(data.resource(i)))).appendResource(i)
+			continue
+		}
+		records.traceIDs(i, func(j, k int, traceID pdata.TraceID) {
+			id := traceID.Bytes()
+			part(ring.endpoint(id[:])).(traceRoutedPart).appendRecord(i, j, k)
+		})
+	}
+	return parts
+}
+
+// routedData adapts the data of a signal to the router, which splits and exports the data of all the signals the
+// same way.
+type routedData interface {
+	// resourceLen returns the number of resources of the data.
+	resourceLen() int
+	// resource returns the i-th resource of the data.
+	resource(i int) pdata.Resource
+	// newPart returns an empty part of the data, routed to an endpoint.
+	newPart() routedPart
+	// failed returns the error reporting the data of the parts which failed to be exported, so it is retried.
+	failed(err error, parts []routedPart) error
+}
+
+// routedPart is the part of the data routed to an endpoint.
+type routedPart interface {
+	// appendResource copies the i-th resource of the data into the part, with all its records.
+	appendResource(i int)
+	// export sends the part to the backend of its endpoint.
+	export(ctx context.Context, backend *routedBackend) error
+}
+
+// traceRoutedData is implemented by the data of the signals whose records can be routed by trace ID.
+type traceRoutedData interface {
+	routedData
+	// traceIDs calls fn with the trace ID of every record of the i-th resource, and the indexes of its scope in
+	// the resource and of the record in the scope.
+	traceIDs(i int, fn func(j, k int, traceID pdata.TraceID))
+}
+
+// traceRoutedPart is the part of the data of a traceRoutedData.
+type traceRoutedPart interface {
+	routedPart
+	// appendRecord copies the k-th record of the j-th scope of the i-th resource of the data into the part, with its
+	// resource and scope. The records are appended in order, so the records of a scope share the same copies.
+	appendRecord(i, j, k int)
+}
+
+type routedTraces struct {
+	td         pdata.Traces
+	exportFunc func(context.Context, otlpgrpc.TracesClient, pdata.Traces) error
+}
+
+func (rt routedTraces) resourceLen() int { return rt.td.ResourceSpans().Len() }
+
+func (rt routedTraces) resource(i int) pdata.Resource { return rt.td.ResourceSpans().At(i).Resource() }
+
+func (rt routedTraces) traceIDs(i int, fn func(j, k int, traceID pdata.TraceID)) {
+	sss := rt.td.ResourceSpans().At(i).ScopeSpans()
+	for j := 0; j < sss.Len(); j++ {
+		spans := sss.At(j).Spans()
+		for k := 0; k < spans.Len(); k++ {
+			fn(j, k, spans.At(k).TraceID())
+		}
+	}
+}
+
+func (rt routedTraces) newPart() routedPart {
+	return &tracesPart{routedTraces: rt, part: pdata.NewTraces(), lastResource: -1}
+}
+
+func (rt routedTraces) failed(err error, parts []routedPart) error {
+	failed := pdata.NewTraces()
+	for _, p := range parts {
+		p.(*tracesPart).part.ResourceSpans().MoveAndAppendTo(failed.ResourceSpans())
+	}
+	return consumererror.NewTraces(err, failed)
+}
+
+type tracesPart struct {
+	routedTraces
+	part pdata.Traces
+	// lastResource and lastScope are the indexes of the resource and scope of the last span appended, copied to rs
+	// and ss.
+	lastResource, lastScope int
+	rs                      pdata.ResourceSpans
+	ss                      pdata.ScopeSpans
+}
+
+func (tp *tracesPart) appendResource(i int) {
+	tp.td.ResourceSpans().At(i).CopyTo(tp.part.ResourceSpans().AppendEmpty())
+}
+
+func (tp *tracesPart) appendRecord(i, j, k int) {
+	rs := tp.td.ResourceSpans().At(i)
+	ss := rs.ScopeSpans().At(j)
+	if i != tp.lastResource {
+		tp.rs = tp.part.ResourceSpans().AppendEmpty()
+		rs.Resource().CopyTo(tp.rs.Resource())
+		tp.rs.SetSchemaUrl(rs.SchemaUrl())
+		tp.lastResource, tp.lastScope = i, -1
+	}
+	if j != tp.lastScope {
+		tp.ss = tp.rs.ScopeSpans().AppendEmpty()
+		ss.Scope().CopyTo(tp.ss.Scope())
+		tp.ss.SetSchemaUrl(ss.SchemaUrl())
+		tp.lastScope = j
+	}
+	ss.Spans().At(k).CopyTo(tp.ss.Spans().AppendEmpty())
+}
+
+func (tp *tracesPart) export(ctx context.Context, backend *routedBackend) error {
+	return tp.exportFunc(ctx, backend.traces, tp.part)
+}
+
+// routedMetrics is only routed by the routing attribute of the resources.
+type routedMetrics struct {
+	md         pdata.Metrics
+	exportFunc func(context.Context, otlpgrpc.MetricsClient, pdata.Metrics) error
+}
+
+func (rm routedMetrics) resourceLen() int { return rm.md.ResourceMetrics().Len() }
+
+func (rm routedMetrics) resource(i int) pdata.Resource {
+	return rm.md.ResourceMetrics().At(i).Resource()
+}
+
+func (rm routedMetrics) newPart() routedPart {
+	return &metricsPart{routedMetrics: rm, part: pdata.NewMetrics()}
+}
+
+func (rm routedMetrics) failed(err error, parts []routedPart) error {
+	failed := pdata.NewMetrics()
+	for _, p := range parts {
+		p.(*metricsPart).part.ResourceMetrics().MoveAndAppendTo(failed.ResourceMetrics())
+	}
+	return consumererror.NewMetrics(err, failed)
+}
+
+type metricsPart struct {
+	routedMetrics
+	part pdata.Metrics
+}
+
+func (mp *metricsPart) appendResource(i int) {
+	mp.md.ResourceMetrics().At(i).CopyTo(mp.part.ResourceMetrics().AppendEmpty())
+}
+
+func (mp *metricsPart) export(ctx context.Context, backend *routedBackend) error {
+	return mp.exportFunc(ctx, backend.metrics, mp.part)
+}
+
+type routedLogs struct {
+	ld         pdata.Logs
+	exportFunc func(context.Context, otlpgrpc.LogsClient, pdata.Logs) error
+}
+
+func (rl routedLogs) resourceLen() int { return rl.ld.ResourceLogs().Len() }
+
+func (rl routedLogs) resource(i int) pdata.Resource { return rl.ld.ResourceLogs().At(i).Resource() }
+
+func (rl routedLogs) traceIDs(i int, fn func(j, k int, traceID pdata.TraceID)) {
+	sls := rl.ld.ResourceLogs().At(i).ScopeLogs()
+	for j := 0; j < sls.Len(); j++ {
+		logs := sls.At(j).LogRecords()
+		for k := 0; k < logs.Len(); k++ {
+			fn(j, k, logs.At(k).TraceID())
+		}
+	}
+}
+
+func (rl routedLogs) newPart() routedPart {
+	return &logsPart{routedLogs: rl, part: pdata.NewLogs(), lastResource: -1}
+}
+
+func (rl routedLogs) failed(err error, parts []routedPart) error {
+	failed := pdata.NewLogs()
+	for _, p := range parts {
+		p.(*logsPart).part.ResourceLogs().MoveAndAppendTo(failed.ResourceLogs())
+	}
+	return consumererror.NewLogs(err, failed)
+}
+
+type logsPart struct {
+	routedLogs
+	part pdata.Logs
+	// lastResource and lastScope are the indexes of the resource and scope of the last log record appended, copied
+	// to rl and sl.
+	lastResource, lastScope int
+	rl                      pdata.ResourceLogs
+	sl                      pdata.ScopeLogs
+}
+
+func (lp *logsPart) appendResource(i int) {
+	lp.ld.ResourceLogs().At(i).CopyTo(lp.part.ResourceLogs().AppendEmpty())
+}
+
+func (lp *logsPart) appendRecord(i, j, k int) {
+	rl := lp.ld.ResourceLogs().At(i)
+	sl := rl.ScopeLogs().At(j)
+	if i != lp.lastResource {
+		lp.rl = lp.part.ResourceLogs().AppendEmpty()
+		rl.Resource().CopyTo(lp.rl.Resource())
+		lp.rl.SetSchemaUrl(rl.SchemaUrl())
+		lp.lastResource, lp.lastScope = i, -1
+	}
+	if j != lp.lastScope {
+		lp.sl = lp.rl.ScopeLogs().AppendEmpty()
+		sl.Scope().CopyTo(lp.sl.Scope())
+		lp.sl.SetSchemaUrl(sl.SchemaUrl())
+		lp.lastScope = j
+	}
+	sl.LogRecords().At(k).CopyTo(lp.sl.LogRecords().AppendEmpty())
+}
+
+func (lp *logsPart) export(ctx context.Context, backend *routedBackend) error {
+	return lp.exportFunc(ctx, backend.logs, lp.part)
+}
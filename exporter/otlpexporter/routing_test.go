@@ -0,0 +1,333 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestLoadBalancingSettings_ValidateRouting(t *testing.T) {
+	lbs := NewDefaultLoadBalancingSettings()
+	lbs.RoutingKey = RoutingKeyTraceID
+	assert.EqualError(t, lbs.Validate(), "routing key cannot be set without endpoints")
+
+	lbs.Endpoints = []string{"gw1:4317", "gw2:4317"}
+	assert.NoError(t, lbs.Validate())
+
+	lbs.HealthCheck = true
+	assert.EqualError(t, lbs.Validate(), "health check cannot be enabled with a routing key")
+
+	lbs.HealthCheck = false
+	lbs.RoutingAttribute = "service.name"
+	assert.EqualError(t, lbs.Validate(), `routing attribute can only be set with the "resource_attribute" routing key`)
+
+	lbs.RoutingKey = RoutingKeyResourceAttribute
+	assert.NoError(t, lbs.Validate())
+
+	lbs.RoutingAttribute = ""
+	assert.EqualError(t, lbs.Validate(), `routing attribute must be set with the "resource_attribute" routing key`)
+
+	lbs.RoutingKey = "span_id"
+	assert.EqualError(t, lbs.Validate(), `unknown routing key "span_id"`)
+}
+
+func TestCreateMetricsExporterRoutedByTraceID(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.LoadBalancing.Endpoints = []string{"gw1:4317"}
+	cfg.LoadBalancing.RoutingKey = RoutingKeyTraceID
+	_, err := factory.CreateMetricsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	assert.EqualError(t, err, "metrics cannot be routed by trace_id")
+}
+
+func newTestTraceID(i int) pdata.TraceID {
+	return pdata.NewTraceID([16]byte{byte(i), byte(i >> 8), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14})
+}
+
+func generateTracesManyTraces(traceCount int) pdata.Traces {
+	td := testdata.GenerateTracesManySpansSameResource(2 * traceCount)
+	spans := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	for i := 0; i < spans.Len(); i++ {
+		spans.At(i).SetTraceID(newTestTraceID(i / 2))
+	}
+	return td
+}
+
+func routedTracesParts(parts map[string]routedPart) map[string]pdata.Traces {
+	groups := make(map[string]pdata.Traces, len(parts))
+	for endpoint, part := range parts {
+		groups[endpoint] = part.(*tracesPart).part
+	}
+	return groups
+}
+
+func routedMetricsParts(parts map[string]routedPart) map[string]pdata.Metrics {
+	groups := make(map[string]pdata.Metrics, len(parts))
+	for endpoint, part := range parts {
+		groups[endpoint] = part.(*metricsPart).part
+	}
+	return groups
+}
+
+func routedLogsParts(parts map[string]routedPart) map[string]pdata.Logs {
+	groups := make(map[string]pdata.Logs, len(parts))
+	for endpoint, part := range parts {
+		groups[endpoint] = part.(*logsPart).part
+	}
+	return groups
+}
+
+func TestRouteTraces(t *testing.T) {
+	r := newRouter(LoadBalancingSettings{RoutingKey: RoutingKeyTraceID}, nil, zap.NewNop())
+	ring := newHashRing([]string{"gw1:4317", "gw2:4317", "gw3:4317"})
+	td := generateTracesManyTraces(50)
+
+	groups := routedTracesParts(r.route(ring, routedTraces{td: td}))
+	assert.Len(t, groups, 3)
+	spanCount := 0
+	for endpoint, group := range groups {
+		spanCount += group.SpanCount()
+		rs := group.ResourceSpans().At(0)
+		assert.Equal(t, 1, group.ResourceSpans().Len())
+		assert.Equal(t, td.ResourceSpans().At(0).Resource(), rs.Resource())
+		spans := rs.ScopeSpans().At(0).Spans()
+		for i := 0; i < spans.Len(); i++ {
+			traceID := spans.At(i).TraceID().Bytes()
+			assert.Equal(t, endpoint, ring.endpoint(traceID[:]))
+		}
+	}
+	assert.Equal(t, td.SpanCount(), spanCount)
+	// The data is not modified.
+	assert.Equal(t, generateTracesManyTraces(50), td)
+
+	// The spans routed to the same endpoint keep their resources and scopes.
+	td = testdata.GenerateTracesTwoSpansSameResourceOneDifferent()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		spans := rss.At(i).ScopeSpans().At(0).Spans()
+		for j := 0; j < spans.Len(); j++ {
+			spans.At(j).SetTraceID(newTestTraceID(1))
+		}
+	}
+	groups = routedTracesParts(r.route(ring, routedTraces{td: td}))
+	require.Len(t, groups, 1)
+	for _, group := range groups {
+		assert.Equal(t, td, group)
+	}
+
+	r.cfg = LoadBalancingSettings{RoutingKey: RoutingKeyResourceAttribute, RoutingAttribute: "resource-attr"}
+	groups = routedTracesParts(r.route(ring, routedTraces{td: testdata.GenerateTracesTwoSpansSameResourceOneDifferent()}))
+	require.Len(t, groups, 1)
+	for endpoint, group := range groups {
+		assert.Equal(t, ring.endpoint([]byte("resource-attr-val-1")), endpoint)
+		assert.Equal(t, testdata.GenerateTracesTwoSpansSameResourceOneDifferent(), group)
+	}
+}
+
+func TestRouteLogs(t *testing.T) {
+	r := newRouter(LoadBalancingSettings{RoutingKey: RoutingKeyTraceID}, nil, zap.NewNop())
+	ring := newHashRing([]string{"gw1:4317", "gw2:4317", "gw3:4317"})
+	ld := testdata.GenerateLogsManyLogRecordsSameResource(100)
+	logs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < logs.Len(); i++ {
+		logs.At(i).SetTraceID(newTestTraceID(i))
+	}
+
+	groups := routedLogsParts(r.route(ring, routedLogs{ld: ld}))
+	assert.Len(t, groups, 3)
+	logCount := 0
+	for endpoint, group := range groups {
+		logCount += group.LogRecordCount()
+		logs := group.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < logs.Len(); i++ {
+			traceID := logs.At(i).TraceID().Bytes()
+			assert.Equal(t, endpoint, ring.endpoint(traceID[:]))
+		}
+	}
+	assert.Equal(t, ld.LogRecordCount(), logCount)
+}
+
+func TestRouteMetrics(t *testing.T) {
+	r := newRouter(LoadBalancingSettings{RoutingKey: RoutingKeyResourceAttribute, RoutingAttribute: "host.name"}, nil, zap.NewNop())
+	ring := newHashRing([]string{"gw1:4317", "gw2:4317", "gw3:4317"})
+	md := pdata.NewMetrics()
+	for i := 0; i < 30; i++ {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("host.name", "host-"+string(rune('a'+i%10)))
+		rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("metric")
+	}
+	// The resources without the attribute are all routed to the same endpoint.
+	md.ResourceMetrics().AppendEmpty()
+
+	groups := routedMetricsParts(r.route(ring, routedMetrics{md: md}))
+	assert.Len(t, groups, 3)
+	resourceCount := 0
+	for endpoint, group := range groups {
+		resourceCount += group.ResourceMetrics().Len()
+		for i := 0; i < group.ResourceMetrics().Len(); i++ {
+			assert.Equal(t, endpoint, ring.endpoint(r.resourceKey(group.ResourceMetrics().At(i).Resource())))
+		}
+	}
+	assert.Equal(t, md.ResourceMetrics().Len(), resourceCount)
+}
+
+func TestExportRouted(t *testing.T) {
+	r := newRouter(LoadBalancingSettings{}, nil, zap.NewNop())
+	endpoints := []string{"gw3:4317", "gw1:4317", "gw2:4317"}
+
+	failedEndpoints, err := r.exportRouted(endpoints, func(string) error { return nil })
+	assert.NoError(t, err)
+	assert.Empty(t, failedEndpoints)
+
+	// Only the data of the endpoints failing with retryable errors is retried.
+	failedEndpoints, err = r.exportRouted(endpoints, func(endpoint string) error {
+		switch endpoint {
+		case "gw1:4317":
+			return consumererror.NewPermanent(errors.New("bad data"))
+		case "gw2:4317":
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, []string{"gw2:4317"}, failedEndpoints)
+
+	failedEndpoints, err = r.exportRouted(endpoints, func(endpoint string) error {
+		if endpoint == "gw1:4317" {
+			return consumererror.NewPermanent(errors.New("bad data"))
+		}
+		return nil
+	})
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Empty(t, failedEndpoints)
+}
+
+// traceIDsReceiver records the trace IDs of the spans it receives.
+type traceIDsReceiver struct {
+	srv  *grpc.Server
+	addr string
+
+	mu       sync.Mutex
+	traceIDs map[pdata.TraceID]int
+}
+
+func (r *traceIDsReceiver) Export(_ context.Context, req otlpgrpc.TracesRequest) (otlpgrpc.TracesResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rss := req.Traces().ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				r.traceIDs[spans.At(k).TraceID()]++
+			}
+		}
+	}
+	return otlpgrpc.NewTracesResponse(), nil
+}
+
+func (r *traceIDsReceiver) spans(traceID pdata.TraceID) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.traceIDs[traceID]
+}
+
+func startTraceIDsReceiver(t *testing.T) *traceIDsReceiver {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	rcv := &traceIDsReceiver{
+		srv:      grpc.NewServer(),
+		addr:     ln.Addr().String(),
+		traceIDs: map[pdata.TraceID]int{},
+	}
+	otlpgrpc.RegisterTracesServer(rcv.srv, rcv)
+	go func() {
+		_ = rcv.srv.Serve(ln)
+	}()
+	t.Cleanup(rcv.srv.Stop)
+	return rcv
+}
+
+func TestSendTracesRoutedByTraceID(t *testing.T) {
+	receivers := []*traceIDsReceiver{startTraceIDsReceiver(t), startTraceIDsReceiver(t), startTraceIDsReceiver(t)}
+
+	lbs := NewDefaultLoadBalancingSettings()
+	for _, rcv := range receivers {
+		lbs.Endpoints = append(lbs.Endpoints, rcv.addr)
+	}
+	lbs.RoutingKey = RoutingKeyTraceID
+	exp := startLoadBalancedExporter(t, lbs)
+
+	// Both spans of every trace are sent to the same receiver, in two batches.
+	require.NoError(t, exp.ConsumeTraces(context.Background(), generateTracesManyTraces(50)))
+	require.NoError(t, exp.ConsumeTraces(context.Background(), generateTracesManyTraces(50)))
+	for i := 0; i < 50; i++ {
+		receiving := 0
+		for _, rcv := range receivers {
+			switch rcv.spans(newTestTraceID(i)) {
+			case 0:
+			case 4:
+				receiving++
+			default:
+				assert.Fail(t, "spans of the trace sent to several receivers", "trace %d", i)
+			}
+		}
+		assert.Equal(t, 1, receiving, "trace %d", i)
+	}
+	for _, rcv := range receivers {
+		assert.NotEmpty(t, rcv.traceIDs)
+	}
+}
+
+func TestSendTracesRoutedEndpointDown(t *testing.T) {
+	rcv1 := startTraceIDsReceiver(t)
+	rcv2 := startTraceIDsReceiver(t)
+
+	lbs := NewDefaultLoadBalancingSettings()
+	lbs.Endpoints = []string{rcv1.addr, rcv2.addr}
+	lbs.RoutingKey = RoutingKeyTraceID
+	exp := startLoadBalancedExporter(t, lbs)
+
+	// The data routed to the endpoint which is down is retried until the context is done, the rest is sent.
+	rcv2.srv.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.Error(t, exp.ConsumeTraces(ctx, generateTracesManyTraces(50)))
+	for i := 0; i < 50; i++ {
+		traceID := newTestTraceID(i)
+		ring := newHashRing(lbs.Endpoints)
+		bytes := traceID.Bytes()
+		if ring.endpoint(bytes[:]) == rcv1.addr {
+			assert.Equal(t, 2, rcv1.spans(traceID), "trace %d", i)
+		}
+	}
+}
@@ -46,5 +46,11 @@ func (cfg *Config) Validate() error {
 	if cfg.Endpoint == "" && cfg.TracesEndpoint == "" && cfg.MetricsEndpoint == "" && cfg.LogsEndpoint == "" {
 		return fmt.Errorf("at least one endpoint must be specified")
 	}
+	if err := cfg.RetrySettings.Validate(); err != nil {
+		return fmt.Errorf("retry settings has invalid configuration: %w", err)
+	}
+	if err := cfg.TLSSetting.Validate(); err != nil {
+		return fmt.Errorf("tls settings has invalid configuration: %w", err)
+	}
 	return nil
 }
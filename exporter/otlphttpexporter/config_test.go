@@ -56,15 +56,20 @@ func TestLoadConfig(t *testing.T) {
 		&Config{
 			ExporterSettings: config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "2")),
 			RetrySettings: exporterhelper.RetrySettings{
-				Enabled:         true,
-				InitialInterval: 10 * time.Second,
-				MaxInterval:     1 * time.Minute,
-				MaxElapsedTime:  10 * time.Minute,
+				Enabled:          true,
+				InitialInterval:  10 * time.Second,
+				MaxInterval:      1 * time.Minute,
+				MaxElapsedTime:   10 * time.Minute,
+				MaxThrottleDelay: 5 * time.Minute,
+				Budget:           exporterhelper.NewDefaultRetryBudgetSettings(),
 			},
 			QueueSettings: exporterhelper.QueueSettings{
-				Enabled:      true,
-				NumConsumers: 2,
-				QueueSize:    10,
+				Enabled:             true,
+				NumConsumers:        2,
+				QueueSize:           10,
+				OverflowPolicy:      exporterhelper.OverflowRejectNew,
+				BlockTimeout:        5 * time.Second,
+				AdaptiveConcurrency: exporterhelper.NewDefaultAdaptiveConcurrencySettings(),
 			},
 			HTTPClientSettings: confighttp.HTTPClientSettings{
 				Headers: map[string]string{
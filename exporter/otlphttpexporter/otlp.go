@@ -35,6 +35,7 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/internal/partialsuccess"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/model/pdata"
 )
@@ -54,6 +55,8 @@ type exporter struct {
 
 const (
 	headerRetryAfter         = "Retry-After"
+	headerContentType        = "Content-Type"
+	protobufContentType      = "application/x-protobuf"
 	maxHTTPResponseReadBytes = 64 * 1024
 )
 
@@ -99,7 +102,7 @@ func (e *exporter) pushTraces(ctx context.Context, td pdata.Traces) error {
 		return consumererror.NewPermanent(err)
 	}
 
-	return e.export(ctx, e.tracesURL, request)
+	return e.export(ctx, e.tracesURL, request, e.tracesPartialSuccessHandler)
 }
 
 func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
@@ -109,7 +112,7 @@ func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
-	return e.export(ctx, e.metricsURL, request)
+	return e.export(ctx, e.metricsURL, request, e.metricsPartialSuccessHandler)
 }
 
 func (e *exporter) pushLogs(ctx context.Context, ld pdata.Logs) error {
@@ -120,16 +123,16 @@ func (e *exporter) pushLogs(ctx context.Context, ld pdata.Logs) error {
 		return consumererror.NewPermanent(err)
 	}
 
-	return e.export(ctx, e.logsURL, request)
+	return e.export(ctx, e.logsURL, request, e.logsPartialSuccessHandler)
 }
 
-func (e *exporter) export(ctx context.Context, url string, request []byte) error {
+func (e *exporter) export(ctx context.Context, url string, request []byte, partialSuccessHandler partialSuccessHandler) error {
 	e.logger.Debug("Preparing to make HTTP request", zap.String("url", url))
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(request))
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
-	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set(headerContentType, protobufContentType)
 	req.Header.Set("User-Agent", e.userAgent)
 
 	resp, err := e.client.Do(req)
@@ -144,8 +147,8 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 	}()
 
 	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		// Request is successful.
-		return nil
+		// Request is successful, the response may still report items rejected by the server.
+		return handlePartialSuccessResponse(resp, partialSuccessHandler)
 	}
 
 	respStatus := readResponse(resp)
@@ -165,16 +168,16 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 	// Check if the server is overwhelmed.
 	// See spec https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/protocol/otlp.md#throttling-1
 	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
-		// Fallback to 0 if the Retry-After header is not present. This will trigger the
+		// Fallback to 0 if the Retry-After header is not present or invalid. This will trigger the
 		// default backoff policy by our caller (retry handler).
-		retryAfter := 0
-		if val := resp.Header.Get(headerRetryAfter); val != "" {
-			if seconds, err2 := strconv.Atoi(val); err2 == nil {
-				retryAfter = seconds
-			}
+		retryAfter := parseRetryAfter(resp.Header.Get(headerRetryAfter), time.Now())
+		// Let the retry policy of the error class apply, overloaded and unavailable servers may be retried differently.
+		class := exporterhelper.RetryClassThrottled
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			class = exporterhelper.RetryClassUnavailable
 		}
-		// Indicate to our caller to pause for the specified number of seconds.
-		return exporterhelper.NewThrottleRetry(formattedErr, time.Duration(retryAfter)*time.Second)
+		// Indicate to our caller to pause for the delay requested by the server.
+		return exporterhelper.NewThrottleRetry(exporterhelper.NewClassifiedRetry(formattedErr, class), retryAfter)
 	}
 
 	if resp.StatusCode == http.StatusBadRequest {
@@ -186,6 +189,24 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 	return formattedErr
 }
 
+// parseRetryAfter returns the delay requested by a Retry-After header, either a number of seconds or an HTTP-date.
+// See https://datatracker.ietf.org/doc/html/rfc7231#section-7.1.3. Returns 0 if the value is empty, invalid or in the past.
+func parseRetryAfter(val string, now time.Time) time.Duration {
+	if val == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(val); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(val); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
 // Read the response and decode the status.Status from the body.
 // Returns nil if the response is empty or cannot be decoded.
 func readResponse(resp *http.Response) *status.Status {
@@ -194,13 +215,8 @@ func readResponse(resp *http.Response) *status.Status {
 		// Request failed. Read the body. OTLP spec says:
 		// "Response body for all HTTP 4xx and HTTP 5xx responses MUST be a
 		// Protobuf-encoded Status message that describes the problem."
-		maxRead := resp.ContentLength
-		if maxRead == -1 || maxRead > maxHTTPResponseReadBytes {
-			maxRead = maxHTTPResponseReadBytes
-		}
-		respBytes := make([]byte, maxRead)
-		n, err := io.ReadFull(resp.Body, respBytes)
-		if err == nil && n > 0 {
+		respBytes, err := readResponseBody(resp)
+		if err == nil && len(respBytes) > 0 {
 			// Decode it as Status struct. See https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/protocol/otlp.md#failures
 			respStatus = &status.Status{}
 			err = proto.Unmarshal(respBytes, respStatus)
@@ -212,3 +228,68 @@ func readResponse(resp *http.Response) *status.Status {
 
 	return respStatus
 }
+
+// readResponseBody reads at most maxHTTPResponseReadBytes of the body, which may be shorter than its Content-Length.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.ContentLength == 0 {
+		return nil, nil
+	}
+	maxRead := resp.ContentLength
+	if maxRead == -1 || maxRead > maxHTTPResponseReadBytes {
+		maxRead = maxHTTPResponseReadBytes
+	}
+	respBytes := make([]byte, maxRead)
+	n, err := io.ReadFull(resp.Body, respBytes)
+	// The body is shorter than the bytes to read when the Content-Length is unknown.
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return respBytes[:n], nil
+}
+
+// partialSuccessHandler decodes the body of a successful response and returns an error if items were rejected.
+type partialSuccessHandler func(protoBytes []byte) error
+
+// handlePartialSuccessResponse passes the body of a successful response to the handler. Servers which do not support
+// partial success may respond with an empty body or a different encoding, these responses are plain successes.
+func handlePartialSuccessResponse(resp *http.Response, handler partialSuccessHandler) error {
+	if resp.Header.Get(headerContentType) != protobufContentType {
+		return nil
+	}
+	respBytes, err := readResponseBody(resp)
+	if err != nil || len(respBytes) == 0 {
+		return nil
+	}
+	return handler(respBytes)
+}
+
+func (e *exporter) tracesPartialSuccessHandler(protoBytes []byte) error {
+	exportResponse := otlpgrpc.NewTracesResponse()
+	if err := exportResponse.UnmarshalProto(protoBytes); err != nil {
+		e.logger.Debug("Failed to decode the traces export response", zap.Error(err))
+		return nil
+	}
+	partialSuccess := exportResponse.PartialSuccess()
+	return partialsuccess.ToError(e.logger, partialSuccess.RejectedSpans(), partialSuccess.ErrorMessage())
+}
+
+func (e *exporter) metricsPartialSuccessHandler(protoBytes []byte) error {
+	exportResponse := otlpgrpc.NewMetricsResponse()
+	if err := exportResponse.UnmarshalProto(protoBytes); err != nil {
+		e.logger.Debug("Failed to decode the metrics export response", zap.Error(err))
+		return nil
+	}
+	partialSuccess := exportResponse.PartialSuccess()
+	return partialsuccess.ToError(e.logger, partialSuccess.RejectedDataPoints(), partialSuccess.ErrorMessage())
+}
+
+func (e *exporter) logsPartialSuccessHandler(protoBytes []byte) error {
+	exportResponse := otlpgrpc.NewLogsResponse()
+	if err := exportResponse.UnmarshalProto(protoBytes); err != nil {
+		e.logger.Debug("Failed to decode the logs export response", zap.Error(err))
+		return nil
+	}
+	partialSuccess := exportResponse.PartialSuccess()
+	return partialsuccess.ToError(e.logger, partialSuccess.RejectedLogRecords(), partialSuccess.ErrorMessage())
+}
+
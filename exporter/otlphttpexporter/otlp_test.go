@@ -26,6 +26,8 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -136,6 +138,26 @@ func TestTraceRoundTrip(t *testing.T) {
 	}
 }
 
+func TestTraceRoundTripUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not used on windows")
+	}
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "otlp.sock")
+
+	sink := new(consumertest.TracesSink)
+	startTracesReceiver(t, endpoint, sink)
+	exp := startTracesExporter(t, endpoint, "")
+
+	td := testdata.GenerateTracesOneSpan()
+	assert.NoError(t, exp.ConsumeTraces(context.Background(), td))
+	require.Eventually(t, func() bool {
+		return sink.SpanCount() > 0
+	}, 1*time.Second, 10*time.Millisecond)
+	allTraces := sink.AllTraces()
+	require.Len(t, allTraces, 1)
+	assert.EqualValues(t, td, allTraces[0])
+}
+
 func TestMetricsError(t *testing.T) {
 	addr := testutil.GetAvailableLocalAddress(t)
 
@@ -296,6 +318,75 @@ func TestIssue_4221(t *testing.T) {
 	assert.NoError(t, exp.ConsumeTraces(context.Background(), md))
 }
 
+func TestPartialSuccess(t *testing.T) {
+	assertPartial := func(t *testing.T, err error, rejected int) {
+		require.Error(t, err)
+		assert.True(t, consumererror.IsPermanent(err))
+		assert.Contains(t, err.Error(), "invalid item")
+		var partial consumererror.Partial
+		require.True(t, errors.As(err, &partial))
+		assert.Equal(t, rejected, partial.Failed())
+	}
+
+	t.Run("traces", func(t *testing.T) {
+		addr := testutil.GetAvailableLocalAddress(t)
+		rejected := testdata.GenerateTracesOneSpan()
+		startTracesReceiver(t, addr, consumertest.NewErr(consumererror.NewPermanent(consumererror.NewTraces(errors.New("invalid item"), rejected))))
+		exp := startTracesExporter(t, "", fmt.Sprintf("http://%s/v1/traces", addr))
+		assertPartial(t, exp.ConsumeTraces(context.Background(), testdata.GenerateTracesTwoSpansSameResource()), 1)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		addr := testutil.GetAvailableLocalAddress(t)
+		rejected := testdata.GenerateMetricsOneMetric()
+		startMetricsReceiver(t, addr, consumertest.NewErr(consumererror.NewPermanent(consumererror.NewMetrics(errors.New("invalid item"), rejected))))
+		exp := startMetricsExporter(t, "", fmt.Sprintf("http://%s/v1/metrics", addr))
+		assertPartial(t, exp.ConsumeMetrics(context.Background(), testdata.GenerateMetricsTwoMetrics()), rejected.DataPointCount())
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		addr := testutil.GetAvailableLocalAddress(t)
+		rejected := testdata.GenerateLogsOneLogRecord()
+		startLogsReceiver(t, addr, consumertest.NewErr(consumererror.NewPermanent(consumererror.NewLogs(errors.New("invalid item"), rejected))))
+		exp := startLogsExporter(t, "", fmt.Sprintf("http://%s/v1/logs", addr))
+		assertPartial(t, exp.ConsumeLogs(context.Background(), testdata.GenerateLogsTwoLogRecordsSameResource()), 1)
+	})
+
+	t.Run("all_rejected", func(t *testing.T) {
+		addr := testutil.GetAvailableLocalAddress(t)
+		td := testdata.GenerateTracesTwoSpansSameResource()
+		startTracesReceiver(t, addr, consumertest.NewErr(consumererror.NewPermanent(consumererror.NewTraces(errors.New("invalid item"), td))))
+		exp := startTracesExporter(t, "", fmt.Sprintf("http://%s/v1/traces", addr))
+		err := exp.ConsumeTraces(context.Background(), td)
+		require.Error(t, err)
+		var partial consumererror.Partial
+		assert.False(t, errors.As(err, &partial))
+	})
+}
+
+func TestHandlePartialSuccessResponse(t *testing.T) {
+	handler := func([]byte) error {
+		return errors.New("rejected")
+	}
+	newResponse := func(contentType string, body []byte) *http.Response {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{headerContentType: []string{contentType}},
+			ContentLength: int64(len(body)),
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		}
+	}
+
+	assert.NoError(t, handlePartialSuccessResponse(newResponse(protobufContentType, nil), handler))
+	assert.NoError(t, handlePartialSuccessResponse(newResponse("application/json", []byte("{}")), handler))
+	assert.EqualError(t, handlePartialSuccessResponse(newResponse(protobufContentType, []byte{0x0a, 0x00}), handler), "rejected")
+
+	// The body is read when its length is unknown.
+	resp := newResponse(protobufContentType, []byte{0x0a, 0x00})
+	resp.ContentLength = -1
+	assert.EqualError(t, handlePartialSuccessResponse(resp, handler), "rejected")
+}
+
 func startTracesExporter(t *testing.T, baseURL string, overrideURL string) component.TracesExporter {
 	factory := NewFactory()
 	cfg := createExporterConfig(baseURL, factory.CreateDefaultConfig())
@@ -372,6 +463,27 @@ func startAndCleanup(t *testing.T, cmp component.Component) {
 	})
 }
 
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "30", want: 30 * time.Second},
+		{value: "-30", want: 0},
+		{value: "Tue, 01 Mar 2022 12:02:00 GMT", want: 2 * time.Minute},
+		{value: "Tuesday, 01-Mar-22 12:00:10 GMT", want: 10 * time.Second},
+		{value: "Tue, 01 Mar 2022 11:00:00 GMT", want: 0},
+		{value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
 func TestErrorResponses(t *testing.T) {
 	addr := testutil.GetAvailableLocalAddress(t)
 	errMsgPrefix := fmt.Sprintf("error exporting items, request to http://%s/v1/traces responded with HTTP Status Code ", addr)
@@ -400,7 +512,7 @@ func TestErrorResponses(t *testing.T) {
 			responseStatus: http.StatusTooManyRequests,
 			responseBody:   status.New(codes.InvalidArgument, "Quota exceeded"),
 			err: exporterhelper.NewThrottleRetry(
-				fmt.Errorf(errMsgPrefix+"429, Message=Quota exceeded, Details=[]"),
+				exporterhelper.NewClassifiedRetry(fmt.Errorf(errMsgPrefix+"429, Message=Quota exceeded, Details=[]"), exporterhelper.RetryClassThrottled),
 				time.Duration(0)*time.Second),
 		},
 		{
@@ -408,7 +520,16 @@ func TestErrorResponses(t *testing.T) {
 			responseStatus: http.StatusServiceUnavailable,
 			responseBody:   status.New(codes.InvalidArgument, "Server overloaded"),
 			err: exporterhelper.NewThrottleRetry(
-				fmt.Errorf(errMsgPrefix+"503, Message=Server overloaded, Details=[]"),
+				exporterhelper.NewClassifiedRetry(fmt.Errorf(errMsgPrefix+"503, Message=Server overloaded, Details=[]"), exporterhelper.RetryClassUnavailable),
+				time.Duration(0)*time.Second),
+		},
+		{
+			name:           "429-Retry-After-Date",
+			responseStatus: http.StatusTooManyRequests,
+			responseBody:   status.New(codes.InvalidArgument, "Quota exceeded"),
+			headers:        map[string]string{"Retry-After": "Fri, 31 Dec 1999 23:59:59 GMT"},
+			err: exporterhelper.NewThrottleRetry(
+				exporterhelper.NewClassifiedRetry(fmt.Errorf(errMsgPrefix+"429, Message=Quota exceeded, Details=[]"), exporterhelper.RetryClassThrottled),
 				time.Duration(0)*time.Second),
 		},
 		{
@@ -417,7 +538,7 @@ func TestErrorResponses(t *testing.T) {
 			responseBody:   status.New(codes.InvalidArgument, "Server overloaded"),
 			headers:        map[string]string{"Retry-After": "30"},
 			err: exporterhelper.NewThrottleRetry(
-				fmt.Errorf(errMsgPrefix+"503, Message=Server overloaded, Details=[]"),
+				exporterhelper.NewClassifiedRetry(fmt.Errorf(errMsgPrefix+"503, Message=Server overloaded, Details=[]"), exporterhelper.RetryClassUnavailable),
 				time.Duration(30)*time.Second),
 		},
 	}
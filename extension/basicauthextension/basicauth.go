@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauthextension // import "go.opentelemetry.io/collector/extension/basicauthextension"
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/internal/authdata"
+)
+
+const basicSchemePrefix = "Basic "
+
+var (
+	errNoAuthHeader       = errors.New("no authorization header provided")
+	errInvalidScheme      = errors.New("invalid authorization scheme")
+	errInvalidFormat      = errors.New("invalid authorization format")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+var _ configauth.ServerAuthenticator = (*basicAuth)(nil)
+
+// basicAuth authenticates the requests using the HTTP Basic authentication scheme, see
+// https://datatracker.ietf.org/doc/html/rfc7617, with the credentials of an htpasswd file.
+type basicAuth struct {
+	cfg *Config
+
+	users  map[string]passwordMatcher
+	groups map[string][]string
+	// unknownUser is the matcher checking the passwords of the unknown users, so the time taken to reject a
+	// request does not tell whether its user exists.
+	unknownUser passwordMatcher
+
+	// verified keeps the SHA256 sum of the last password verified for each user, so the clients are not
+	// slowed down by the verification of the bcrypt hashes on each request.
+	mu       sync.RWMutex
+	verified map[string][sha256.Size]byte
+}
+
+func newBasicAuth(cfg *Config) *basicAuth {
+	return &basicAuth{
+		cfg:      cfg,
+		verified: map[string][sha256.Size]byte{},
+	}
+}
+
+// Start reads the credentials and groups of the clients.
+func (b *basicAuth) Start(context.Context, component.Host) error {
+	htpasswd, err := readContent(b.cfg.Htpasswd.File, b.cfg.Htpasswd.Inline)
+	if err != nil {
+		return fmt.Errorf("failed to read the htpasswd file: %w", err)
+	}
+	if b.users, b.unknownUser, err = parseHtpasswd(htpasswd); err != nil {
+		return err
+	}
+
+	htgroup, err := readContent(b.cfg.Htgroup.File, b.cfg.Htgroup.Inline)
+	if err != nil {
+		return fmt.Errorf("failed to read the htgroup file: %w", err)
+	}
+	b.groups, err = parseHtgroup(htgroup)
+	return err
+}
+
+// Shutdown does nothing, the extension has no resources to release.
+func (b *basicAuth) Shutdown(context.Context) error {
+	return nil
+}
+
+// Authenticate checks the credentials of the authorization header, and authenticates the client as the user
+// member of its groups.
+func (b *basicAuth) Authenticate(ctx context.Context, headers map[string][]string) (context.Context, error) {
+	auth := authdata.AuthorizationHeader(headers)
+	if auth == "" {
+		return ctx, errNoAuthHeader
+	}
+	// The scheme is case-insensitive, see https://datatracker.ietf.org/doc/html/rfc7235#section-2.1
+	if len(auth) < len(basicSchemePrefix) || !strings.EqualFold(auth[:len(basicSchemePrefix)], basicSchemePrefix) {
+		return ctx, errInvalidScheme
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(auth[len(basicSchemePrefix):]))
+	if err != nil {
+		return ctx, errInvalidFormat
+	}
+	i := strings.Index(string(decoded), ":")
+	if i < 0 {
+		return ctx, errInvalidFormat
+	}
+	user, password := string(decoded[:i]), string(decoded[i+1:])
+
+	if !b.verify(user, password) {
+		return ctx, errInvalidCredentials
+	}
+	return authdata.NewContext(ctx, user, b.groups[user]), nil
+}
+
+func (b *basicAuth) verify(user, password string) bool {
+	matcher, ok := b.users[user]
+	if !ok {
+		if b.unknownUser != nil {
+			b.unknownUser(password)
+		}
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(password))
+	b.mu.RLock()
+	verified, ok := b.verified[user]
+	b.mu.RUnlock()
+	if ok && subtle.ConstantTimeCompare(sum[:], verified[:]) == 1 {
+		return true
+	}
+
+	if !matcher(password) {
+		return false
+	}
+	b.mu.Lock()
+	b.verified[user] = sum
+	b.mu.Unlock()
+	return true
+}
+
+// readContent returns the content of the file followed by the inline content.
+func readContent(filename, inline string) (string, error) {
+	if filename == "" {
+		return inline, nil
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n" + inline, nil
+}
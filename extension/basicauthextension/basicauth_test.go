@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauthextension
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func basicAuthHeader(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+func TestBasicAuth_Authenticate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Htpasswd.File = filepath.Join("testdata", "htpasswd")
+	cfg.Htpasswd.Inline = "carol:{SHA}rHQB4A1GnNjWGyNqibGx/Y46GMs="
+	cfg.Htgroup.File = filepath.Join("testdata", "htgroup")
+	ba := newBasicAuth(cfg)
+	require.NoError(t, ba.Start(context.Background(), componenttest.NewNopHost()))
+
+	for _, tt := range []struct {
+		name      string
+		headers   map[string][]string
+		principal string
+		groups    []string
+		err       error
+	}{
+		{
+			name:      "http",
+			headers:   map[string][]string{"Authorization": {basicAuthHeader("alice", "alicepassword")}},
+			principal: "alice",
+			groups:    []string{"admins", "devs"},
+		},
+		{
+			name:      "grpc",
+			headers:   map[string][]string{"authorization": {basicAuthHeader("bob", "bobpassword")}},
+			principal: "bob",
+			groups:    []string{"devs"},
+		},
+		{
+			name:      "inline",
+			headers:   map[string][]string{"authorization": {basicAuthHeader("carol", "carolpassword")}},
+			principal: "carol",
+			groups:    []string{},
+		},
+		{
+			name:      "case_insensitive_scheme",
+			headers:   map[string][]string{"authorization": {"basic " + base64.StdEncoding.EncodeToString([]byte("bob:bobpassword"))}},
+			principal: "bob",
+			groups:    []string{"devs"},
+		},
+		{
+			name:    "missing_header",
+			headers: map[string][]string{},
+			err:     errNoAuthHeader,
+		},
+		{
+			name:    "wrong_scheme",
+			headers: map[string][]string{"authorization": {"Bearer token"}},
+			err:     errInvalidScheme,
+		},
+		{
+			name:    "invalid_base64",
+			headers: map[string][]string{"authorization": {"Basic !!!"}},
+			err:     errInvalidFormat,
+		},
+		{
+			name:    "no_password",
+			headers: map[string][]string{"authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte("alice"))}},
+			err:     errInvalidFormat,
+		},
+		{
+			name:    "wrong_password",
+			headers: map[string][]string{"authorization": {basicAuthHeader("alice", "wrong")}},
+			err:     errInvalidCredentials,
+		},
+		{
+			name:    "unknown_user",
+			headers: map[string][]string{"authorization": {basicAuthHeader("mallory", "alicepassword")}},
+			err:     errInvalidCredentials,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// The second attempt uses the verified credentials.
+			for i := 0; i < 2; i++ {
+				ctx, err := ba.Authenticate(context.Background(), tt.headers)
+				if tt.err != nil {
+					assert.Equal(t, tt.err, err)
+					assert.Nil(t, client.FromContext(ctx).Auth)
+					continue
+				}
+				require.NoError(t, err)
+				auth := client.FromContext(ctx).Auth
+				require.NotNil(t, auth)
+				assert.Equal(t, tt.principal, auth.GetAttribute("principal"))
+				assert.Equal(t, tt.groups, auth.GetAttribute("groups"))
+			}
+		})
+	}
+}
+
+func TestBasicAuth_VerifiedPasswordChange(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Htpasswd.File = filepath.Join("testdata", "htpasswd")
+	ba := newBasicAuth(cfg)
+	require.NoError(t, ba.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.True(t, ba.verify("alice", "alicepassword"))
+	// Only the verified password is accepted without checking the hash.
+	assert.False(t, ba.verify("alice", "wrong"))
+	assert.True(t, ba.verify("alice", "alicepassword"))
+}
+
+func TestBasicAuth_UnknownUser(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Htpasswd.File = filepath.Join("testdata", "htpasswd")
+	ba := newBasicAuth(cfg)
+	require.NoError(t, ba.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The password of an unknown user is checked anyway, but never accepted.
+	var checked []string
+	ba.unknownUser = func(password string) bool {
+		checked = append(checked, password)
+		return true
+	}
+	assert.False(t, ba.verify("mallory", "alicepassword"))
+	assert.Equal(t, []string{"alicepassword"}, checked)
+}
+
+func TestBasicAuth_StartErrors(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Htpasswd.File = filepath.Join("testdata", "missing")
+	assert.Error(t, newBasicAuth(cfg).Start(context.Background(), componenttest.NewNopHost()))
+
+	cfg.Htpasswd.File = filepath.Join("testdata", "htpasswd")
+	cfg.Htgroup.File = filepath.Join("testdata", "missing")
+	assert.Error(t, newBasicAuth(cfg).Start(context.Background(), componenttest.NewNopHost()))
+
+	cfg.Htpasswd.File = ""
+	cfg.Htpasswd.Inline = "alice:plaintext"
+	cfg.Htgroup.File = ""
+	assert.Error(t, newBasicAuth(cfg).Start(context.Background(), componenttest.NewNopHost()))
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauthextension // import "go.opentelemetry.io/collector/extension/basicauthextension"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+var errNoCredentialSource = errors.New("no htpasswd file or inline content provided")
+
+// HtpasswdSettings specifies the credentials of the clients, in the htpasswd format.
+type HtpasswdSettings struct {
+	// File is the path of the htpasswd file.
+	File string `mapstructure:"file"`
+
+	// Inline is the content of an htpasswd file, in addition to the content of File.
+	Inline string `mapstructure:"inline"`
+}
+
+// HtgroupSettings specifies the groups of the clients, in the htgroup format.
+type HtgroupSettings struct {
+	// File is the path of the htgroup file.
+	File string `mapstructure:"file"`
+
+	// Inline is the content of an htgroup file, in addition to the content of File.
+	Inline string `mapstructure:"inline"`
+}
+
+// Config specifies the credentials and groups of the clients.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Htpasswd specifies the credentials of the clients.
+	Htpasswd HtpasswdSettings `mapstructure:"htpasswd"`
+
+	// Htgroup optionally specifies the groups of the clients.
+	Htgroup HtgroupSettings `mapstructure:"htgroup"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Htpasswd.File == "" && cfg.Htpasswd.Inline == "" {
+		return errNoCredentialSource
+	}
+	return nil
+}
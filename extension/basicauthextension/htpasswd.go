@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauthextension // import "go.opentelemetry.io/collector/extension/basicauthextension"
+
+import (
+	"bufio"
+	"crypto/md5"  //nolint:gosec // required by the apr1 hashes of the htpasswd files
+	"crypto/sha1" //nolint:gosec // required by the SHA hashes of the htpasswd files
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	apr1Prefix = "$apr1$"
+	shaPrefix  = "{SHA}"
+)
+
+// passwordMatcher checks a password against the hash read from an htpasswd file.
+type passwordMatcher func(password string) bool
+
+// parseHtpasswd parses the content of an htpasswd file, returning the password matcher of each user, and the
+// slowest of these matchers, which is run for the unknown users so they take as long to reject as the known users.
+// The bcrypt, apr1 (MD5) and SHA1 hashes created by htpasswd are supported. The crypt(3) hashes and
+// the passwords stored in plain text are rejected.
+func parseHtpasswd(content string) (map[string]passwordMatcher, passwordMatcher, error) {
+	users := map[string]passwordMatcher{}
+	var slowest passwordMatcher
+	slowestCost := -1
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		i := strings.Index(text, ":")
+		if i <= 0 {
+			return nil, nil, fmt.Errorf("invalid htpasswd entry on line %d, expecting \"user:hash\"", line)
+		}
+		user, hash := text[:i], text[i+1:]
+		matcher, cost, err := newPasswordMatcher(hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid htpasswd entry for user %q: %w", user, err)
+		}
+		users[user] = matcher
+		if cost > slowestCost {
+			slowest, slowestCost = matcher, cost
+		}
+	}
+	return users, slowest, scanner.Err()
+}
+
+// newPasswordMatcher returns the matcher of the hash, and its relative cost: the SHA hashes are the fastest to
+// check, then the apr1 hashes, then the bcrypt hashes by increasing bcrypt cost.
+func newPasswordMatcher(hash string) (passwordMatcher, int, error) {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		// Fails on malformed hashes instead of rejecting all the passwords.
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return nil, 0, err
+		}
+		return func(password string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+		}, 2 + cost, nil
+	case strings.HasPrefix(hash, apr1Prefix):
+		salt := strings.TrimPrefix(hash, apr1Prefix)
+		i := strings.Index(salt, "$")
+		if i < 0 {
+			return nil, 0, fmt.Errorf("malformed apr1 hash")
+		}
+		salt = salt[:i]
+		return func(password string) bool {
+			return subtle.ConstantTimeCompare([]byte(apr1(password, salt)), []byte(hash)) == 1
+		}, 1, nil
+	case strings.HasPrefix(hash, shaPrefix):
+		expected, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(hash, shaPrefix))
+		if err != nil || len(expected) != sha1.Size {
+			return nil, 0, fmt.Errorf("malformed SHA hash")
+		}
+		return func(password string) bool {
+			sum := sha1.Sum([]byte(password))
+			return subtle.ConstantTimeCompare(sum[:], expected) == 1
+		}, 0, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported hash, only the bcrypt, apr1 and SHA hashes are supported")
+	}
+}
+
+// apr1 returns the hash of the password with the Apache variant of the MD5-based crypt algorithm,
+// see https://httpd.apache.org/docs/2.4/misc/password_encryptions.html.
+func apr1(password, salt string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw := []byte(password)
+
+	alternate := md5.New()
+	alternate.Write(pw)
+	alternate.Write([]byte(salt))
+	alternate.Write(pw)
+	alt := alternate.Sum(nil)
+
+	h := md5.New()
+	h.Write(pw)
+	h.Write([]byte(apr1Prefix))
+	h.Write([]byte(salt))
+	for i := len(pw); i > 0; i -= md5.Size {
+		if i > md5.Size {
+			h.Write(alt)
+		} else {
+			h.Write(alt[:i])
+		}
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 == 1 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	sum := h.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		h := md5.New()
+		if i&1 == 1 {
+			h.Write(pw)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 == 1 {
+			h.Write(sum)
+		} else {
+			h.Write(pw)
+		}
+		sum = h.Sum(nil)
+	}
+
+	const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	var out strings.Builder
+	encode := func(v uint32, n int) {
+		for ; n > 0; n-- {
+			out.WriteByte(itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	encode(uint32(sum[0])<<16|uint32(sum[6])<<8|uint32(sum[12]), 4)
+	encode(uint32(sum[1])<<16|uint32(sum[7])<<8|uint32(sum[13]), 4)
+	encode(uint32(sum[2])<<16|uint32(sum[8])<<8|uint32(sum[14]), 4)
+	encode(uint32(sum[3])<<16|uint32(sum[9])<<8|uint32(sum[15]), 4)
+	encode(uint32(sum[4])<<16|uint32(sum[10])<<8|uint32(sum[5]), 4)
+	encode(uint32(sum[11]), 2)
+
+	return apr1Prefix + salt + "$" + out.String()
+}
+
+// parseHtgroup parses the content of an htgroup file, returning the groups of each user in the order of the file.
+func parseHtgroup(content string) (map[string][]string, error) {
+	groups := map[string][]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		i := strings.Index(text, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid htgroup entry on line %d, expecting \"group: user1 user2\"", line)
+		}
+		group := strings.TrimSpace(text[:i])
+		for _, user := range strings.Fields(text[i+1:]) {
+			groups[user] = append(groups[user], group)
+		}
+	}
+	return groups, scanner.Err()
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauthextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApr1(t *testing.T) {
+	// Created with "openssl passwd -apr1".
+	assert.Equal(t, "$apr1$Nj7Rs9wM$NKk4aDzXUoNEMF7YJ9SKU.", apr1("bobpassword", "Nj7Rs9wM"))
+	assert.Equal(t, "$apr1$r31xyz12$onQKKDsjYs7dR4muzW5nu0", apr1("secret", "r31xyz12"))
+}
+
+func TestParseHtpasswd(t *testing.T) {
+	users, unknownUser, err := parseHtpasswd(`
+# comment
+alice:$2y$05$RZX88O88jIIAOxpvJ4su2.AXNk3/BAHlNgtI74kFqec5STWPGdIc6
+bob:$apr1$Nj7Rs9wM$NKk4aDzXUoNEMF7YJ9SKU.
+carol:{SHA}rHQB4A1GnNjWGyNqibGx/Y46GMs=
+`)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	for _, user := range []string{"alice", "bob", "carol"} {
+		assert.True(t, users[user](user+"password"), user)
+		assert.False(t, users[user]("wrong"), user)
+	}
+	// The unknown users are checked with the slowest hash, the bcrypt hash of alice.
+	assert.True(t, unknownUser("alicepassword"))
+
+	_, unknownUser, err = parseHtpasswd(`
+alice:$2y$05$RZX88O88jIIAOxpvJ4su2.AXNk3/BAHlNgtI74kFqec5STWPGdIc6
+dave:$2y$06$RZX88O88jIIAOxpvJ4su2.AXNk3/BAHlNgtI74kFqec5STWPGdIc6
+bob:$apr1$Nj7Rs9wM$NKk4aDzXUoNEMF7YJ9SKU.
+`)
+	require.NoError(t, err)
+	assert.False(t, unknownUser("alicepassword"), "the hash with the highest bcrypt cost is used")
+}
+
+func TestParseHtpasswdErrors(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		err     string
+	}{
+		{content: "alice", err: `invalid htpasswd entry on line 1, expecting "user:hash"`},
+		{content: "alice:plaintext", err: `invalid htpasswd entry for user "alice": unsupported hash, only the bcrypt, apr1 and SHA hashes are supported`},
+		{content: "alice:$2y$05$short", err: `invalid htpasswd entry for user "alice": crypto/bcrypt: hashedSecret too short to be a bcrypted password`},
+		{content: "alice:$apr1$nosalt", err: `invalid htpasswd entry for user "alice": malformed apr1 hash`},
+		{content: "alice:{SHA}short", err: `invalid htpasswd entry for user "alice": malformed SHA hash`},
+	} {
+		_, _, err := parseHtpasswd(tt.content)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestParseHtgroup(t *testing.T) {
+	groups, err := parseHtgroup("# comment\nadmins: alice\ndevs:alice  bob\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"alice": {"admins", "devs"},
+		"bob":   {"devs"},
+	}, groups)
+
+	_, err = parseHtgroup("admins alice")
+	assert.EqualError(t, err, `invalid htgroup entry on line 1, expecting "group: user1 user2"`)
+}
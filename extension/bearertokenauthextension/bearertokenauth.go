@@ -0,0 +1,229 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bearertokenauthextension // import "go.opentelemetry.io/collector/extension/bearertokenauthextension"
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/internal/authdata"
+)
+
+var (
+	errNoAuthHeader  = errors.New("no authorization header provided")
+	errInvalidScheme = errors.New("invalid authorization scheme")
+	errInvalidToken  = errors.New("invalid bearer token")
+)
+
+var (
+	_ configauth.ClientAuthenticator = (*bearerTokenAuth)(nil)
+	_ configauth.ServerAuthenticator = (*bearerTokenAuth)(nil)
+)
+
+// bearerTokenAuth adds the bearer token to the HTTP requests and gRPC calls of the exporters, and authenticates
+// the requests received with the bearer token. The token read from a file is read again when the file changes,
+// so the rotated tokens are used without restarting the collector.
+type bearerTokenAuth struct {
+	cfg    *Config
+	logger *zap.Logger
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.RWMutex
+	token string
+	// modTime and size identify the version of the file the token was read from.
+	modTime time.Time
+	size    int64
+}
+
+func newBearerTokenAuth(cfg *Config, logger *zap.Logger) *bearerTokenAuth {
+	return &bearerTokenAuth{
+		cfg:    cfg,
+		logger: logger,
+		token:  cfg.BearerToken,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start reads the token file and starts watching it for changes.
+func (b *bearerTokenAuth) Start(context.Context, component.Host) error {
+	if b.cfg.Filename == "" {
+		return nil
+	}
+	if _, err := b.reload(); err != nil {
+		return err
+	}
+	if b.cfg.ReloadInterval > 0 {
+		b.wg.Add(1)
+		go b.watch()
+	}
+	return nil
+}
+
+// Shutdown stops watching the token file.
+func (b *bearerTokenAuth) Shutdown(context.Context) error {
+	close(b.done)
+	b.wg.Wait()
+	return nil
+}
+
+func (b *bearerTokenAuth) watch() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := b.reload()
+			if err != nil {
+				// Keep using the last token, the file may be in the middle of being replaced.
+				b.logger.Warn("Failed to read the bearer token file", zap.String("filename", b.cfg.Filename), zap.Error(err))
+			} else if reloaded {
+				b.logger.Info("Bearer token file changed, using the new token", zap.String("filename", b.cfg.Filename))
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// reload reads the token from the file if it changed since it was last read, and returns true if it did.
+func (b *bearerTokenAuth) reload() (bool, error) {
+	info, err := os.Stat(b.cfg.Filename)
+	if err != nil {
+		return false, err
+	}
+	b.mu.RLock()
+	unchanged := info.ModTime().Equal(b.modTime) && info.Size() == b.size
+	b.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	content, err := ioutil.ReadFile(b.cfg.Filename)
+	if err != nil {
+		return false, err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return false, fmt.Errorf("bearer token file %s is empty", b.cfg.Filename)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.token = token
+	b.modTime = info.ModTime()
+	b.size = info.Size()
+	return true, nil
+}
+
+// authorization returns the value of the Authorization header carrying the token.
+func (b *bearerTokenAuth) authorization() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.cfg.Scheme == "" {
+		return b.token
+	}
+	return b.cfg.Scheme + " " + b.token
+}
+
+// RoundTripper returns a http.RoundTripper adding the token to the HTTP requests.
+func (b *bearerTokenAuth) RoundTripper(base http.RoundTripper) (http.RoundTripper, error) {
+	return &roundTripper{base: base, auth: b}, nil
+}
+
+// PerRPCCredentials returns the credentials adding the token to the gRPC calls.
+func (b *bearerTokenAuth) PerRPCCredentials() (credentials.PerRPCCredentials, error) {
+	return &perRPCCredentials{auth: b}, nil
+}
+
+// Authenticate checks that the authorization header carries the token, and authenticates the client as the
+// configured principal.
+func (b *bearerTokenAuth) Authenticate(ctx context.Context, headers map[string][]string) (context.Context, error) {
+	auth := authdata.AuthorizationHeader(headers)
+	if auth == "" {
+		return ctx, errNoAuthHeader
+	}
+
+	b.mu.RLock()
+	token := b.token
+	b.mu.RUnlock()
+
+	if b.cfg.Scheme != "" {
+		scheme, value, found := cut(auth, " ")
+		// The scheme is case-insensitive, see https://datatracker.ietf.org/doc/html/rfc7235#section-2.1
+		if !found || !strings.EqualFold(scheme, b.cfg.Scheme) {
+			return ctx, errInvalidScheme
+		}
+		auth = strings.TrimLeft(value, " ")
+	}
+	if subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+		return ctx, errInvalidToken
+	}
+	return authdata.NewContext(ctx, b.cfg.Principal, b.cfg.Groups), nil
+}
+
+// cut slices s around the first instance of sep, like strings.Cut available from Go 1.18.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// roundTripper adds the bearer token to the HTTP requests.
+type roundTripper struct {
+	base http.RoundTripper
+	auth *bearerTokenAuth
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request.
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", rt.auth.authorization())
+	return rt.base.RoundTrip(authReq)
+}
+
+// perRPCCredentials adds the bearer token to the gRPC calls.
+type perRPCCredentials struct {
+	auth *bearerTokenAuth
+}
+
+var _ credentials.PerRPCCredentials = (*perRPCCredentials)(nil)
+
+// GetRequestMetadata implements the credentials.PerRPCCredentials interface
+func (c *perRPCCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": c.auth.authorization()}, nil
+}
+
+// RequireTransportSecurity implements the credentials.PerRPCCredentials interface.
+// The tokens must not be sent in clear text.
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return true
+}
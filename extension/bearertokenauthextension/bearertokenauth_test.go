@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bearertokenauthextension
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestBearerTokenAuth_PerRPCCredentials(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.BearerToken = "sometoken"
+	bta := newBearerTokenAuth(cfg, zap.NewNop())
+	require.NoError(t, bta.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, bta.Shutdown(context.Background()))
+	}()
+
+	creds, err := bta.PerRPCCredentials()
+	require.NoError(t, err)
+	assert.True(t, creds.RequireTransportSecurity())
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer sometoken"}, md)
+
+	cfg.Scheme = ""
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "sometoken"}, md)
+}
+
+func TestBearerTokenAuth_RoundTripper(t *testing.T) {
+	var authorization string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer backend.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Scheme = "MyScheme"
+	cfg.BearerToken = "sometoken"
+	bta := newBearerTokenAuth(cfg, zap.NewNop())
+	rt, err := bta.RoundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, backend.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "MyScheme sometoken", authorization)
+	// The request of the caller is not modified.
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestBearerTokenAuth_File(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("token-1\n"), 0600))
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Filename = filename
+	cfg.ReloadInterval = 10 * time.Millisecond
+	bta := newBearerTokenAuth(cfg, zap.NewNop())
+	require.NoError(t, bta.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, bta.Shutdown(context.Background()))
+	}()
+	assert.Equal(t, "Bearer token-1", bta.authorization())
+
+	// The rotated token is used once the file is read again.
+	require.NoError(t, ioutil.WriteFile(filename, []byte("token-22\n"), 0600))
+	assert.Eventually(t, func() bool {
+		return bta.authorization() == "Bearer token-22"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The last token is kept while the file cannot be read.
+	require.NoError(t, ioutil.WriteFile(filename, nil, 0600))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "Bearer token-22", bta.authorization())
+}
+
+func TestBearerTokenAuth_FileError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Filename = filepath.Join(t.TempDir(), "missing")
+	bta := newBearerTokenAuth(cfg, zap.NewNop())
+	assert.Error(t, bta.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, ioutil.WriteFile(cfg.Filename, []byte(" \n"), 0600))
+	assert.EqualError(t, bta.Start(context.Background(), componenttest.NewNopHost()), "bearer token file "+cfg.Filename+" is empty")
+}
+
+func TestBearerTokenAuth_Authenticate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.BearerToken = "sometoken"
+	cfg.Principal = "agent"
+	cfg.Groups = []string{"agents"}
+	bta := newBearerTokenAuth(cfg, zap.NewNop())
+
+	for _, tt := range []struct {
+		name    string
+		headers map[string][]string
+		err     error
+	}{
+		{name: "http", headers: map[string][]string{"Authorization": {"Bearer sometoken"}}},
+		{name: "grpc", headers: map[string][]string{"authorization": {"Bearer sometoken"}}},
+		{name: "case_insensitive_scheme", headers: map[string][]string{"authorization": {"bearer sometoken"}}},
+		{name: "missing_header", headers: map[string][]string{}, err: errNoAuthHeader},
+		{name: "wrong_scheme", headers: map[string][]string{"authorization": {"Basic sometoken"}}, err: errInvalidScheme},
+		{name: "no_scheme", headers: map[string][]string{"authorization": {"sometoken"}}, err: errInvalidScheme},
+		{name: "wrong_token", headers: map[string][]string{"authorization": {"Bearer othertoken"}}, err: errInvalidToken},
+		{name: "token_prefix", headers: map[string][]string{"authorization": {"Bearer some"}}, err: errInvalidToken},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := bta.Authenticate(context.Background(), tt.headers)
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+				assert.Nil(t, client.FromContext(ctx).Auth)
+				return
+			}
+			require.NoError(t, err)
+			auth := client.FromContext(ctx).Auth
+			require.NotNil(t, auth)
+			assert.Equal(t, "agent", auth.GetAttribute("principal"))
+			assert.Equal(t, []string{"agents"}, auth.GetAttribute("groups"))
+		})
+	}
+}
+
+func TestBearerTokenAuth_AuthenticateWithoutScheme(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Scheme = ""
+	cfg.BearerToken = "sometoken"
+	bta := newBearerTokenAuth(cfg, zap.NewNop())
+
+	_, err := bta.Authenticate(context.Background(), map[string][]string{"authorization": {"sometoken"}})
+	assert.NoError(t, err)
+	_, err = bta.Authenticate(context.Background(), map[string][]string{"authorization": {"Bearer sometoken"}})
+	assert.Equal(t, errInvalidToken, err)
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bearertokenauthextension // import "go.opentelemetry.io/collector/extension/bearertokenauthextension"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+var (
+	errNoTokenProvided        = errors.New("either token or filename must be provided")
+	errTokenAndFilename       = errors.New("token and filename cannot be both provided")
+	errNegativeReloadInterval = errors.New("reload_interval must not be negative")
+)
+
+// Config specifies how the bearer token is obtained.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Scheme is the authentication scheme prefixing the token in the Authorization header.
+	Scheme string `mapstructure:"scheme,omitempty"`
+
+	// BearerToken is the static token.
+	BearerToken string `mapstructure:"token,omitempty"`
+
+	// Filename is the path of a file containing the token, like a Kubernetes service account token.
+	Filename string `mapstructure:"filename,omitempty"`
+
+	// Principal is the principal of the clients authenticated with the token by the receivers.
+	Principal string `mapstructure:"principal,omitempty"`
+
+	// Groups are the groups of the clients authenticated with the token by the receivers.
+	Groups []string `mapstructure:"groups,omitempty"`
+
+	// ReloadInterval is the interval at which the file is checked for changes, and read again when it changed.
+	// If not set, the file is only read on start.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.BearerToken == "" && cfg.Filename == "" {
+		return errNoTokenProvided
+	}
+	if cfg.BearerToken != "" && cfg.Filename != "" {
+		return errTokenAndFilename
+	}
+	if cfg.ReloadInterval < 0 {
+		return errNegativeReloadInterval
+	}
+	return nil
+}
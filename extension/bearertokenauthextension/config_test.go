@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bearertokenauthextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	ext0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+			Scheme:            "Bearer",
+			BearerToken:       "sometoken",
+			ReloadInterval:    10 * time.Second,
+		},
+		ext0)
+
+	ext1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "file")]
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "file")),
+			Scheme:            "MyScheme",
+			Filename:          "/var/run/secrets/token",
+			Principal:         "agent",
+			Groups:            []string{"agents"},
+			ReloadInterval:    time.Minute,
+		},
+		ext1)
+
+	assert.Equal(t, 1, len(cfg.Service.Extensions))
+	assert.Equal(t, config.NewComponentID(typeStr), cfg.Service.Extensions[0])
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	_, err = servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config_invalid.yaml"), factories)
+
+	require.Error(t, err)
+	assert.Equal(t, `extension "bearertokenauth" has invalid configuration: token and filename cannot be both provided`, err.Error())
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, errNoTokenProvided, cfg.Validate())
+
+	cfg.Filename = "token"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.ReloadInterval = -time.Second
+	assert.Equal(t, errNegativeReloadInterval, cfg.Validate())
+}
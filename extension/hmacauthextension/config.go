@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hmacauthextension // import "go.opentelemetry.io/collector/extension/hmacauthextension"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+var (
+	errNoKeyID  = errors.New("key_id must be provided")
+	errNoSecret = errors.New("secret must be provided")
+)
+
+// Config specifies the key the requests are signed with.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// KeyID identifies the secret to the backend, it is sent with the signature.
+	KeyID string `mapstructure:"key_id"`
+
+	// Secret is the secret shared with the backend the signatures are computed with.
+	Secret string `mapstructure:"secret"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.KeyID == "" {
+		return errNoKeyID
+	}
+	if cfg.Secret == "" {
+		return errNoSecret
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hmacauthextension // import "go.opentelemetry.io/collector/extension/hmacauthextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// scheme is the authentication scheme of the Authorization header carrying the signature.
+const scheme = "HMAC-SHA256"
+
+// hmacAuth signs the HTTP requests and gRPC calls of the exporters with an HMAC-SHA256 of the method, the path,
+// the host, the time and the body of the request, keyed with the shared secret.
+type hmacAuth struct {
+	keyID  string
+	secret []byte
+	// now returns the time the requests are signed at, it is replaced by the tests.
+	now func() time.Time
+}
+
+func newHMACAuth(cfg *Config) *hmacAuth {
+	return &hmacAuth{
+		keyID:  cfg.KeyID,
+		secret: []byte(cfg.Secret),
+		now:    time.Now,
+	}
+}
+
+// authorization returns the value of the Authorization header carrying the signature of the request.
+func (h *hmacAuth) authorization(method, path, host string, body []byte) string {
+	timestamp := strconv.FormatInt(h.now().Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, h.secret)
+	// The host is case-insensitive, the other parts are signed as sent.
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", method, path, strings.ToLower(host), timestamp, hex.EncodeToString(bodyHash[:]))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return fmt.Sprintf("%s KeyId=%s, Timestamp=%s, Signature=%s", scheme, h.keyID, timestamp, signature)
+}
+
+// roundTripper returns a http.RoundTripper signing the HTTP requests.
+func (h *hmacAuth) roundTripper(base http.RoundTripper) (http.RoundTripper, error) {
+	return &signingRoundTripper{base: base, auth: h}, nil
+}
+
+// perRPCCredentials returns the credentials signing the gRPC calls.
+func (h *hmacAuth) perRPCCredentials() (credentials.PerRPCCredentials, error) {
+	return &signingCredentials{auth: h}, nil
+}
+
+// signingRoundTripper signs the HTTP requests.
+type signingRoundTripper struct {
+	base http.RoundTripper
+	auth *hmacAuth
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt *signingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request.
+	authReq := req.Clone(req.Context())
+	body, err := readBody(authReq)
+	if err != nil {
+		return nil, err
+	}
+	host := authReq.Host
+	if host == "" {
+		host = authReq.URL.Host
+	}
+	authReq.Header.Set("Authorization", rt.auth.authorization(authReq.Method, authReq.URL.RequestURI(), host, body))
+	return rt.base.RoundTrip(authReq)
+}
+
+// readBody returns the body of the request to sign, and replaces the body of the request with an unread copy.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	var body io.ReadCloser = req.Body
+	if req.GetBody != nil {
+		// Read a copy of the body, so the body of the request of the caller is left unread.
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	content, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
+
+// signingCredentials signs the gRPC calls.
+type signingCredentials struct {
+	auth *hmacAuth
+}
+
+var _ credentials.PerRPCCredentials = (*signingCredentials)(nil)
+
+// GetRequestMetadata implements the credentials.PerRPCCredentials interface.
+// The messages are not available to the credentials, so the signature of a gRPC call only covers the method,
+// the host and the time of the call.
+func (c *signingCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	ri, ok := credentials.RequestInfoFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("no request info in the context of the gRPC call")
+	}
+	var host string
+	if len(uri) > 0 {
+		u, err := url.Parse(uri[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid gRPC call URI %q: %w", uri[0], err)
+		}
+		host = u.Host
+	}
+	return map[string]string{"authorization": c.auth.authorization(http.MethodPost, ri.Method, host, nil)}, nil
+}
+
+// RequireTransportSecurity implements the credentials.PerRPCCredentials interface.
+// The secret is never sent, so the signed calls can be sent in clear text.
+func (c *signingCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hmacauthextension
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// testTime is the time the requests of the tests are signed at.
+var testTime = time.Unix(1650000000, 0)
+
+func newTestHMACAuth() *hmacAuth {
+	h := newHMACAuth(&Config{KeyID: "agent", Secret: "secret"})
+	h.now = func() time.Time { return testTime }
+	return h
+}
+
+// expectedAuthorization computes the Authorization header of a request independently of the authenticator.
+func expectedAuthorization(method, path, host string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(method + "\n" + path + "\n" + host + "\n1650000000\n" + hex.EncodeToString(bodyHash[:])))
+	return "HMAC-SHA256 KeyId=agent, Timestamp=1650000000, Signature=" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestHMACAuth_RoundTripper(t *testing.T) {
+	var authorization string
+	var received []byte
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		received, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer backend.Close()
+
+	rt, err := newTestHMACAuth().roundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+	host := backend.Listener.Addr().String()
+
+	body := []byte("payload")
+	req, err := http.NewRequest(http.MethodPost, backend.URL+"/v1/traces?a=b", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, expectedAuthorization(http.MethodPost, "/v1/traces?a=b", host, body), authorization)
+	// The signed body is sent, and the request of the caller is not modified.
+	assert.Equal(t, body, received)
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// A body which cannot be read again is sent as well.
+	req, err = http.NewRequest(http.MethodPost, backend.URL+"/v1/logs", ioutil.NopCloser(bytes.NewReader(body)))
+	require.NoError(t, err)
+	resp, err = (&http.Client{Transport: rt}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, expectedAuthorization(http.MethodPost, "/v1/logs", host, body), authorization)
+	assert.Equal(t, body, received)
+
+	// A request without body is signed with the hash of an empty body.
+	req, err = http.NewRequest(http.MethodGet, backend.URL, nil)
+	require.NoError(t, err)
+	resp, err = (&http.Client{Transport: rt}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, expectedAuthorization(http.MethodGet, "/", host, nil), authorization)
+}
+
+func TestHMACAuth_PerRPCCredentials(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	var authorization []string
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		authorization = md.Get("authorization")
+		return handler(ctx, req)
+	}))
+	hs := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer srv.Stop()
+
+	creds, err := newTestHMACAuth().perRPCCredentials()
+	require.NoError(t, err)
+	assert.False(t, creds.RequireTransportSecurity())
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithPerRPCCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{expectedAuthorization(http.MethodPost, "/grpc.health.v1.Health/Check", ln.Addr().String(), nil)}, authorization)
+
+	// The credentials can only sign gRPC calls.
+	_, err = creds.GetRequestMetadata(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mtlsauthextension // import "go.opentelemetry.io/collector/extension/mtlsauthextension"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// PrincipalSource is the field of the client certificate used as principal.
+type PrincipalSource string
+
+const (
+	// PrincipalFromCommonName uses the common name of the subject as principal.
+	PrincipalFromCommonName PrincipalSource = "common_name"
+	// PrincipalFromSubject uses the distinguished name of the subject as principal, like "CN=agent,O=example".
+	PrincipalFromSubject PrincipalSource = "subject"
+	// PrincipalFromDNSSAN uses the first DNS name of the subject alternative names as principal.
+	PrincipalFromDNSSAN PrincipalSource = "dns_san"
+	// PrincipalFromURISAN uses the first URI of the subject alternative names as principal, like a SPIFFE ID.
+	PrincipalFromURISAN PrincipalSource = "uri_san"
+	// PrincipalFromEmailSAN uses the first email address of the subject alternative names as principal.
+	PrincipalFromEmailSAN PrincipalSource = "email_san"
+)
+
+// GroupsSource is the field of the client certificate used as groups.
+type GroupsSource string
+
+const (
+	// GroupsFromOrganization uses the organizations of the subject as groups.
+	GroupsFromOrganization GroupsSource = "organization"
+	// GroupsFromOrganizationalUnit uses the organizational units of the subject as groups.
+	GroupsFromOrganizationalUnit GroupsSource = "organizational_unit"
+	// GroupsFromNone does not set groups.
+	GroupsFromNone GroupsSource = "none"
+)
+
+// Config specifies how the clients are identified by their certificate.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// PrincipalFrom is the field of the certificate used as principal.
+	PrincipalFrom PrincipalSource `mapstructure:"principal_from"`
+
+	// GroupsFrom is the field of the certificate used as groups.
+	GroupsFrom GroupsSource `mapstructure:"groups_from"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.PrincipalFrom {
+	case PrincipalFromCommonName, PrincipalFromSubject, PrincipalFromDNSSAN, PrincipalFromURISAN, PrincipalFromEmailSAN:
+	default:
+		return fmt.Errorf("unknown principal_from %q", cfg.PrincipalFrom)
+	}
+	switch cfg.GroupsFrom {
+	case GroupsFromOrganization, GroupsFromOrganizationalUnit, GroupsFromNone:
+	default:
+		return fmt.Errorf("unknown groups_from %q", cfg.GroupsFrom)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mtlsauthextension // import "go.opentelemetry.io/collector/extension/mtlsauthextension"
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/internal/authdata"
+)
+
+var (
+	errNoTLS                 = errors.New("the request was not received over TLS")
+	errNoVerifiedCertificate = errors.New("no verified client certificate")
+)
+
+// mtlsAuth authenticates the clients by the certificate they presented during the TLS handshake. The certificate
+// must have been verified by the server, with the client_ca_file of its TLS settings.
+type mtlsAuth struct {
+	cfg *Config
+}
+
+func newMTLSAuth(cfg *Config) *mtlsAuth {
+	return &mtlsAuth{cfg: cfg}
+}
+
+func (m *mtlsAuth) authenticate(ctx context.Context, _ map[string][]string) (context.Context, error) {
+	state, ok := configauth.TLSConnectionStateFromContext(ctx)
+	if !ok {
+		return ctx, errNoTLS
+	}
+	// The certificates not verified by the server, when it does not require them, cannot be trusted.
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ctx, errNoVerifiedCertificate
+	}
+	cert := state.VerifiedChains[0][0]
+
+	principal := m.principal(cert)
+	if principal == "" {
+		return ctx, fmt.Errorf("the client certificate has no %s", m.cfg.PrincipalFrom)
+	}
+	return authdata.NewContext(ctx, principal, m.groups(cert)), nil
+}
+
+func (m *mtlsAuth) principal(cert *x509.Certificate) string {
+	switch m.cfg.PrincipalFrom {
+	case PrincipalFromSubject:
+		return cert.Subject.String()
+	case PrincipalFromDNSSAN:
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case PrincipalFromURISAN:
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	case PrincipalFromEmailSAN:
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+	default:
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+func (m *mtlsAuth) groups(cert *x509.Certificate) []string {
+	switch m.cfg.GroupsFrom {
+	case GroupsFromOrganization:
+		return cert.Subject.Organization
+	case GroupsFromOrganizationalUnit:
+		return cert.Subject.OrganizationalUnit
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mtlsauthextension
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/config/configauth"
+)
+
+func newTestCertificate() *x509.Certificate {
+	return &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "agent-1",
+			Organization:       []string{"agents"},
+			OrganizationalUnit: []string{"team-a", "team-b"},
+		},
+		DNSNames:       []string{"agent-1.example.com", "agent.example.com"},
+		URIs:           []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/agent-1"}},
+		EmailAddresses: []string{"agent-1@example.com"},
+	}
+}
+
+func TestMTLSAuth_Authenticate(t *testing.T) {
+	cert := newTestCertificate()
+	state := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	for _, tt := range []struct {
+		principalFrom PrincipalSource
+		groupsFrom    GroupsSource
+		principal     string
+		groups        []string
+	}{
+		{principalFrom: PrincipalFromCommonName, groupsFrom: GroupsFromOrganization, principal: "agent-1", groups: []string{"agents"}},
+		{principalFrom: PrincipalFromSubject, groupsFrom: GroupsFromOrganizationalUnit, principal: "CN=agent-1,OU=team-a+OU=team-b,O=agents", groups: []string{"team-a", "team-b"}},
+		{principalFrom: PrincipalFromDNSSAN, groupsFrom: GroupsFromNone, principal: "agent-1.example.com", groups: []string{}},
+		{principalFrom: PrincipalFromURISAN, groupsFrom: GroupsFromOrganization, principal: "spiffe://example.com/agent-1", groups: []string{"agents"}},
+		{principalFrom: PrincipalFromEmailSAN, groupsFrom: GroupsFromOrganization, principal: "agent-1@example.com", groups: []string{"agents"}},
+	} {
+		t.Run(string(tt.principalFrom), func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.PrincipalFrom = tt.principalFrom
+			cfg.GroupsFrom = tt.groupsFrom
+			ma := newMTLSAuth(cfg)
+
+			// The state of the HTTP requests is set by confighttp, the state of the gRPC calls is in their peer.
+			for _, ctx := range []context.Context{
+				configauth.ContextWithTLSConnectionState(context.Background(), state),
+				peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: *state}}),
+			} {
+				ctx, err := ma.authenticate(ctx, nil)
+				require.NoError(t, err)
+				auth := client.FromContext(ctx).Auth
+				require.NotNil(t, auth)
+				assert.Equal(t, tt.principal, auth.GetAttribute("principal"))
+				assert.Equal(t, tt.groups, auth.GetAttribute("groups"))
+			}
+		})
+	}
+}
+
+func TestMTLSAuth_AuthenticateErrors(t *testing.T) {
+	ma := newMTLSAuth(createDefaultConfig().(*Config))
+
+	_, err := ma.authenticate(context.Background(), nil)
+	assert.Equal(t, errNoTLS, err)
+
+	// The client certificates are not verified when the server does not require them.
+	unverified := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{newTestCertificate()}}
+	_, err = ma.authenticate(configauth.ContextWithTLSConnectionState(context.Background(), unverified), nil)
+	assert.Equal(t, errNoVerifiedCertificate, err)
+
+	ma.cfg.PrincipalFrom = PrincipalFromURISAN
+	noURI := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "agent-1"}}}}}
+	_, err = ma.authenticate(configauth.ContextWithTLSConnectionState(context.Background(), noURI), nil)
+	assert.EqualError(t, err, "the client certificate has no uri_san")
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2clientauthextension // import "go.opentelemetry.io/collector/extension/oauth2clientauthextension"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+var (
+	errNoClientIDProvided     = errors.New("no client_id provided in the OAuth2 client configuration")
+	errNoClientSecretProvided = errors.New("no client_secret provided in the OAuth2 client configuration")
+	errNoTokenURLProvided     = errors.New("no token_url provided in the OAuth2 client configuration")
+)
+
+// Config stores the configuration for the OAuth2 Client Credentials (2-legged OAuth2 flow) setup.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// ClientID is the application's ID.
+	// See https://datatracker.ietf.org/doc/html/rfc6749#section-2.2
+	ClientID string `mapstructure:"client_id"`
+
+	// ClientSecret is the application's secret.
+	// See https://datatracker.ietf.org/doc/html/rfc6749#section-2.3.1
+	ClientSecret string `mapstructure:"client_secret"`
+
+	// TokenURL is the resource server's token endpoint URL.
+	// See https://datatracker.ietf.org/doc/html/rfc6749#section-3.2
+	TokenURL string `mapstructure:"token_url"`
+
+	// Scopes optionally specifies a list of requested permission scopes.
+	// See https://datatracker.ietf.org/doc/html/rfc6749#section-3.3
+	Scopes []string `mapstructure:"scopes,omitempty"`
+
+	// EndpointParams specifies additional parameters for requests to the token endpoint, like the audience.
+	EndpointParams map[string]string `mapstructure:"endpoint_params,omitempty"`
+
+	// TLSSetting struct exposes TLS client configuration for the requests to the token endpoint.
+	TLSSetting configtls.TLSClientSetting `mapstructure:"tls,omitempty"`
+
+	// Timeout parameter configures the timeout of the requests to the token endpoint.
+	Timeout time.Duration `mapstructure:"timeout,omitempty"`
+
+	// ExpiryBuffer is the time before the expiry of a token it is refreshed, so requests are not sent
+	// with a token expiring on the way.
+	ExpiryBuffer time.Duration `mapstructure:"expiry_buffer"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.ClientID == "" {
+		return errNoClientIDProvided
+	}
+	if cfg.ClientSecret == "" {
+		return errNoClientSecretProvided
+	}
+	if cfg.TokenURL == "" {
+		return errNoTokenURLProvided
+	}
+	if err := cfg.TLSSetting.Validate(); err != nil {
+		return fmt.Errorf("tls settings has invalid configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2clientauthextension // import "go.opentelemetry.io/collector/extension/oauth2clientauthextension"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
+)
+
+const (
+	// The value of extension "type" in configuration.
+	typeStr = "oauth2client"
+)
+
+// NewFactory creates a factory for the OAuth2 client authenticator extension.
+func NewFactory() component.ExtensionFactory {
+	return component.NewExtensionFactory(typeStr, createDefaultConfig, createExtension)
+}
+
+func createDefaultConfig() config.Extension {
+	return &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+		Timeout:           5 * time.Second,
+		ExpiryBuffer:      10 * time.Second,
+	}
+}
+
+func createExtension(_ context.Context, set component.ExtensionCreateSettings, cfg config.Extension) (component.Extension, error) {
+	ca := newClientAuthenticator(cfg.(*Config), set.Logger)
+	return configauth.NewClientAuthenticator(
+		configauth.WithClientStart(ca.start),
+		configauth.WithClientRoundTripper(ca.roundTripper),
+		configauth.WithPerRPCCredentials(ca.perRPCCredentials),
+		configauth.WithClientUnaryInterceptor(ca.unaryClientInterceptor),
+		configauth.WithClientStreamInterceptor(ca.streamClientInterceptor),
+	), nil
+}
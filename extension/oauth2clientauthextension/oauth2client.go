@@ -0,0 +1,284 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2clientauthextension // import "go.opentelemetry.io/collector/extension/oauth2clientauthextension"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+// maxTokenResponseBytes is the maximum size of the token endpoint responses read.
+const maxTokenResponseBytes = 1 << 20
+
+// token is an access token obtained from the token endpoint.
+type token struct {
+	accessToken string
+	tokenType   string
+	// expiry is the time the token expires, or zero if it does not.
+	expiry time.Time
+}
+
+// authorization returns the value of the Authorization header carrying the token.
+func (t *token) authorization() string {
+	// The token type is case insensitive, see https://datatracker.ietf.org/doc/html/rfc6749#section-5.1.
+	if t.tokenType == "" || strings.EqualFold(t.tokenType, "bearer") {
+		return "Bearer " + t.accessToken
+	}
+	return t.tokenType + " " + t.accessToken
+}
+
+// tokenResponse is the successful response of the token endpoint.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-5.1
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// errorResponse is the error response of the token endpoint.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// clientAuthenticator gets access tokens with the client credentials grant, caches them until they are about to
+// expire or are rejected by the server, and adds them to the HTTP requests and gRPC calls.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-4.4
+type clientAuthenticator struct {
+	cfg    *Config
+	logger *zap.Logger
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	token *token
+}
+
+func newClientAuthenticator(cfg *Config, logger *zap.Logger) *clientAuthenticator {
+	return &clientAuthenticator{
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (ca *clientAuthenticator) start(context.Context, component.Host) error {
+	tlsCfg, err := ca.cfg.TLSSetting.LoadTLSConfig(configtls.WithLogger(ca.logger), configtls.WithEndpoint(ca.cfg.TokenURL))
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	ca.client = &http.Client{
+		Transport: transport,
+		Timeout:   ca.cfg.Timeout,
+	}
+	return nil
+}
+
+// getToken returns the cached token, or a new one if it is missing or about to expire.
+func (ca *clientAuthenticator) getToken(ctx context.Context) (*token, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.token != nil && (ca.token.expiry.IsZero() || ca.now().Add(ca.cfg.ExpiryBuffer).Before(ca.token.expiry)) {
+		return ca.token, nil
+	}
+	tok, err := ca.fetchToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ca.token = tok
+	return tok, nil
+}
+
+// invalidateToken drops the cached token if it is the one rejected by the server, so a new one is fetched.
+func (ca *clientAuthenticator) invalidateToken(rejected *token) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.token == rejected {
+		ca.token = nil
+	}
+}
+
+func (ca *clientAuthenticator) fetchToken(ctx context.Context) (*token, error) {
+	params := url.Values{}
+	params.Set("grant_type", "client_credentials")
+	if len(ca.cfg.Scopes) > 0 {
+		params.Set("scope", strings.Join(ca.cfg.Scopes, " "))
+	}
+	for key, value := range ca.cfg.EndpointParams {
+		params.Set(key, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ca.cfg.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// The client credentials are form-urlencoded before being used as the basic auth credentials.
+	// See https://datatracker.ietf.org/doc/html/rfc6749#section-2.3.1
+	req.SetBasicAuth(url.QueryEscape(ca.cfg.ClientID), url.QueryEscape(ca.cfg.ClientSecret))
+
+	requested := ca.now()
+	resp, err := ca.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request a token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxTokenResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the token response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp errorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("token request responded with HTTP Status Code %d, Error=%s, Description=%s",
+				resp.StatusCode, errResp.Error, errResp.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request responded with HTTP Status Code %d", resp.StatusCode)
+	}
+
+	var tokResp tokenResponse
+	if err = json.Unmarshal(body, &tokResp); err != nil {
+		return nil, fmt.Errorf("failed to decode the token response: %w", err)
+	}
+	if tokResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+
+	tok := &token{
+		accessToken: tokResp.AccessToken,
+		tokenType:   tokResp.TokenType,
+	}
+	if tokResp.ExpiresIn > 0 {
+		tok.expiry = requested.Add(time.Duration(tokResp.ExpiresIn) * time.Second)
+	}
+	ca.logger.Debug("Obtained a new access token", zap.Time("expiry", tok.expiry))
+	return tok, nil
+}
+
+func (ca *clientAuthenticator) roundTripper(base http.RoundTripper) (http.RoundTripper, error) {
+	return &roundTripper{base: base, authenticator: ca}, nil
+}
+
+func (ca *clientAuthenticator) perRPCCredentials() (credentials.PerRPCCredentials, error) {
+	return &perRPCCredentials{authenticator: ca}, nil
+}
+
+// roundTripper adds the access token to the HTTP requests.
+type roundTripper struct {
+	base          http.RoundTripper
+	authenticator *clientAuthenticator
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := rt.authenticator.getToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	// A RoundTripper must not modify the request.
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", tok.authorization())
+	resp, err := rt.base.RoundTrip(authReq)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// The token may have been revoked, the next request gets a new one.
+		rt.authenticator.invalidateToken(tok)
+	}
+	return resp, err
+}
+
+// unaryClientInterceptor drops the cached token when a gRPC call is rejected as unauthenticated, as the token may
+// have been revoked, so the next call gets a new one.
+func (ca *clientAuthenticator) unaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// The credentials of the call get the same cached token, the error is returned by them if it fails.
+	tok, _ := ca.getToken(ctx)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	ca.checkRejected(tok, err)
+	return err
+}
+
+// streamClientInterceptor drops the cached token when a streaming gRPC call is rejected as unauthenticated.
+func (ca *clientAuthenticator) streamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	tok, _ := ca.getToken(ctx)
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		ca.checkRejected(tok, err)
+		return nil, err
+	}
+	return &clientStream{ClientStream: stream, authenticator: ca, token: tok}, nil
+}
+
+// checkRejected drops the token if the gRPC call it was sent with failed as unauthenticated.
+func (ca *clientAuthenticator) checkRejected(tok *token, err error) {
+	if tok != nil && status.Code(err) == codes.Unauthenticated {
+		ca.invalidateToken(tok)
+	}
+}
+
+// clientStream is a gRPC client stream dropping its token when the stream is rejected as unauthenticated.
+type clientStream struct {
+	grpc.ClientStream
+	authenticator *clientAuthenticator
+	token         *token
+}
+
+// RecvMsg implements the grpc.ClientStream interface
+func (cs *clientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	cs.authenticator.checkRejected(cs.token, err)
+	return err
+}
+
+// perRPCCredentials adds the access token to the gRPC calls.
+type perRPCCredentials struct {
+	authenticator *clientAuthenticator
+}
+
+var _ credentials.PerRPCCredentials = (*perRPCCredentials)(nil)
+
+// GetRequestMetadata implements the credentials.PerRPCCredentials interface
+func (c *perRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	tok, err := c.authenticator.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": tok.authorization()}, nil
+}
+
+// RequireTransportSecurity implements the credentials.PerRPCCredentials interface.
+// The access tokens must not be sent in clear text.
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,241 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2clientauthextension
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+// tokenServer is a stand-in for an OAuth2 token endpoint issuing the tokens "token-1", "token-2"...
+type tokenServer struct {
+	*httptest.Server
+	issued    int32
+	expiresIn int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	ts := &tokenServer{expiresIn: expiresIn}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "api.metrics api.traces", r.PostForm.Get("scope"))
+		assert.Equal(t, "someaudience", r.PostForm.Get("audience"))
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "some%2Bclient" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"unknown client"}`))
+			return
+		}
+
+		issued := atomic.AddInt32(&ts.issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, issued, ts.expiresIn)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestClientAuthenticator(t *testing.T, tokenURL string) *clientAuthenticator {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientID = "some+client"
+	cfg.ClientSecret = "secret"
+	cfg.TokenURL = tokenURL
+	cfg.Scopes = []string{"api.metrics", "api.traces"}
+	cfg.EndpointParams = map[string]string{"audience": "someaudience"}
+	ca := newClientAuthenticator(cfg, zap.NewNop())
+	require.NoError(t, ca.start(context.Background(), componenttest.NewNopHost()))
+	return ca
+}
+
+func TestClientAuthenticator_TokenCaching(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	ca := newTestClientAuthenticator(t, ts.URL)
+	now := time.Now()
+	ca.now = func() time.Time { return now }
+
+	creds, err := ca.perRPCCredentials()
+	require.NoError(t, err)
+	assert.True(t, creds.RequireTransportSecurity())
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token-1"}, md)
+
+	// The token is cached until it is about to expire.
+	now = now.Add(3580 * time.Second)
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token-1"}, md)
+
+	now = now.Add(15 * time.Second)
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token-2"}, md)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&ts.issued))
+}
+
+func TestClientAuthenticator_TokenWithoutExpiry(t *testing.T) {
+	ts := newTokenServer(t, 0)
+	ca := newTestClientAuthenticator(t, ts.URL)
+
+	for i := 0; i < 3; i++ {
+		tok, err := ca.getToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", tok.accessToken)
+		assert.True(t, tok.expiry.IsZero())
+	}
+}
+
+func TestClientAuthenticator_TokenError(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	ca := newTestClientAuthenticator(t, ts.URL)
+	ca.cfg.ClientSecret = "wrong"
+
+	_, err := ca.getToken(context.Background())
+	assert.EqualError(t, err, "token request responded with HTTP Status Code 401, Error=invalid_client, Description=unknown client")
+
+	ca = newTestClientAuthenticator(t, ts.URL+"/missing")
+	ca.client.Transport = http.NewFileTransport(http.Dir(t.TempDir()))
+	_, err = ca.getToken(context.Background())
+	assert.EqualError(t, err, "token request responded with HTTP Status Code 404")
+}
+
+func TestClientAuthenticator_RoundTripper(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	ca := newTestClientAuthenticator(t, ts.URL)
+
+	var authorizations []string
+	reject := int32(0)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if atomic.LoadInt32(&reject) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer backend.Close()
+
+	rt, err := ca.roundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+	client := &http.Client{Transport: rt}
+
+	req, err := http.NewRequest(http.MethodPost, backend.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	// The request of the caller is not modified.
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// A rejected token is replaced by a new one.
+	atomic.StoreInt32(&reject, 1)
+	resp, err = client.Post(backend.URL, "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	atomic.StoreInt32(&reject, 0)
+	resp, err = client.Post(backend.URL, "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}, authorizations)
+}
+
+func TestClientAuthenticator_UnaryClientInterceptor(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	ca := newTestClientAuthenticator(t, ts.URL)
+	creds, err := ca.perRPCCredentials()
+	require.NoError(t, err)
+
+	var authorizations []string
+	invoke := func(code codes.Code) error {
+		return ca.unaryClientInterceptor(context.Background(), "/test", nil, nil, nil,
+			func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				md, err := creds.GetRequestMetadata(ctx)
+				require.NoError(t, err)
+				authorizations = append(authorizations, md["authorization"])
+				return status.Error(code, "test")
+			})
+	}
+
+	// Other errors keep the token, a rejected token is replaced by a new one.
+	assert.Equal(t, codes.Unavailable, status.Code(invoke(codes.Unavailable)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(invoke(codes.Unauthenticated)))
+	assert.Equal(t, codes.OK, status.Code(invoke(codes.OK)))
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}, authorizations)
+}
+
+func TestClientAuthenticator_StreamClientInterceptor(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	ca := newTestClientAuthenticator(t, ts.URL)
+
+	// The token is dropped when the stream cannot be created, or receives an unauthenticated error.
+	_, err := ca.streamClientInterceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.Unauthenticated, "test")
+		})
+	assert.Error(t, err)
+	stream, err := ca.streamClientInterceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &rejectedClientStream{}, nil
+		})
+	require.NoError(t, err)
+	assert.Error(t, stream.RecvMsg(nil))
+
+	tok, err := ca.getToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-3", tok.accessToken)
+}
+
+// rejectedClientStream is a grpc.ClientStream whose messages are rejected as unauthenticated.
+type rejectedClientStream struct {
+	grpc.ClientStream
+}
+
+func (*rejectedClientStream) RecvMsg(interface{}) error {
+	return status.Error(codes.Unauthenticated, "test")
+}
+
+func TestClientAuthenticator_StartInvalidTLS(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TLSSetting = configtls.TLSClientSetting{
+		TLSSetting: configtls.TLSSetting{CAFile: "missing.pem"},
+	}
+	ca := newClientAuthenticator(cfg, zap.NewNop())
+	assert.Error(t, ca.start(context.Background(), componenttest.NewNopHost()))
+}
+
+func TestTokenAuthorization(t *testing.T) {
+	assert.Equal(t, "Bearer abc", (&token{accessToken: "abc"}).authorization())
+	assert.Equal(t, "Bearer abc", (&token{accessToken: "abc", tokenType: "BEARER"}).authorization())
+	assert.Equal(t, "MAC abc", (&token{accessToken: "abc", tokenType: "MAC"}).authorization())
+}
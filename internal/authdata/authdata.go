@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authdata implements the client.AuthData and the header parsing shared by the server authenticators of the
+// core distribution.
+package authdata // import "go.opentelemetry.io/collector/internal/authdata"
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+const (
+	// PrincipalAttribute is the name of the string attribute identifying the authenticated client.
+	PrincipalAttribute = "principal"
+	// GroupsAttribute is the name of the []string attribute listing the groups of the authenticated client.
+	GroupsAttribute = "groups"
+)
+
+var attributeNames = []string{PrincipalAttribute, GroupsAttribute}
+
+// AuthData is the authentication data of a client identified by a principal, and member of groups.
+type AuthData struct {
+	principal string
+	groups    []string
+}
+
+var _ client.AuthData = (*AuthData)(nil)
+
+// New returns the authentication data of the principal, member of the groups.
+func New(principal string, groups []string) *AuthData {
+	return &AuthData{principal: principal, groups: groups}
+}
+
+// GetAttribute implements the client.AuthData interface.
+func (a *AuthData) GetAttribute(name string) interface{} {
+	switch name {
+	case PrincipalAttribute:
+		return a.principal
+	case GroupsAttribute:
+		// The groups are copied so the consumers cannot change the data seen by the others.
+		groups := make([]string, len(a.groups))
+		copy(groups, a.groups)
+		return groups
+	default:
+		return nil
+	}
+}
+
+// GetAttributeNames implements the client.AuthData interface.
+func (a *AuthData) GetAttributeNames() []string {
+	return attributeNames
+}
+
+// NewContext returns a context with the client.Info of ctx authenticated as the principal, member of the groups.
+func NewContext(ctx context.Context, principal string, groups []string) context.Context {
+	cl := client.FromContext(ctx)
+	cl.Auth = New(principal, groups)
+	return client.NewContext(ctx, cl)
+}
+
+// AuthorizationHeader returns the first value of the authorization header of HTTP requests or gRPC calls, or an empty
+// string if it is missing. The name of the header is case-insensitive, the spellings of the gRPC metadata and of the
+// canonical HTTP headers are looked up first.
+func AuthorizationHeader(headers map[string][]string) string {
+	for _, key := range []string{"authorization", "Authorization"} {
+		if values := headers[key]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	for key, values := range headers {
+		if len(values) > 0 && strings.EqualFold(key, "authorization") {
+			return values[0]
+		}
+	}
+	return ""
+}
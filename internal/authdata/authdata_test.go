@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authdata
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+func TestNewContext(t *testing.T) {
+	addr := &net.IPAddr{IP: net.IPv4(1, 2, 3, 4)}
+	ctx := client.NewContext(context.Background(), client.Info{Addr: addr})
+	ctx = NewContext(ctx, "alice", []string{"admins", "devs"})
+
+	cl := client.FromContext(ctx)
+	assert.Equal(t, addr, cl.Addr)
+	assert.Equal(t, []string{PrincipalAttribute, GroupsAttribute}, cl.Auth.GetAttributeNames())
+	assert.Equal(t, "alice", cl.Auth.GetAttribute(PrincipalAttribute))
+	assert.Equal(t, []string{"admins", "devs"}, cl.Auth.GetAttribute(GroupsAttribute))
+	assert.Nil(t, cl.Auth.GetAttribute("unknown"))
+
+	// The groups returned to a consumer cannot change the groups returned to the others.
+	cl.Auth.GetAttribute(GroupsAttribute).([]string)[0] = "changed"
+	assert.Equal(t, []string{"admins", "devs"}, cl.Auth.GetAttribute(GroupsAttribute))
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	assert.Equal(t, "", AuthorizationHeader(nil))
+	assert.Equal(t, "", AuthorizationHeader(map[string][]string{"other": {"value"}}))
+	assert.Equal(t, "", AuthorizationHeader(map[string][]string{"authorization": {}}))
+	for _, key := range []string{"authorization", "Authorization", "AUTHORIZATION", "aUthoRization"} {
+		assert.Equal(t, "Bearer token", AuthorizationHeader(map[string][]string{key: {"Bearer token", "other"}}), key)
+	}
+}
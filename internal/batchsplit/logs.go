@@ -12,14 +12,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package batchprocessor // import "go.opentelemetry.io/collector/processor/batchprocessor"
+package batchsplit // import "go.opentelemetry.io/collector/internal/batchsplit"
 
 import (
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
-// splitLogs removes logrecords from the input data and returns a new data of the specified size.
-func splitLogs(size int, src pdata.Logs) pdata.Logs {
+// Logs removes logrecords from the input data and returns a new data of the specified size.
+func Logs(size int, src pdata.Logs) pdata.Logs {
 	if src.LogRecordCount() <= size {
 		return src
 	}
@@ -12,9 +12,10 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package batchprocessor
+package batchsplit
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,7 +27,7 @@ import (
 func TestSplitMetrics_noop(t *testing.T) {
 	td := testdata.GenerateMetricsManyMetricsSameResource(20)
 	splitSize := 40
-	split := splitMetrics(splitSize, td)
+	split := Metrics(splitSize, td)
 	assert.Equal(t, td, split)
 
 	i := 0
@@ -60,24 +61,24 @@ func TestSplitMetrics(t *testing.T) {
 
 	splitMetricCount := 5
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, md)
+	split := Metrics(splitSize, md)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, cp, split)
 	assert.Equal(t, 15, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-4", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 10, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-5", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-9", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 5, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-10", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-14", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 5, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-15", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-19", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(4).Name())
@@ -101,7 +102,7 @@ func TestSplitMetricsMultipleResourceSpans(t *testing.T) {
 
 	splitMetricCount := 5
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, md)
+	split := Metrics(splitSize, md)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, 35, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
@@ -126,7 +127,7 @@ func TestSplitMetricsMultipleResourceSpans_SplitSizeGreaterThanMetricSize(t *tes
 
 	splitMetricCount := 25
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, td)
+	split := Metrics(splitSize, td)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, 40-splitMetricCount, td.MetricCount())
 	assert.Equal(t, 1, td.ResourceMetrics().Len())
@@ -146,19 +147,19 @@ func TestSplitMetricsUneven(t *testing.T) {
 	}
 
 	splitSize := 9
-	split := splitMetrics(splitSize, md)
+	split := Metrics(splitSize, md)
 	assert.Equal(t, 5, split.MetricCount())
 	assert.Equal(t, 6, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-4", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 5, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-4", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-8", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, "test-metric-int-0-9", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 }
@@ -177,14 +178,14 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	// and then split by 2 for the rest so that each metric is split in half.
 	// Verify that descriptors are preserved for all data types across splits.
 
-	split := splitMetrics(1, md)
+	split := Metrics(1, md)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 7, md.MetricCount())
 	gaugeInt := split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, gaugeInt.Gauge().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-0", gaugeInt.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 6, md.MetricCount())
 	gaugeInt = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
@@ -194,7 +195,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, 1, gaugeDouble.Gauge().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-1", gaugeDouble.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 5, md.MetricCount())
 	gaugeDouble = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
@@ -206,7 +207,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, true, sumInt.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-2", sumInt.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 4, md.MetricCount())
 	sumInt = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
@@ -220,7 +221,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, true, sumDouble.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-3", sumDouble.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 3, md.MetricCount())
 	sumDouble = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
@@ -233,7 +234,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, pdata.MetricAggregationTemporalityCumulative, histogram.Histogram().AggregationTemporality())
 	assert.Equal(t, "test-metric-int-0-4", histogram.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 2, md.MetricCount())
 	histogram = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
@@ -245,7 +246,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, pdata.MetricAggregationTemporalityDelta, exponentialHistogram.ExponentialHistogram().AggregationTemporality())
 	assert.Equal(t, "test-metric-int-0-5", exponentialHistogram.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
 	exponentialHistogram = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
@@ -256,7 +257,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, 1, summary.Summary().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-6", summary.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	summary = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, summary.Summary().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-6", summary.Name())
@@ -272,7 +273,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	}
 
 	splitSize := 1
-	split := splitMetrics(splitSize, md)
+	split := Metrics(splitSize, md)
 	splitMetric := split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 2, md.MetricCount())
@@ -280,7 +281,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	assert.Equal(t, true, splitMetric.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-0", splitMetric.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	splitMetric = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
@@ -288,7 +289,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	assert.Equal(t, true, splitMetric.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-0", splitMetric.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	splitMetric = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
@@ -296,7 +297,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	assert.Equal(t, true, splitMetric.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-1", splitMetric.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = Metrics(splitSize, md)
 	splitMetric = split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
@@ -327,7 +328,7 @@ func TestSplitMetricsMultipleILM(t *testing.T) {
 
 	splitMetricCount := 40
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, md)
+	split := Metrics(splitSize, md)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, 20, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
@@ -358,9 +359,13 @@ func BenchmarkSplitMetrics(b *testing.B) {
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		cloneReq := clones[n]
-		split := splitMetrics(128*dataPointCount, cloneReq)
+		split := Metrics(128*dataPointCount, cloneReq)
 		if split.MetricCount() != 128 || cloneReq.MetricCount() != 400-128 {
 			b.Fail()
 		}
 	}
 }
+
+func getTestMetricName(requestNum, index int) string {
+	return fmt.Sprintf("test-metric-int-%d-%d", requestNum, index)
+}
@@ -37,10 +37,20 @@ const (
 	SentLogRecordsKey = "sent_log_records"
 	// FailedToSendLogRecordsKey used to track logs that failed to be sent by exporters.
 	FailedToSendLogRecordsKey = "send_failed_log_records"
+
+	// SignalKey used to identify the signal of the data sent by exporters.
+	SignalKey = "signal"
+	// QueueLatencyKey used to track the time spent by the batches in the sending queue of exporters.
+	QueueLatencyKey = "queue_latency"
+	// SendLatencyKey used to track the duration of the attempts to send batches by exporters.
+	SendLatencyKey = "send_latency"
+	// RetriesKey used to track the retries of batches by exporters.
+	RetriesKey = "retries"
 )
 
 var (
 	TagKeyExporter, _ = tag.NewKey(ExporterKey)
+	TagKeySignal, _   = tag.NewKey(SignalKey)
 
 	ExporterPrefix                 = ExporterKey + NameSep
 	ExportTraceDataOperationSuffix = NameSep + "traces"
@@ -76,4 +86,18 @@ var (
 		ExporterPrefix+FailedToSendLogRecordsKey,
 		"Number of log records in failed attempts to send to destination.",
 		stats.UnitDimensionless)
+
+	// Exporter sending queue and retry metrics, only reported by the exporters built with the exporterhelper.
+	ExporterQueueLatency = stats.Float64(
+		ExporterPrefix+QueueLatencyKey,
+		"Time spent by batches in the sending queue before being sent.",
+		stats.UnitMilliseconds)
+	ExporterSendLatency = stats.Float64(
+		ExporterPrefix+SendLatencyKey,
+		"Duration of every attempt to send batches to destination.",
+		stats.UnitMilliseconds)
+	ExporterRetries = stats.Int64(
+		ExporterPrefix+RetriesKey,
+		"Number of retries of batches which failed to be sent to destination.",
+		stats.UnitDimensionless)
 )
@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package obsmetrics // import "go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+const (
+	// TLSKey used to identify the metrics of the TLS configurations.
+	TLSKey = "tls"
+	// FileKey used to identify the file reloaded by the TLS configurations.
+	FileKey = "file"
+
+	// ReloadFailuresKey used to track the failures to reload the certificates and CAs.
+	ReloadFailuresKey = "reload_failures"
+)
+
+const (
+	TLSPrefix = TLSKey + NameSep
+)
+
+var (
+	TagKeyFile, _ = tag.NewKey(FileKey)
+
+	TLSReloadFailures = stats.Int64(
+		TLSPrefix+ReloadFailuresKey,
+		"Number of failures to reload the certificates and CAs of the TLS configurations.",
+		stats.UnitDimensionless)
+)
@@ -27,6 +27,9 @@ import (
 
 var (
 	globalLevel = int32(configtelemetry.LevelBasic)
+
+	// exporterLatencyDistribution buckets the latencies of the exporters in milliseconds, from 1ms to 5m.
+	exporterLatencyDistribution = view.Distribution(1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 300000)
 )
 
 // ObsMetrics wraps OpenCensus View for Collector observability metrics
@@ -100,6 +103,19 @@ func allViews() *ObsMetrics {
 	}
 	views = append(views, errorNumberView)
 
+	// Exporter sending queue and retry views.
+	tagKeys = []tag.Key{obsmetrics.TagKeyExporter, obsmetrics.TagKeySignal}
+	views = append(views, genViews([]*stats.Int64Measure{obsmetrics.ExporterRetries}, tagKeys, view.Sum())...)
+	for _, measure := range []*stats.Float64Measure{obsmetrics.ExporterQueueLatency, obsmetrics.ExporterSendLatency} {
+		views = append(views, &view.View{
+			Name:        measure.Name(),
+			Description: measure.Description(),
+			TagKeys:     tagKeys,
+			Measure:     measure,
+			Aggregation: exporterLatencyDistribution,
+		})
+	}
+
 	// Processor views.
 	measures = []*stats.Int64Measure{
 		obsmetrics.ProcessorAcceptedSpans,
@@ -115,6 +131,10 @@ func allViews() *ObsMetrics {
 	tagKeys = []tag.Key{obsmetrics.TagKeyProcessor}
 	views = append(views, genViews(measures, tagKeys, view.Sum())...)
 
+	// TLS views.
+	tagKeys = []tag.Key{obsmetrics.TagKeyFile}
+	views = append(views, genViews([]*stats.Int64Measure{obsmetrics.TLSReloadFailures}, tagKeys, view.Sum())...)
+
 	return &ObsMetrics{
 		Views: views,
 	}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package partialsuccess implements the OTLP partial success handling shared by the OTLP exporters and receiver.
+// See https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/protocol/otlp.md#partial-success
+package partialsuccess // import "go.opentelemetry.io/collector/internal/partialsuccess"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+// ToError returns an error if the server rejected some items of a successful request. The rejected items are
+// reported as failed but they must not be retried, the server already knows they cannot be accepted.
+func ToError(logger *zap.Logger, rejected int64, errorMessage string) error {
+	if rejected == 0 {
+		if errorMessage != "" {
+			// The server accepted all the items but has something to tell us.
+			logger.Warn("Partial success response", zap.String("message", errorMessage))
+		}
+		return nil
+	}
+	err := fmt.Errorf("partial success response: %d items rejected: %s", rejected, errorMessage)
+	return consumererror.NewPermanent(consumererror.NewPartial(err, int(rejected)))
+}
+
+// Rejected returns the number of spans, data points or log records rejected by a consumer of count items, and whether
+// it permanently failed for only part of them, as reported by a consumererror.Traces, Metrics or Logs error.
+func Rejected(err error, count int) (int, bool) {
+	if !consumererror.IsPermanent(err) {
+		return 0, false
+	}
+
+	var rejected int
+	var tracesErr consumererror.Traces
+	var metricsErr consumererror.Metrics
+	var logsErr consumererror.Logs
+	switch {
+	case errors.As(err, &tracesErr):
+		rejected = tracesErr.GetTraces().SpanCount()
+	case errors.As(err, &metricsErr):
+		rejected = metricsErr.GetMetrics().DataPointCount()
+	case errors.As(err, &logsErr):
+		rejected = logsErr.GetLogs().LogRecordCount()
+	default:
+		return 0, false
+	}
+	return rejected, rejected < count
+}
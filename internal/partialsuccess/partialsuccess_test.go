@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partialsuccess
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/testdata"
+)
+
+func TestToError(t *testing.T) {
+	logger := zap.NewNop()
+	assert.NoError(t, ToError(logger, 0, ""))
+	assert.NoError(t, ToError(logger, 0, "warning"))
+
+	err := ToError(logger, 3, "invalid data points")
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.EqualError(t, err, "Permanent error: partial success response: 3 items rejected: invalid data points")
+	var partial consumererror.Partial
+	require.True(t, errors.As(err, &partial))
+	assert.Equal(t, 3, partial.Failed())
+}
+
+func TestRejected(t *testing.T) {
+	cause := errors.New("invalid item")
+	tests := []struct {
+		name     string
+		err      error
+		count    int
+		rejected int
+		partial  bool
+	}{
+		{
+			name: "no_error",
+		},
+		{
+			name:  "not_permanent",
+			err:   consumererror.NewTraces(cause, testdata.GenerateTracesOneSpan()),
+			count: 2,
+		},
+		{
+			name:  "no_signal_data",
+			err:   consumererror.NewPermanent(cause),
+			count: 2,
+		},
+		{
+			name:     "traces",
+			err:      consumererror.NewPermanent(consumererror.NewTraces(cause, testdata.GenerateTracesOneSpan())),
+			count:    2,
+			rejected: 1,
+			partial:  true,
+		},
+		{
+			name:     "metrics",
+			err:      consumererror.NewPermanent(consumererror.NewMetrics(cause, testdata.GenerateMetricsOneMetric())),
+			count:    3,
+			rejected: 2,
+			partial:  true,
+		},
+		{
+			name:     "all_logs",
+			err:      consumererror.NewPermanent(consumererror.NewLogs(cause, testdata.GenerateLogsOneLogRecord())),
+			count:    1,
+			rejected: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected, partial := Rejected(tt.err, tt.count)
+			assert.Equal(t, tt.rejected, rejected)
+			assert.Equal(t, tt.partial, partial)
+		})
+	}
+}
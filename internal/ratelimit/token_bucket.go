@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit implements a token bucket used to limit the rate of data sent or received by components.
+package ratelimit // import "go.opentelemetry.io/collector/internal/ratelimit"
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a token bucket refilled at a constant rate, up to a maximum number of tokens (the burst).
+// It is safe for concurrent use.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket returns a full TokenBucket refilled with rate tokens per second, holding at most burst tokens.
+// If burst is not positive, the bucket holds as many tokens as refilled in one second (at least one).
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := float64(burst)
+	if burst <= 0 {
+		b = math.Max(1, math.Ceil(rate))
+	}
+	tb := &TokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		now:    time.Now,
+	}
+	tb.last = tb.now()
+	return tb
+}
+
+// refill must be called while holding the lock.
+func (tb *TokenBucket) refill() {
+	now := tb.now()
+	tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+	tb.last = now
+}
+
+// Allow takes n tokens if they are available right now, and reports whether they were taken.
+func (tb *TokenBucket) Allow(n int) bool {
+	return tb.Take(n) == 0
+}
+
+// Take takes n tokens if they are available right now and returns zero. Otherwise no token is taken,
+// and Take returns how long to wait until the n tokens are available, e.g. to tell a client when to retry.
+func (tb *TokenBucket) Take(n int) time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	if missing := float64(n) - tb.tokens; missing > 0 {
+		return time.Duration(math.Ceil(missing / tb.rate * float64(time.Second)))
+	}
+	tb.tokens -= float64(n)
+	return 0
+}
+
+// Reserve takes n tokens and returns how long to wait before acting as if they were available.
+// Taking more tokens than available leaves the bucket in debt, so n can exceed the burst: such a
+// reservation waits until the bucket is refilled and delays the following ones accordingly.
+func (tb *TokenBucket) Reserve(n int) time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	tb.tokens -= float64(n)
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// Cancel gives back n tokens taken by a reservation which was not used.
+func (tb *TokenBucket) Cancel(n int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	tb.tokens = math.Min(tb.burst, tb.tokens+float64(n))
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTokenBucket(rate float64, burst int) (*TokenBucket, *time.Time) {
+	now := time.Unix(1000, 0)
+	tb := NewTokenBucket(rate, burst)
+	tb.now = func() time.Time { return now }
+	tb.last = now
+	return tb, &now
+}
+
+func TestTokenBucket_Allow(t *testing.T) {
+	tb, now := newTestTokenBucket(10, 5)
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, tb.Allow(1))
+	}
+	assert.False(t, tb.Allow(1))
+
+	*now = now.Add(100 * time.Millisecond)
+	assert.True(t, tb.Allow(1))
+	assert.False(t, tb.Allow(1))
+
+	// The bucket never holds more tokens than the burst.
+	*now = now.Add(time.Hour)
+	assert.False(t, tb.Allow(6))
+	assert.True(t, tb.Allow(5))
+}
+
+func TestTokenBucket_Take(t *testing.T) {
+	tb, now := newTestTokenBucket(4, 2)
+
+	assert.Zero(t, tb.Take(2))
+	assert.Equal(t, 250*time.Millisecond, tb.Take(1))
+	assert.Equal(t, 500*time.Millisecond, tb.Take(2))
+
+	// Nothing is taken when the tokens are not available.
+	*now = now.Add(100 * time.Millisecond)
+	assert.Equal(t, 150*time.Millisecond, tb.Take(1))
+	*now = now.Add(150 * time.Millisecond)
+	assert.Zero(t, tb.Take(1))
+	assert.Equal(t, 250*time.Millisecond, tb.Take(1))
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	tb, now := newTestTokenBucket(10, 5)
+
+	assert.Zero(t, tb.Reserve(5))
+	assert.Equal(t, 100*time.Millisecond, tb.Reserve(1))
+
+	// A reservation larger than the burst is allowed, and delays the following ones.
+	assert.Equal(t, 2100*time.Millisecond, tb.Reserve(20))
+	tb.Cancel(20)
+	assert.Equal(t, 200*time.Millisecond, tb.Reserve(1))
+
+	*now = now.Add(200 * time.Millisecond)
+	assert.Zero(t, tb.Reserve(0))
+	assert.True(t, tb.Allow(0))
+	assert.False(t, tb.Allow(1))
+}
+
+func TestTokenBucket_DefaultBurst(t *testing.T) {
+	tb, _ := newTestTokenBucket(2.5, 0)
+	assert.True(t, tb.Allow(3))
+	assert.False(t, tb.Allow(1))
+
+	tb, _ = newTestTokenBucket(0.1, 0)
+	assert.True(t, tb.Allow(1))
+	assert.False(t, tb.Allow(1))
+}
@@ -80,6 +80,9 @@ func (m *ExportMetricsServiceRequest) GetResourceMetrics() []*v1.ResourceMetrics
 }
 
 type ExportMetricsServiceResponse struct {
+	// The details of a partially successful export request, set by the server when it rejects
+	// part of the data, or to convey warnings even when the request was fully accepted.
+	PartialSuccess ExportMetricsPartialSuccess `protobuf:"bytes,1,opt,name=partial_success,json=partialSuccess,proto3" json:"partial_success"`
 }
 
 func (m *ExportMetricsServiceResponse) Reset()         { *m = ExportMetricsServiceResponse{} }
@@ -115,9 +118,72 @@ func (m *ExportMetricsServiceResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ExportMetricsServiceResponse proto.InternalMessageInfo
 
+func (m *ExportMetricsServiceResponse) GetPartialSuccess() ExportMetricsPartialSuccess {
+	if m != nil {
+		return m.PartialSuccess
+	}
+	return ExportMetricsPartialSuccess{}
+}
+
+type ExportMetricsPartialSuccess struct {
+	// The number of rejected data points.
+	RejectedDataPoints int64 `protobuf:"varint,1,opt,name=rejected_data_points,json=rejectedDataPoints,proto3" json:"rejected_data_points,omitempty"`
+	// A developer-facing human-readable message in English explaining why the server
+	// rejected parts of the data, or conveying warnings during a full success.
+	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+}
+
+func (m *ExportMetricsPartialSuccess) Reset()         { *m = ExportMetricsPartialSuccess{} }
+func (m *ExportMetricsPartialSuccess) String() string { return proto.CompactTextString(m) }
+func (*ExportMetricsPartialSuccess) ProtoMessage()    {}
+func (*ExportMetricsPartialSuccess) Descriptor() ([]byte, []int) {
+	return fileDescriptor_75fb6015e6e64798, []int{2}
+}
+func (m *ExportMetricsPartialSuccess) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExportMetricsPartialSuccess) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExportMetricsPartialSuccess.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExportMetricsPartialSuccess) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportMetricsPartialSuccess.Merge(m, src)
+}
+func (m *ExportMetricsPartialSuccess) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExportMetricsPartialSuccess) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportMetricsPartialSuccess.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportMetricsPartialSuccess proto.InternalMessageInfo
+
+func (m *ExportMetricsPartialSuccess) GetRejectedDataPoints() int64 {
+	if m != nil {
+		return m.RejectedDataPoints
+	}
+	return 0
+}
+
+func (m *ExportMetricsPartialSuccess) GetErrorMessage() string {
+	if m != nil {
+		return m.ErrorMessage
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ExportMetricsServiceRequest)(nil), "opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceRequest")
 	proto.RegisterType((*ExportMetricsServiceResponse)(nil), "opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceResponse")
+	proto.RegisterType((*ExportMetricsPartialSuccess)(nil), "opentelemetry.proto.collector.metrics.v1.ExportMetricsPartialSuccess")
 }
 
 func init() {
@@ -125,26 +191,33 @@ func init() {
 }
 
 var fileDescriptor_75fb6015e6e64798 = []byte{
-	// 302 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x52, 0x3f, 0x4b, 0xc3, 0x40,
-	0x14, 0xcf, 0x21, 0x74, 0x38, 0x41, 0x25, 0x3a, 0x48, 0x94, 0x43, 0x32, 0x15, 0x94, 0x3b, 0x5a,
-	0x77, 0x87, 0x42, 0xdd, 0xc4, 0x12, 0xb7, 0x2e, 0x25, 0xa6, 0x8f, 0x10, 0x88, 0xf7, 0xe2, 0xdd,
-	0x35, 0xd8, 0x6f, 0xe1, 0xea, 0x77, 0xd0, 0xef, 0xe1, 0xd8, 0xd1, 0x51, 0x92, 0x2f, 0x22, 0xe9,
-	0x45, 0xe5, 0x34, 0x48, 0xc1, 0xed, 0xf1, 0x7b, 0xbf, 0x7f, 0xc9, 0x3d, 0x7a, 0x81, 0x05, 0x48,
-	0x03, 0x39, 0xdc, 0x81, 0x51, 0x4b, 0x51, 0x28, 0x34, 0x28, 0x12, 0xcc, 0x73, 0x48, 0x0c, 0x2a,
-	0xd1, 0xa0, 0x59, 0xa2, 0x45, 0x39, 0xf8, 0x1c, 0x67, 0x1a, 0x54, 0x99, 0x25, 0xc0, 0xd7, 0x54,
-	0xbf, 0xef, 0xe8, 0x2d, 0xc8, 0xbf, 0xf4, 0xbc, 0x15, 0xf1, 0x72, 0x10, 0x1c, 0xa4, 0x98, 0xa2,
-	0xf5, 0x6f, 0x26, 0x4b, 0x0d, 0xce, 0xba, 0xf2, 0x7f, 0xa7, 0x5a, 0x76, 0xb8, 0xa4, 0x47, 0xe3,
-	0x87, 0x02, 0x95, 0xb9, 0xb2, 0xf0, 0x8d, 0xed, 0x12, 0xc1, 0xfd, 0x02, 0xb4, 0xf1, 0xa7, 0x74,
-	0x4f, 0x81, 0xc6, 0x85, 0x4a, 0x60, 0xd6, 0x0a, 0x0f, 0xc9, 0xc9, 0x56, 0x7f, 0x7b, 0x28, 0x78,
-	0x57, 0xcf, 0xef, 0x76, 0x3c, 0x6a, 0x75, 0xad, 0x71, 0xb4, 0xab, 0x5c, 0x20, 0x64, 0xf4, 0xb8,
-	0x3b, 0x5a, 0x17, 0x28, 0x35, 0x0c, 0x9f, 0x09, 0xdd, 0x71, 0x57, 0xfe, 0x13, 0xa1, 0x3d, 0xab,
-	0xf1, 0xc7, 0x7c, 0xd3, 0xff, 0xc4, 0xff, 0xf8, 0xc0, 0xe0, 0xf2, 0xbf, 0x36, 0xb6, 0x6c, 0xe8,
-	0x8d, 0x5e, 0xc8, 0x6b, 0xc5, 0xc8, 0xaa, 0x62, 0xe4, 0xbd, 0x62, 0xe4, 0xb1, 0x66, 0xde, 0xaa,
-	0x66, 0xde, 0x5b, 0xcd, 0x3c, 0x7a, 0x9a, 0xe1, 0xc6, 0x31, 0xa3, 0x7d, 0x37, 0x61, 0xd2, 0x30,
-	0x27, 0x64, 0x7a, 0x9d, 0xfe, 0xf4, 0xc8, 0x9c, 0xb3, 0xc2, 0x39, 0xe4, 0x22, 0x93, 0x06, 0x94,
-	0x8c, 0x73, 0x31, 0x8f, 0x4d, 0x6c, 0x9f, 0x3e, 0x05, 0xd9, 0x79, 0x7d, 0xb7, 0xbd, 0xf5, 0xfa,
-	0xfc, 0x23, 0x00, 0x00, 0xff, 0xff, 0xbd, 0x25, 0x0c, 0xb5, 0xb0, 0x02, 0x00, 0x00,
+	// 401 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0x4f, 0xcf, 0xd2, 0x30,
+	0x18, 0x5f, 0xc5, 0x90, 0x58, 0x14, 0x4c, 0xe5, 0x40, 0xc0, 0x4c, 0x32, 0x2f, 0x4b, 0x34, 0x9d,
+	0xe0, 0xdd, 0x03, 0x11, 0x6f, 0xc4, 0x65, 0xdc, 0xb8, 0x2c, 0xb5, 0x3c, 0x59, 0x66, 0xc6, 0x3a,
+	0xdb, 0x42, 0xe4, 0x5b, 0x78, 0xf0, 0xe2, 0x77, 0xd0, 0xef, 0xc1, 0x91, 0xa3, 0x27, 0x63, 0xe0,
+	0x8b, 0x98, 0xad, 0x43, 0x53, 0x5d, 0x0c, 0x79, 0xdf, 0x5b, 0xf3, 0x7b, 0x7e, 0xff, 0xd6, 0x67,
+	0xc5, 0xaf, 0x44, 0x01, 0xb9, 0x86, 0x0c, 0x36, 0xa0, 0xe5, 0x3e, 0x28, 0xa4, 0xd0, 0x22, 0xe0,
+	0x22, 0xcb, 0x80, 0x6b, 0x21, 0x83, 0x12, 0x4d, 0xb9, 0x0a, 0x76, 0x93, 0xcb, 0x31, 0x56, 0x20,
+	0x77, 0x29, 0x07, 0x5a, 0x51, 0x89, 0x6f, 0xe9, 0x0d, 0x48, 0x7f, 0xeb, 0x69, 0x2d, 0xa2, 0xbb,
+	0xc9, 0xb0, 0x9f, 0x88, 0x44, 0x18, 0xff, 0xf2, 0x64, 0xa8, 0xc3, 0xe7, 0x4d, 0xf9, 0xff, 0xa6,
+	0x1a, 0xb6, 0xb7, 0xc7, 0xa3, 0xf9, 0xc7, 0x42, 0x48, 0xbd, 0x30, 0xf0, 0xd2, 0x74, 0x89, 0xe0,
+	0xc3, 0x16, 0x94, 0x26, 0x2b, 0xfc, 0x50, 0x82, 0x12, 0x5b, 0xc9, 0x21, 0xae, 0x85, 0x03, 0x34,
+	0x6e, 0xf9, 0x9d, 0x69, 0x40, 0x9b, 0x7a, 0xfe, 0x69, 0x47, 0xa3, 0x5a, 0x57, 0x1b, 0x47, 0x3d,
+	0x69, 0x03, 0xde, 0x67, 0x84, 0x1f, 0x37, 0x67, 0xab, 0x42, 0xe4, 0x0a, 0x88, 0xc6, 0xbd, 0x82,
+	0x49, 0x9d, 0xb2, 0x2c, 0x56, 0x5b, 0xce, 0x41, 0x95, 0xd9, 0xc8, 0xef, 0x4c, 0xe7, 0xf4, 0xda,
+	0x3b, 0xa2, 0x56, 0x40, 0x68, 0xdc, 0x96, 0xc6, 0x6c, 0x76, 0xf7, 0xf0, 0xe3, 0x89, 0x13, 0x75,
+	0x0b, 0x0b, 0xf5, 0x34, 0x1e, 0xfd, 0x47, 0x44, 0x5e, 0xe0, 0xbe, 0x84, 0xf7, 0xc0, 0x35, 0xac,
+	0xe3, 0x35, 0xd3, 0x2c, 0x2e, 0x44, 0x9a, 0x6b, 0xd3, 0xac, 0x15, 0x91, 0xcb, 0xec, 0x35, 0xd3,
+	0x2c, 0xac, 0x26, 0xe4, 0x29, 0x7e, 0x00, 0x52, 0x0a, 0x19, 0x6f, 0x40, 0x29, 0x96, 0xc0, 0xe0,
+	0xce, 0x18, 0xf9, 0xf7, 0xa2, 0xfb, 0x15, 0xb8, 0x30, 0xd8, 0xf4, 0x2b, 0xc2, 0x5d, 0xfb, 0x1a,
+	0xc8, 0x17, 0x84, 0xdb, 0xa6, 0x09, 0xb9, 0xe9, 0x07, 0xdb, 0xdb, 0x1c, 0xbe, 0xb9, 0xad, 0x8d,
+	0x59, 0x8c, 0xe7, 0xcc, 0xbe, 0xa1, 0xc3, 0xc9, 0x45, 0xc7, 0x93, 0x8b, 0x7e, 0x9e, 0x5c, 0xf4,
+	0xe9, 0xec, 0x3a, 0xc7, 0xb3, 0xeb, 0x7c, 0x3f, 0xbb, 0x0e, 0x7e, 0x96, 0x8a, 0xab, 0x63, 0x66,
+	0x8f, 0xec, 0x84, 0xb0, 0x64, 0x86, 0x68, 0xf5, 0x36, 0xf9, 0xdb, 0x23, 0xb5, 0xde, 0x90, 0x58,
+	0x43, 0x16, 0xa4, 0xb9, 0x06, 0x99, 0xb3, 0x2c, 0x28, 0xd7, 0x60, 0xfe, 0xf3, 0x04, 0xf2, 0xc6,
+	0xa7, 0xf6, 0xae, 0x5d, 0x8d, 0x5f, 0xfe, 0x1a, 0x00, 0xa7, 0x3d, 0x8c, 0xa6, 0x9d, 0x03, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -288,6 +361,51 @@ func (m *ExportMetricsServiceResponse) MarshalToSizedBuffer(dAtA []byte) (int, e
 	_ = i
 	var l int
 	_ = l
+	{
+		size, err := m.PartialSuccess.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintMetricsService(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *ExportMetricsPartialSuccess) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExportMetricsPartialSuccess) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExportMetricsPartialSuccess) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.ErrorMessage) > 0 {
+		i -= len(m.ErrorMessage)
+		copy(dAtA[i:], m.ErrorMessage)
+		i = encodeVarintMetricsService(dAtA, i, uint64(len(m.ErrorMessage)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.RejectedDataPoints != 0 {
+		i = encodeVarintMetricsService(dAtA, i, uint64(m.RejectedDataPoints))
+		i--
+		dAtA[i] = 0x8
+	}
 	return len(dAtA) - i, nil
 }
 
@@ -323,6 +441,24 @@ func (m *ExportMetricsServiceResponse) Size() (n int) {
 	}
 	var l int
 	_ = l
+	l = m.PartialSuccess.Size()
+	n += 1 + l + sovMetricsService(uint64(l))
+	return n
+}
+
+func (m *ExportMetricsPartialSuccess) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.RejectedDataPoints != 0 {
+		n += 1 + sovMetricsService(uint64(m.RejectedDataPoints))
+	}
+	l = len(m.ErrorMessage)
+	if l > 0 {
+		n += 1 + l + sovMetricsService(uint64(l))
+	}
 	return n
 }
 
@@ -445,6 +581,140 @@ func (m *ExportMetricsServiceResponse) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: ExportMetricsServiceResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartialSuccess", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetricsService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetricsService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetricsService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.PartialSuccess.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMetricsService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthMetricsService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExportMetricsPartialSuccess) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMetricsService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExportMetricsPartialSuccess: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExportMetricsPartialSuccess: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedDataPoints", wireType)
+			}
+			m.RejectedDataPoints = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetricsService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RejectedDataPoints |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrorMessage", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetricsService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMetricsService
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetricsService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ErrorMessage = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetricsService(dAtA[iNdEx:])
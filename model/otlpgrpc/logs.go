@@ -84,6 +84,36 @@ func (lr LogsResponse) UnmarshalJSON(data []byte) error {
 	return jsonUnmarshaler.Unmarshal(bytes.NewReader(data), lr.orig)
 }
 
+// PartialSuccess returns the details of a partially successful export request.
+func (lr LogsResponse) PartialSuccess() LogsPartialSuccess {
+	return LogsPartialSuccess{orig: &lr.orig.PartialSuccess}
+}
+
+// LogsPartialSuccess represents the details of a partially successful export request.
+type LogsPartialSuccess struct {
+	orig *otlpcollectorlog.ExportLogsPartialSuccess
+}
+
+// RejectedLogRecords returns the number of log records rejected by the server.
+func (ps LogsPartialSuccess) RejectedLogRecords() int64 {
+	return ps.orig.RejectedLogRecords
+}
+
+// SetRejectedLogRecords replaces the number of log records rejected by the server.
+func (ps LogsPartialSuccess) SetRejectedLogRecords(count int64) {
+	ps.orig.RejectedLogRecords = count
+}
+
+// ErrorMessage returns the message explaining why the server rejected parts of the data, or conveying a warning.
+func (ps LogsPartialSuccess) ErrorMessage() string {
+	return ps.orig.ErrorMessage
+}
+
+// SetErrorMessage replaces the message explaining why the server rejected parts of the data, or conveying a warning.
+func (ps LogsPartialSuccess) SetErrorMessage(msg string) {
+	ps.orig.ErrorMessage = msg
+}
+
 // LogsRequest represents the response for gRPC client/server.
 type LogsRequest struct {
 	orig *otlpcollectorlog.ExportLogsServiceRequest
@@ -157,6 +157,23 @@ func TestLogsRequestJSON_Deprecated(t *testing.T) {
 	assert.Equal(t, strings.Join(strings.Fields(string(logsRequestJSON)), ""), string(got))
 }
 
+func TestLogsResponsePartialSuccess(t *testing.T) {
+	resp := NewLogsResponse()
+	resp.PartialSuccess().SetRejectedLogRecords(3)
+	resp.PartialSuccess().SetErrorMessage("invalid item")
+
+	got, err := resp.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"partialSuccess":{"rejectedLogRecords":"3","errorMessage":"invalid item"}}`, string(got))
+
+	protoBytes, err := resp.MarshalProto()
+	require.NoError(t, err)
+	decoded := NewLogsResponse()
+	require.NoError(t, decoded.UnmarshalProto(protoBytes))
+	assert.EqualValues(t, 3, decoded.PartialSuccess().RejectedLogRecords())
+	assert.Equal(t, "invalid item", decoded.PartialSuccess().ErrorMessage())
+}
+
 func TestLogsGrpc(t *testing.T) {
 	lis := bufconn.Listen(1024 * 1024)
 	s := grpc.NewServer()
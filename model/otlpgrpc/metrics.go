@@ -80,6 +80,36 @@ func (mr MetricsResponse) UnmarshalJSON(data []byte) error {
 	return jsonUnmarshaler.Unmarshal(bytes.NewReader(data), mr.orig)
 }
 
+// PartialSuccess returns the details of a partially successful export request.
+func (mr MetricsResponse) PartialSuccess() MetricsPartialSuccess {
+	return MetricsPartialSuccess{orig: &mr.orig.PartialSuccess}
+}
+
+// MetricsPartialSuccess represents the details of a partially successful export request.
+type MetricsPartialSuccess struct {
+	orig *otlpcollectormetrics.ExportMetricsPartialSuccess
+}
+
+// RejectedDataPoints returns the number of data points rejected by the server.
+func (ps MetricsPartialSuccess) RejectedDataPoints() int64 {
+	return ps.orig.RejectedDataPoints
+}
+
+// SetRejectedDataPoints replaces the number of data points rejected by the server.
+func (ps MetricsPartialSuccess) SetRejectedDataPoints(count int64) {
+	ps.orig.RejectedDataPoints = count
+}
+
+// ErrorMessage returns the message explaining why the server rejected parts of the data, or conveying a warning.
+func (ps MetricsPartialSuccess) ErrorMessage() string {
+	return ps.orig.ErrorMessage
+}
+
+// SetErrorMessage replaces the message explaining why the server rejected parts of the data, or conveying a warning.
+func (ps MetricsPartialSuccess) SetErrorMessage(msg string) {
+	ps.orig.ErrorMessage = msg
+}
+
 // MetricsRequest represents the response for gRPC client/server.
 type MetricsRequest struct {
 	orig *otlpcollectormetrics.ExportMetricsServiceRequest
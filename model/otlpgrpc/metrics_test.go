@@ -141,6 +141,23 @@ func TestMetricsRequestJSON_Deprecated(t *testing.T) {
 	assert.Equal(t, strings.Join(strings.Fields(string(metricsRequestJSON)), ""), string(got))
 }
 
+func TestMetricsResponsePartialSuccess(t *testing.T) {
+	resp := NewMetricsResponse()
+	resp.PartialSuccess().SetRejectedDataPoints(3)
+	resp.PartialSuccess().SetErrorMessage("invalid item")
+
+	got, err := resp.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"partialSuccess":{"rejectedDataPoints":"3","errorMessage":"invalid item"}}`, string(got))
+
+	protoBytes, err := resp.MarshalProto()
+	require.NoError(t, err)
+	decoded := NewMetricsResponse()
+	require.NoError(t, decoded.UnmarshalProto(protoBytes))
+	assert.EqualValues(t, 3, decoded.PartialSuccess().RejectedDataPoints())
+	assert.Equal(t, "invalid item", decoded.PartialSuccess().ErrorMessage())
+}
+
 func TestMetricsGrpc(t *testing.T) {
 	lis := bufconn.Listen(1024 * 1024)
 	s := grpc.NewServer()
@@ -80,6 +80,36 @@ func (tr TracesResponse) UnmarshalJSON(data []byte) error {
 	return jsonUnmarshaler.Unmarshal(bytes.NewReader(data), tr.orig)
 }
 
+// PartialSuccess returns the details of a partially successful export request.
+func (tr TracesResponse) PartialSuccess() TracesPartialSuccess {
+	return TracesPartialSuccess{orig: &tr.orig.PartialSuccess}
+}
+
+// TracesPartialSuccess represents the details of a partially successful export request.
+type TracesPartialSuccess struct {
+	orig *otlpcollectortrace.ExportTracePartialSuccess
+}
+
+// RejectedSpans returns the number of spans rejected by the server.
+func (ps TracesPartialSuccess) RejectedSpans() int64 {
+	return ps.orig.RejectedSpans
+}
+
+// SetRejectedSpans replaces the number of spans rejected by the server.
+func (ps TracesPartialSuccess) SetRejectedSpans(count int64) {
+	ps.orig.RejectedSpans = count
+}
+
+// ErrorMessage returns the message explaining why the server rejected parts of the data, or conveying a warning.
+func (ps TracesPartialSuccess) ErrorMessage() string {
+	return ps.orig.ErrorMessage
+}
+
+// SetErrorMessage replaces the message explaining why the server rejected parts of the data, or conveying a warning.
+func (ps TracesPartialSuccess) SetErrorMessage(msg string) {
+	ps.orig.ErrorMessage = msg
+}
+
 // TracesRequest represents the response for gRPC client/server.
 type TracesRequest struct {
 	orig *otlpcollectortrace.ExportTraceServiceRequest
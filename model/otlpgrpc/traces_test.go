@@ -157,6 +157,23 @@ func TestTracesRequestJSON_Deprecated(t *testing.T) {
 	assert.Equal(t, strings.Join(strings.Fields(string(tracesRequestJSON)), ""), string(got))
 }
 
+func TestTracesResponsePartialSuccess(t *testing.T) {
+	resp := NewTracesResponse()
+	resp.PartialSuccess().SetRejectedSpans(3)
+	resp.PartialSuccess().SetErrorMessage("invalid item")
+
+	got, err := resp.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"partialSuccess":{"rejectedSpans":"3","errorMessage":"invalid item"}}`, string(got))
+
+	protoBytes, err := resp.MarshalProto()
+	require.NoError(t, err)
+	decoded := NewTracesResponse()
+	require.NoError(t, decoded.UnmarshalProto(protoBytes))
+	assert.EqualValues(t, 3, decoded.PartialSuccess().RejectedSpans())
+	assert.Equal(t, "invalid item", decoded.PartialSuccess().ErrorMessage())
+}
+
 func TestTracesGrpc(t *testing.T) {
 	lis := bufconn.Listen(1024 * 1024)
 	s := grpc.NewServer()
@@ -16,6 +16,7 @@ package obsreport // import "go.opentelemetry.io/collector/obsreport"
 
 import (
 	"context"
+	"errors"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
@@ -25,6 +26,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/internal/obsreportconfig"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 )
@@ -130,8 +132,14 @@ func endSpan(ctx context.Context, err error, numSent, numFailedToSend int64, sen
 	span.End()
 }
 
+// toNumItems splits the items into succeeded and failed ones: all of them fail with an error,
+// unless it is a consumererror.Partial reporting that only some of them failed.
 func toNumItems(numExportedItems int, err error) (int64, int64) {
 	if err != nil {
+		var partial consumererror.Partial
+		if errors.As(err, &partial) && partial.Failed() < numExportedItems {
+			return int64(numExportedItems - partial.Failed()), int64(partial.Failed())
+		}
 		return 0, int64(numExportedItems)
 	}
 	return int64(numExportedItems), 0
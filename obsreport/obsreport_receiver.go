@@ -152,12 +152,7 @@ func (rec *Receiver) endOp(
 	err error,
 	dataType config.DataType,
 ) {
-	numAccepted := numReceivedItems
-	numRefused := 0
-	if err != nil {
-		numAccepted = 0
-		numRefused = numReceivedItems
-	}
+	numAccepted, numRefused := toNumItems(numReceivedItems, err)
 
 	span := trace.SpanFromContext(receiverCtx)
 
@@ -177,8 +172,8 @@ func (rec *Receiver) endOp(
 
 		stats.Record(
 			receiverCtx,
-			acceptedMeasure.M(int64(numAccepted)),
-			refusedMeasure.M(int64(numRefused)))
+			acceptedMeasure.M(numAccepted),
+			refusedMeasure.M(numRefused))
 	}
 
 	// end span according to errors
@@ -198,8 +193,8 @@ func (rec *Receiver) endOp(
 
 		span.SetAttributes(
 			attribute.String(obsmetrics.FormatKey, format),
-			attribute.Int64(acceptedItemsKey, int64(numAccepted)),
-			attribute.Int64(refusedItemsKey, int64(numRefused)),
+			attribute.Int64(acceptedItemsKey, numAccepted),
+			attribute.Int64(refusedItemsKey, numRefused),
 		)
 		recordError(span, err)
 	}
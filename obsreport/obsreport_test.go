@@ -26,6 +26,7 @@ import (
 
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 	"go.opentelemetry.io/collector/obsreport/obsreporttest"
 	"go.opentelemetry.io/collector/receiver/scrapererror"
@@ -406,6 +407,51 @@ func TestExportLogsOp(t *testing.T) {
 	require.NoError(t, obsreporttest.CheckExporterLogs(tt, exporter, int64(sentLogRecords), int64(failedToSendLogRecords)))
 }
 
+func TestPartialFailureOp(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	partialErr := consumererror.NewPermanent(consumererror.NewPartial(errFake, 3))
+
+	recv := NewReceiver(ReceiverSettings{
+		ReceiverID:             receiver,
+		Transport:              transport,
+		ReceiverCreateSettings: tt.ToReceiverCreateSettings(),
+	})
+	ctx := recv.StartTracesOp(context.Background())
+	recv.EndTracesOp(ctx, format, 10, partialErr)
+	require.NoError(t, obsreporttest.CheckReceiverTraces(tt, receiver, transport, 7, 3))
+
+	exp := NewExporter(ExporterSettings{
+		Level:                  configtelemetry.LevelNormal,
+		ExporterID:             exporter,
+		ExporterCreateSettings: tt.ToExporterCreateSettings(),
+	})
+	ctx = exp.StartTracesOp(context.Background())
+	exp.EndTracesOp(ctx, 10, partialErr)
+	require.NoError(t, obsreporttest.CheckExporterTraces(tt, exporter, 7, 3))
+
+	spans := tt.SpanRecorder.Ended()
+	require.Len(t, spans, 2)
+	require.Contains(t, spans[0].Attributes(), attribute.KeyValue{Key: obsmetrics.AcceptedSpansKey, Value: attribute.Int64Value(7)})
+	require.Contains(t, spans[0].Attributes(), attribute.KeyValue{Key: obsmetrics.RefusedSpansKey, Value: attribute.Int64Value(3)})
+	require.Contains(t, spans[1].Attributes(), attribute.KeyValue{Key: obsmetrics.SentSpansKey, Value: attribute.Int64Value(7)})
+	require.Contains(t, spans[1].Attributes(), attribute.KeyValue{Key: obsmetrics.FailedToSendSpansKey, Value: attribute.Int64Value(3)})
+}
+
+func TestToNumItems(t *testing.T) {
+	sent, failed := toNumItems(10, nil)
+	assert.Equal(t, []int64{10, 0}, []int64{sent, failed})
+	sent, failed = toNumItems(10, errFake)
+	assert.Equal(t, []int64{0, 10}, []int64{sent, failed})
+	sent, failed = toNumItems(10, consumererror.NewPartial(errFake, 4))
+	assert.Equal(t, []int64{6, 4}, []int64{sent, failed})
+	// A partial error reporting more failed items than the items is a complete failure.
+	sent, failed = toNumItems(10, consumererror.NewPartial(errFake, 12))
+	assert.Equal(t, []int64{0, 10}, []int64{sent, failed})
+}
+
 func TestReceiveWithLongLivedCtx(t *testing.T) {
 	tt, err := obsreporttest.SetupTelemetry()
 	require.NoError(t, err)
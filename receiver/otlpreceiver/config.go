@@ -51,6 +51,26 @@ func (cfg *Config) Validate() error {
 		cfg.HTTP == nil {
 		return fmt.Errorf("must specify at least one protocol when using the OTLP receiver")
 	}
+	if cfg.GRPC != nil && cfg.GRPC.TLSSetting != nil {
+		if err := cfg.GRPC.TLSSetting.Validate(); err != nil {
+			return fmt.Errorf("grpc tls settings has invalid configuration: %w", err)
+		}
+	}
+	if cfg.GRPC != nil && cfg.GRPC.RateLimit != nil {
+		if err := cfg.GRPC.RateLimit.ValidateAuth(cfg.GRPC.Auth != nil); err != nil {
+			return fmt.Errorf("grpc rate limit has invalid configuration: %w", err)
+		}
+	}
+	if cfg.HTTP != nil && cfg.HTTP.TLSSetting != nil {
+		if err := cfg.HTTP.TLSSetting.Validate(); err != nil {
+			return fmt.Errorf("http tls settings has invalid configuration: %w", err)
+		}
+	}
+	if cfg.HTTP != nil && cfg.HTTP.RateLimit != nil {
+		if err := cfg.HTTP.RateLimit.ValidateAuth(cfg.HTTP.Auth != nil); err != nil {
+			return fmt.Errorf("http rate limit has invalid configuration: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -214,4 +214,10 @@ func TestFailedLoadConfig(t *testing.T) {
 
 	_, err = servicetest.LoadConfigAndValidate(filepath.Join("testdata", "bad_empty_config.yaml"), factories)
 	assert.EqualError(t, err, "error reading receivers configuration for \"otlp\": empty config for OTLP receiver")
+
+	_, err = servicetest.LoadConfigAndValidate(filepath.Join("testdata", "bad_tls_config.yaml"), factories)
+	assert.EqualError(t, err, "receiver \"otlp\" has invalid configuration: grpc tls settings has invalid configuration: invalid TLS cipher_suites: unsupported cipher suite: \"TLS_RSA_WITH_RC4_128_SHA\"")
+
+	_, err = servicetest.LoadConfigAndValidate(filepath.Join("testdata", "bad_rate_limit_config.yaml"), factories)
+	assert.EqualError(t, err, "receiver \"otlp\" has invalid configuration: http rate limit has invalid configuration: key principal requires the auth of the receiver to be configured")
 }
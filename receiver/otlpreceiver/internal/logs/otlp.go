@@ -20,6 +20,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/partialsuccess"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/obsreport"
 )
@@ -57,7 +59,15 @@ func (r *Receiver) Export(ctx context.Context, req otlpgrpc.LogsRequest) (otlpgr
 
 	ctx = r.obsrecv.StartLogsOp(ctx)
 	err := r.nextConsumer.ConsumeLogs(ctx, ld)
+	resp := otlpgrpc.NewLogsResponse()
+	if rejected, ok := partialsuccess.Rejected(err, numSpans); ok {
+		// The next consumer accepted part of the log records, report the rejected ones instead of failing the request.
+		resp.PartialSuccess().SetRejectedLogRecords(int64(rejected))
+		resp.PartialSuccess().SetErrorMessage(err.Error())
+		r.obsrecv.EndLogsOp(ctx, dataFormatProtobuf, numSpans, consumererror.NewPartial(err, rejected))
+		return resp, nil
+	}
 	r.obsrecv.EndLogsOp(ctx, dataFormatProtobuf, numSpans, err)
 
-	return otlpgrpc.NewLogsResponse(), err
+	return resp, err
 }
@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
@@ -91,6 +92,30 @@ func TestExport_ErrorConsumer(t *testing.T) {
 	assert.Equal(t, otlpgrpc.LogsResponse{}, resp)
 }
 
+func TestExport_PartialSuccess(t *testing.T) {
+	rejected := testdata.GenerateLogsOneLogRecord()
+	nextErr := consumererror.NewPermanent(consumererror.NewLogs(errors.New("invalid item"), rejected))
+	addr, doneFn := otlpReceiverOnGRPCServer(t, consumertest.NewErr(nextErr))
+	defer doneFn()
+
+	logClient, logClientDoneFn, err := makeLogsServiceClient(addr)
+	require.NoError(t, err, "Failed to create the LogsServiceClient: %v", err)
+	defer logClientDoneFn()
+
+	req := otlpgrpc.NewLogsRequest()
+	req.SetLogs(testdata.GenerateLogsTwoLogRecordsSameResource())
+	resp, err := logClient.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, resp.PartialSuccess().RejectedLogRecords())
+	assert.Equal(t, nextErr.Error(), resp.PartialSuccess().ErrorMessage())
+
+	// The request fails when all the items are rejected.
+	req = otlpgrpc.NewLogsRequest()
+	req.SetLogs(rejected)
+	_, err = logClient.Export(context.Background(), req)
+	assert.Error(t, err)
+}
+
 func makeLogsServiceClient(addr net.Addr) (otlpgrpc.LogsClient, func(), error) {
 	cc, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 	if err != nil {
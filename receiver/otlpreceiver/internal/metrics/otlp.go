@@ -20,6 +20,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/partialsuccess"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/obsreport"
 )
@@ -57,7 +59,15 @@ func (r *Receiver) Export(ctx context.Context, req otlpgrpc.MetricsRequest) (otl
 
 	ctx = r.obsrecv.StartMetricsOp(ctx)
 	err := r.nextConsumer.ConsumeMetrics(ctx, md)
+	resp := otlpgrpc.NewMetricsResponse()
+	if rejected, ok := partialsuccess.Rejected(err, dataPointCount); ok {
+		// The next consumer accepted part of the data points, report the rejected ones instead of failing the request.
+		resp.PartialSuccess().SetRejectedDataPoints(int64(rejected))
+		resp.PartialSuccess().SetErrorMessage(err.Error())
+		r.obsrecv.EndMetricsOp(ctx, dataFormatProtobuf, dataPointCount, consumererror.NewPartial(err, rejected))
+		return resp, nil
+	}
 	r.obsrecv.EndMetricsOp(ctx, dataFormatProtobuf, dataPointCount, err)
 
-	return otlpgrpc.NewMetricsResponse(), err
+	return resp, err
 }
@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
@@ -93,6 +94,30 @@ func TestExport_ErrorConsumer(t *testing.T) {
 	assert.Equal(t, otlpgrpc.MetricsResponse{}, resp)
 }
 
+func TestExport_PartialSuccess(t *testing.T) {
+	rejected := testdata.GenerateMetricsOneMetric()
+	nextErr := consumererror.NewPermanent(consumererror.NewMetrics(errors.New("invalid item"), rejected))
+	addr, doneFn := otlpReceiverOnGRPCServer(t, consumertest.NewErr(nextErr))
+	defer doneFn()
+
+	metricsClient, metricsClientDoneFn, err := makeMetricsServiceClient(addr)
+	require.NoError(t, err, "Failed to create the MetricsServiceClient: %v", err)
+	defer metricsClientDoneFn()
+
+	req := otlpgrpc.NewMetricsRequest()
+	req.SetMetrics(testdata.GenerateMetricsTwoMetrics())
+	resp, err := metricsClient.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.EqualValues(t, int64(rejected.DataPointCount()), resp.PartialSuccess().RejectedDataPoints())
+	assert.Equal(t, nextErr.Error(), resp.PartialSuccess().ErrorMessage())
+
+	// The request fails when all the items are rejected.
+	req = otlpgrpc.NewMetricsRequest()
+	req.SetMetrics(rejected)
+	_, err = metricsClient.Export(context.Background(), req)
+	assert.Error(t, err)
+}
+
 func makeMetricsServiceClient(addr net.Addr) (otlpgrpc.MetricsClient, func(), error) {
 	cc, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 	if err != nil {
@@ -20,6 +20,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/partialsuccess"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/obsreport"
 )
@@ -58,7 +60,15 @@ func (r *Receiver) Export(ctx context.Context, req otlpgrpc.TracesRequest) (otlp
 
 	ctx = r.obsrecv.StartTracesOp(ctx)
 	err := r.nextConsumer.ConsumeTraces(ctx, td)
+	resp := otlpgrpc.NewTracesResponse()
+	if rejected, ok := partialsuccess.Rejected(err, numSpans); ok {
+		// The next consumer accepted part of the spans, report the rejected ones instead of failing the request.
+		resp.PartialSuccess().SetRejectedSpans(int64(rejected))
+		resp.PartialSuccess().SetErrorMessage(err.Error())
+		r.obsrecv.EndTracesOp(ctx, dataFormatProtobuf, numSpans, consumererror.NewPartial(err, rejected))
+		return resp, nil
+	}
 	r.obsrecv.EndTracesOp(ctx, dataFormatProtobuf, numSpans, err)
 
-	return otlpgrpc.NewTracesResponse(), err
+	return resp, err
 }
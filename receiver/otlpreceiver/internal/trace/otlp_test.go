@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
@@ -90,6 +91,30 @@ func TestExport_ErrorConsumer(t *testing.T) {
 	assert.Equal(t, otlpgrpc.TracesResponse{}, resp)
 }
 
+func TestExport_PartialSuccess(t *testing.T) {
+	rejected := testdata.GenerateTracesOneSpan()
+	nextErr := consumererror.NewPermanent(consumererror.NewTraces(errors.New("invalid item"), rejected))
+	addr, doneFn := otlpReceiverOnGRPCServer(t, consumertest.NewErr(nextErr))
+	defer doneFn()
+
+	traceClient, traceClientDoneFn, err := makeTraceServiceClient(addr)
+	require.NoError(t, err, "Failed to create the TraceServiceClient: %v", err)
+	defer traceClientDoneFn()
+
+	req := otlpgrpc.NewTracesRequest()
+	req.SetTraces(testdata.GenerateTracesTwoSpansSameResource())
+	resp, err := traceClient.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, resp.PartialSuccess().RejectedSpans())
+	assert.Equal(t, nextErr.Error(), resp.PartialSuccess().ErrorMessage())
+
+	// The request fails when all the items are rejected.
+	req = otlpgrpc.NewTracesRequest()
+	req.SetTraces(rejected)
+	_, err = traceClient.Export(context.Background(), req)
+	assert.Error(t, err)
+}
+
 func makeTraceServiceClient(addr net.Addr) (otlpgrpc.TracesClient, func(), error) {
 	cc, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 	if err != nil {